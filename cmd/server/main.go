@@ -1,8 +1,13 @@
 package main
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"log"
+	"net"
+	"net/http"
+	"os"
 	"time"
 
 	"github.com/gin-contrib/cors"
@@ -15,9 +20,19 @@ import (
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 
 	"github.com/radiatus-ai/auth-service/config"
+	"github.com/radiatus-ai/auth-service/internal/admin"
+	"github.com/radiatus-ai/auth-service/internal/audit"
 	"github.com/radiatus-ai/auth-service/internal/auth"
+	"github.com/radiatus-ai/auth-service/internal/extauthz"
+	"github.com/radiatus-ai/auth-service/internal/forwardauth"
+	"github.com/radiatus-ai/auth-service/internal/kubernetes"
 	"github.com/radiatus-ai/auth-service/internal/middleware"
+	"github.com/radiatus-ai/auth-service/internal/oauth"
+	"github.com/radiatus-ai/auth-service/internal/organization"
+	"github.com/radiatus-ai/auth-service/internal/pat"
 	"github.com/radiatus-ai/auth-service/internal/repository"
+	"github.com/radiatus-ai/auth-service/internal/session"
+	"github.com/radiatus-ai/auth-service/pkg/jwks"
 )
 
 func main() {
@@ -41,46 +56,268 @@ func main() {
 	// Initialize repositories
 	userRepo := repository.NewUserRepository(db)
 	orgRepo := repository.NewOrganizationRepository(db)
+	teamRepo := repository.NewTeamRepository(db)
+	domainRepo := repository.NewDomainRepository(db)
+	transferRepo := repository.NewTransferRepository(db)
+	auditRepo := repository.NewAuditRepository(db)
+	clientRepo := repository.NewClientRepository(db)
+	serviceAccountRepo := repository.NewServiceAccountRepository(db)
+	patRepo := repository.NewPersonalAccessTokenRepository(db)
+	apiKeyRepo := repository.NewAPIKeyRepository(db)
+	sessionRepo := repository.NewSessionRepository(db)
+	referenceTokenRepo := repository.NewReferenceTokenRepository(db)
+
+	// Load the token signing key, if tokens are signed with RS256
+	var signingKey *auth.SigningKey
+	if cfg.JWTSigningKeyFile != "" {
+		pemData, err := os.ReadFile(cfg.JWTSigningKeyFile)
+		if err != nil {
+			log.Fatalf("Failed to read signing key: %v", err)
+		}
+		if signingKey, err = auth.ParseSigningKey(pemData); err != nil {
+			log.Fatalf("Failed to load signing key: %v", err)
+		}
+		log.Printf("Signing tokens with RS256 key %s", signingKey.ID)
+	}
+
+	sessionPolicy := auth.SessionPolicy{
+		TokenTTL:         cfg.TokenTTL,
+		RefreshTokenTTL:  cfg.RefreshTokenTTL,
+		IdleTimeout:      cfg.SessionIdleTimeout,
+		AbsoluteLifetime: cfg.SessionAbsoluteLifetime,
+	}
+	if err := sessionPolicy.Validate(); err != nil {
+		log.Fatalf("Invalid session policy: %v", err)
+	}
+
+	claimsConfig, err := auth.LoadClaimsConfig(cfg.ClaimsConfigFile)
+	if err != nil {
+		log.Fatalf("Failed to load claims config: %v", err)
+	}
+
+	if cfg.KubernetesWebhookClientName != "" && cfg.TLSClientCAFile == "" {
+		log.Fatalf("KUBERNETES_WEBHOOK_CLIENT_NAME requires TLS_CLIENT_CA_FILE")
+	}
+	if cfg.KubernetesWebhookSecret == "" && cfg.KubernetesWebhookClientName == "" {
+		log.Printf("Kubernetes TokenReview webhook disabled: set KUBERNETES_WEBHOOK_SECRET or KUBERNETES_WEBHOOK_CLIENT_NAME")
+	}
+
+	if !auth.ValidACR(cfg.StepUpACR) {
+		log.Fatalf("Invalid STEP_UP_ACR: %q", cfg.StepUpACR)
+	}
 
 	// Initialize services
-	authService := auth.NewService(userRepo, orgRepo, cfg.JWTSecret, cfg.GoogleClientIDs, cfg.EmailWhitelist)
+	auditLog := audit.NewLogger(auditRepo)
+	authService := auth.NewService(userRepo, orgRepo, serviceAccountRepo, patRepo, apiKeyRepo, sessionRepo, referenceTokenRepo, sessionPolicy, cfg.JWTSecret, signingKey, cfg.GoogleClientIDs, cfg.EmailWhitelist, cfg.SuperadminEmails, claimsConfig)
+	adminService := admin.NewService(authService, userRepo, orgRepo, auditLog)
+	orgService := organization.NewService(orgRepo, teamRepo, domainRepo, transferRepo, serviceAccountRepo, apiKeyRepo, auditLog, cfg.OrgRetention, sessionPolicy)
+	patService := pat.NewService(patRepo, orgService)
+	oauthService := oauth.NewService(authService, clientRepo, serviceAccountRepo, jwks.New(nil, 0), auditLog)
+	kubernetesService := kubernetes.NewService(authService, orgService, orgRepo)
+	forwardAuthService := forwardauth.NewService(authService, orgService)
+	sessionService := session.NewService(sessionRepo)
+
+	// Permanently remove organizations once their restore window has passed
+	stopPurge := organization.StartPurgeJob(orgService, time.Hour)
+	defer stopPurge()
+
+	// Delete reference tokens once they have expired
+	stopTokenPurge := auth.StartPurgeJob(authService, time.Hour)
+	defer stopTokenPurge()
 
 	// Initialize handlers
-	authHandler := auth.NewHandler(authService)
+	sessions := auth.NewSessionCookies(auth.SessionCookieConfig{
+		Name:     cfg.SessionCookie,
+		Domain:   cfg.SessionCookieDomain,
+		Insecure: cfg.SessionCookieInsecure,
+		SameSite: cfg.SessionCookieSameSite,
+	})
+	authHandler := auth.NewHandler(authService, sessions)
+	orgHandler := organization.NewHandler(orgService)
+	adminHandler := admin.NewHandler(adminService)
+	oauthHandler := oauth.NewHandler(oauthService)
+	patHandler := pat.NewHandler(patService)
+	sessionHandler := session.NewHandler(sessionService)
+	kubernetesHandler := kubernetes.NewHandler(kubernetesService, cfg.KubernetesWebhookSecret, cfg.KubernetesWebhookClientName)
+	forwardAuthHandler := forwardauth.NewHandler(forwardAuthService, cfg.ForwardAuthCookie, cfg.ForwardAuthCertHeader)
 
 	// Set up Gin router
 	router := gin.Default()
+	if err := router.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
 
 	// Configure CORS
 	router.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"http://localhost:3000", "https://dev.r7ai.net", "https://canvas.dev.r7ai.net"}, // Add your frontend URL
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Length", "Content-Type", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders:     []string{"Origin", "Content-Length", "Content-Type", "Authorization", auth.CSRFHeader, auth.DPoPHeader},
+		ExposeHeaders:    []string{"Content-Length", "WWW-Authenticate"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}))
 
 	// Public routes
 	router.POST("/login/google", authHandler.LoginGoogle)
+	router.POST("/logout", authHandler.Logout)
 	router.POST("/api/verify-token", authHandler.VerifyToken)
+	router.POST("/introspect", oauthHandler.RequireClient, authHandler.Introspect)
+	router.GET("/.well-known/jwks.json", authHandler.JWKS)
+	router.POST("/token", oauthHandler.Token)
+	router.POST("/revoke", oauthHandler.Revoke)
+	router.POST("/kubernetes/token-review", kubernetesHandler.TokenReview)
+	// nginx auth_request subrequests keep the method of the original request.
+	router.Any("/forward-auth", forwardAuthHandler.ForwardAuth)
+
+	// Sensitive actions demand a recent sign-in
+	stepUp := middleware.RequireStepUp(cfg.StepUpMaxAge, cfg.StepUpACR)
 
 	// Protected routes
 	api := router.Group("/api")
-	api.Use(middleware.AuthMiddleware(authService))
+	api.Use(middleware.AuthMiddleware(authService, sessions), middleware.ImpersonationAudit(auditLog), middleware.APIKeyAudit(auditLog))
 	{
 		api.GET("/protected", func(c *gin.Context) {
 			userID, _ := c.Get("user_id")
 			c.JSON(200, gin.H{"message": "You're authenticated!", "user_id": userID})
 		})
+
+		api.GET("/tokens", patHandler.ListTokens)
+		api.POST("/tokens", stepUp, patHandler.CreateToken)
+		api.DELETE("/tokens/:tokenID", patHandler.RevokeToken)
+
+		api.POST("/reauthenticate", authHandler.Reauthenticate)
+		api.GET("/sessions", sessionHandler.ListSessions)
+		api.DELETE("/sessions", sessionHandler.RevokeOtherSessions)
+		api.DELETE("/sessions/:sessionID", sessionHandler.RevokeSession)
+
+		api.GET("/organizations", orgHandler.ListOrganizations)
+		api.POST("/organizations", orgHandler.CreateOrganization)
+		api.GET("/organizations/by-slug/:slug", orgHandler.GetOrganizationBySlug)
+		api.GET("/organizations/deleted", orgHandler.ListDeletedOrganizations)
+
+		orgs := api.Group("/organizations/:orgID")
+		orgs.GET("", orgHandler.GetOrganization)
+		orgs.PATCH("", orgHandler.UpdateOrganization)
+		orgs.DELETE("", stepUp, orgHandler.DeleteOrganization)
+		orgs.POST("/restore", orgHandler.RestoreOrganization)
+		orgs.GET("/tree", orgHandler.GetOrganizationTree)
+		orgs.POST("/leave", orgHandler.LeaveOrganization)
+		orgs.GET("/transfer", orgHandler.GetPendingTransfer)
+		orgs.POST("/transfer", stepUp, orgHandler.InitiateTransfer)
+		orgs.POST("/transfer/accept", orgHandler.AcceptTransfer)
+		orgs.DELETE("/transfer", orgHandler.CancelTransfer)
+		orgs.GET("/audit-events", orgHandler.ListAuditEvents)
+		orgs.GET("/settings", orgHandler.GetSettings)
+		orgs.PUT("/settings", orgHandler.UpdateSettings)
+		orgs.GET("/domains", orgHandler.ListDomains)
+		orgs.POST("/domains", orgHandler.AddDomain)
+		orgs.POST("/domains/:domainID/verify", orgHandler.VerifyDomain)
+		orgs.DELETE("/domains/:domainID", orgHandler.DeleteDomain)
+		orgs.GET("/members/:userID/permissions", orgHandler.GetMemberAccess)
+		orgs.GET("/teams", orgHandler.ListTeams)
+		orgs.POST("/teams", orgHandler.CreateTeam)
+		orgs.GET("/teams/:teamID", orgHandler.GetTeam)
+		orgs.PATCH("/teams/:teamID", orgHandler.UpdateTeam)
+		orgs.DELETE("/teams/:teamID", orgHandler.DeleteTeam)
+		orgs.GET("/teams/:teamID/members", orgHandler.ListTeamMembers)
+		orgs.PUT("/teams/:teamID/members/:userID", orgHandler.AddTeamMember)
+		orgs.DELETE("/teams/:teamID/members/:userID", orgHandler.RemoveTeamMember)
+		orgs.GET("/service-accounts", orgHandler.ListServiceAccounts)
+		orgs.POST("/service-accounts", orgHandler.CreateServiceAccount)
+		orgs.GET("/service-accounts/:accountID", orgHandler.GetServiceAccount)
+		orgs.PATCH("/service-accounts/:accountID", orgHandler.UpdateServiceAccount)
+		orgs.DELETE("/service-accounts/:accountID", orgHandler.DeleteServiceAccount)
+		orgs.GET("/service-accounts/:accountID/secrets", orgHandler.ListServiceAccountSecrets)
+		orgs.POST("/service-accounts/:accountID/secrets", stepUp, orgHandler.CreateServiceAccountSecret)
+		orgs.DELETE("/service-accounts/:accountID/secrets/:secretID", orgHandler.DeleteServiceAccountSecret)
+		orgs.GET("/service-accounts/:accountID/trust-policies", orgHandler.ListTrustPolicies)
+		orgs.POST("/service-accounts/:accountID/trust-policies", stepUp, orgHandler.CreateTrustPolicy)
+		orgs.DELETE("/service-accounts/:accountID/trust-policies/:policyID", orgHandler.DeleteTrustPolicy)
+		orgs.GET("/api-keys", orgHandler.ListAPIKeys)
+		orgs.POST("/api-keys", stepUp, orgHandler.CreateAPIKey)
+		orgs.POST("/api-keys/:keyID/rotate", stepUp, orgHandler.RotateAPIKey)
+		orgs.DELETE("/api-keys/:keyID", orgHandler.RevokeAPIKey)
+	}
+
+	// Platform admin routes
+	admins := router.Group("/admin")
+	admins.Use(middleware.AuthMiddleware(authService, sessions), middleware.ImpersonationAudit(auditLog), middleware.RequireSuperadmin(authService))
+	{
+		admins.GET("/users", adminHandler.SearchUsers)
+		admins.GET("/users/:userID", adminHandler.GetUser)
+		admins.DELETE("/users/:userID", adminHandler.DeleteUser)
+		admins.PUT("/users/:userID/status", adminHandler.SetUserStatus)
+		admins.POST("/users/:userID/suspend", adminHandler.SuspendUser)
+		admins.POST("/users/:userID/unsuspend", adminHandler.UnsuspendUser)
+		admins.POST("/users/:userID/logout", adminHandler.ForceLogout)
+		admins.POST("/users/:userID/impersonate", adminHandler.Impersonate)
+		admins.GET("/clients", oauthHandler.ListClients)
+		admins.POST("/clients", oauthHandler.CreateClient)
+		admins.PUT("/clients/:clientID", oauthHandler.UpdateClient)
+		admins.POST("/clients/:clientID/disable", oauthHandler.DisableClient)
+	}
+
+	// Serve Envoy external authorization alongside the HTTP API
+	if cfg.ExtAuthzAddr != "" {
+		rules, err := extauthz.LoadRules(cfg.ExtAuthzRules)
+		if err != nil {
+			log.Fatalf("Failed to load ext_authz rules: %v", err)
+		}
+		lis, err := net.Listen("tcp", cfg.ExtAuthzAddr)
+		if err != nil {
+			log.Fatalf("Failed to listen for ext_authz: %v", err)
+		}
+		go func() {
+			log.Printf("Serving ext_authz on %s with %d rules", cfg.ExtAuthzAddr, len(rules))
+			if err := extauthz.Serve(lis, extauthz.NewServer(forwardAuthService, rules)); err != nil {
+				log.Fatalf("ext_authz server stopped: %v", err)
+			}
+		}()
 	}
 
-	// Start the server
+	// Start the server, terminating TLS itself if configured to
+	if cfg.TLSCertFile != "" {
+		tlsConfig, err := serverTLSConfig(cfg.TLSClientCAFile)
+		if err != nil {
+			log.Fatalf("Failed to configure TLS: %v", err)
+		}
+		server := &http.Server{Addr: ":" + cfg.Port, Handler: router, TLSConfig: tlsConfig}
+		log.Printf("Serving HTTPS on %s (client certificates: %t)", server.Addr, cfg.TLSClientCAFile != "")
+		if err := server.ListenAndServeTLS(cfg.TLSCertFile, cfg.TLSKeyFile); err != nil {
+			log.Fatalf("Failed to start server: %v", err)
+		}
+		return
+	}
+	if cfg.TLSClientCAFile != "" {
+		log.Fatalf("TLS_CLIENT_CA_FILE requires TLS_CERT_FILE and TLS_KEY_FILE")
+	}
 	if err := router.Run(":" + cfg.Port); err != nil {
 		log.Fatalf("Failed to start server: %v", err)
 	}
 }
 
+// serverTLSConfig asks clients for a certificate issued by one of the CAs in
+// clientCAFile, if set. Certificates are optional, as browsers and most
+// clients have none, but a certificate that does not verify fails the
+// handshake.
+func serverTLSConfig(clientCAFile string) (*tls.Config, error) {
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+	if clientCAFile == "" {
+		return config, nil
+	}
+	bundle, err := os.ReadFile(clientCAFile)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(bundle) {
+		return nil, fmt.Errorf("no certificates found in %s", clientCAFile)
+	}
+	config.ClientCAs = pool
+	config.ClientAuth = tls.VerifyClientCertIfGiven
+	return config, nil
+}
+
 func runMigrations(databaseURL string) error {
 	m, err := migrate.New("file://migrations", databaseURL)
 	if err != nil {
@@ -2,8 +2,11 @@ package config
 
 import (
 	"fmt"
+	"net/http"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -14,6 +17,69 @@ type Config struct {
 	GoogleClientIDs []string
 	Port            string
 	EmailWhitelist  []string
+	// SuperadminEmails are granted the superadmin platform role when they sign in.
+	SuperadminEmails []string
+	// OrgRetention is how long a deleted organization can be restored before it is purged.
+	OrgRetention time.Duration
+	// TokenTTL, RefreshTokenTTL, SessionIdleTimeout and
+	// SessionAbsoluteLifetime are the global session policy, which
+	// organizations can override in their settings. Zero SessionIdleTimeout
+	// and SessionAbsoluteLifetime mean no limit.
+	TokenTTL                time.Duration
+	RefreshTokenTTL         time.Duration
+	SessionIdleTimeout      time.Duration
+	SessionAbsoluteLifetime time.Duration
+	// StepUpMaxAge is how recently a user must have signed in to perform a
+	// sensitive action, and StepUpACR how strongly.
+	StepUpMaxAge time.Duration
+	StepUpACR    string
+	// SessionCookie names the HttpOnly cookie browser sessions are kept in,
+	// set when a login asks for a cookie session.
+	SessionCookie       string
+	SessionCookieDomain string
+	// SessionCookieInsecure drops the Secure attribute, for local development
+	// over http.
+	SessionCookieInsecure bool
+	SessionCookieSameSite http.SameSite
+	// TrustedProxies are the addresses or CIDRs of the reverse proxies in
+	// front of the service, whose X-Forwarded-For headers are believed when
+	// working out the client's address, such as for API key allowlists.
+	// Empty trusts none and uses the connection's address.
+	TrustedProxies []string
+	// ForwardAuthCookie names the cookie /forward-auth reads a token from when
+	// a request has no Authorization header. It defaults to SessionCookie.
+	ForwardAuthCookie string
+	// ForwardAuthCertHeader names the header the proxy passes the client
+	// certificate to /forward-auth in, for certificate-bound tokens. The proxy
+	// must overwrite it on every request. Empty rejects such tokens.
+	ForwardAuthCertHeader string
+	// TLSCertFile and TLSKeyFile make the server terminate TLS itself. With
+	// TLSClientCAFile, a PEM bundle of CAs, clients may also present a
+	// certificate issued by one of them, to authenticate to the token
+	// endpoint and get certificate-bound tokens (RFC 8705).
+	TLSCertFile     string
+	TLSKeyFile      string
+	TLSClientCAFile string
+	// KubernetesWebhookSecret is the bearer token the Kubernetes API server
+	// must send to the TokenReview webhook, set as the token of the user in
+	// its webhook kubeconfig. KubernetesWebhookClientName lets it
+	// authenticate with a client certificate instead, issued to that common
+	// name by a CA in TLSClientCAFile. With neither the webhook refuses every
+	// request.
+	KubernetesWebhookSecret     string
+	KubernetesWebhookClientName string
+	// ExtAuthzAddr is where the Envoy ext_authz gRPC server listens, e.g.
+	// ":9001". Empty disables it.
+	ExtAuthzAddr string
+	// ExtAuthzRules is the path of a JSON file of per-route ext_authz rules.
+	ExtAuthzRules string
+	// JWTSigningKeyFile is the path of a PEM encoded RSA private key. When set,
+	// tokens are signed with RS256 and the public key is published at
+	// /.well-known/jwks.json; otherwise they are signed with JWTSecret.
+	JWTSigningKeyFile string
+	// ClaimsConfigFile is the path of a JSON file of claims templates and
+	// the pre-issue token hook, which add custom claims to issued tokens.
+	ClaimsConfigFile string
 }
 
 func Load() (*Config, error) {
@@ -32,6 +98,55 @@ func Load() (*Config, error) {
 		port = "8080"
 	}
 
+	retentionDays, err := parseIntEnv("ORG_RETENTION_DAYS", 30)
+	if err != nil {
+		return nil, err
+	}
+	if retentionDays < 0 {
+		return nil, fmt.Errorf("invalid ORG_RETENTION_DAYS: %d is negative", retentionDays)
+	}
+
+	tokenTTL, err := parseDurationEnv("TOKEN_TTL", 24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	refreshTokenTTL, err := parseDurationEnv("REFRESH_TOKEN_TTL", 30*24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	idleTimeout, err := parseDurationEnv("SESSION_IDLE_TIMEOUT", 0)
+	if err != nil {
+		return nil, err
+	}
+	absoluteLifetime, err := parseDurationEnv("SESSION_ABSOLUTE_LIFETIME", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	stepUpMaxAge, err := parseDurationEnv("STEP_UP_MAX_AGE", 10*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	stepUpACR := os.Getenv("STEP_UP_ACR")
+	if stepUpACR == "" {
+		stepUpACR = "aal1"
+	}
+
+	sessionCookie := os.Getenv("SESSION_COOKIE")
+	if sessionCookie == "" {
+		sessionCookie = "r7_token"
+	}
+
+	sameSite, err := parseSameSite(os.Getenv("SESSION_COOKIE_SAMESITE"))
+	if err != nil {
+		return nil, err
+	}
+
+	forwardAuthCookie := os.Getenv("FORWARD_AUTH_COOKIE")
+	if forwardAuthCookie == "" {
+		forwardAuthCookie = sessionCookie
+	}
+
 	sslMode := "require"
 	if os.Getenv("LOCAL_DB") != "" {
 		sslMode = "disable"
@@ -61,6 +176,30 @@ func Load() (*Config, error) {
 			"radiatus.io",
 			// Add more allowed domains or full email addresses here
 		},
+		SuperadminEmails:            parseList(os.Getenv("SUPERADMIN_EMAILS")),
+		OrgRetention:                time.Duration(retentionDays) * 24 * time.Hour,
+		TokenTTL:                    tokenTTL,
+		RefreshTokenTTL:             refreshTokenTTL,
+		SessionIdleTimeout:          idleTimeout,
+		SessionAbsoluteLifetime:     absoluteLifetime,
+		StepUpMaxAge:                stepUpMaxAge,
+		StepUpACR:                   stepUpACR,
+		SessionCookie:               sessionCookie,
+		SessionCookieDomain:         os.Getenv("SESSION_COOKIE_DOMAIN"),
+		SessionCookieInsecure:       os.Getenv("SESSION_COOKIE_INSECURE") != "",
+		SessionCookieSameSite:       sameSite,
+		TrustedProxies:              parseList(os.Getenv("TRUSTED_PROXIES")),
+		ForwardAuthCookie:           forwardAuthCookie,
+		ForwardAuthCertHeader:       os.Getenv("FORWARD_AUTH_CERT_HEADER"),
+		TLSCertFile:                 os.Getenv("TLS_CERT_FILE"),
+		TLSKeyFile:                  os.Getenv("TLS_KEY_FILE"),
+		TLSClientCAFile:             os.Getenv("TLS_CLIENT_CA_FILE"),
+		KubernetesWebhookSecret:     os.Getenv("KUBERNETES_WEBHOOK_SECRET"),
+		KubernetesWebhookClientName: os.Getenv("KUBERNETES_WEBHOOK_CLIENT_NAME"),
+		ExtAuthzAddr:                os.Getenv("EXT_AUTHZ_ADDR"),
+		ExtAuthzRules:               os.Getenv("EXT_AUTHZ_RULES"),
+		JWTSigningKeyFile:           os.Getenv("JWT_SIGNING_KEY_FILE"),
+		ClaimsConfigFile:            os.Getenv("CLAIMS_CONFIG"),
 	}, nil
 }
 
@@ -70,3 +209,49 @@ func parseGoogleClientIDs(envValue string) []string {
 	}
 	return strings.Split(envValue, ",")
 }
+
+func parseList(envValue string) []string {
+	var values []string
+	for _, value := range strings.Split(envValue, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func parseIntEnv(name string, fallback int) (int, error) {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", name, err)
+	}
+	return n, nil
+}
+
+func parseSameSite(value string) (http.SameSite, error) {
+	switch strings.ToLower(value) {
+	case "", "lax":
+		return http.SameSiteLaxMode, nil
+	case "strict":
+		return http.SameSiteStrictMode, nil
+	case "none":
+		return http.SameSiteNoneMode, nil
+	}
+	return 0, fmt.Errorf("invalid SESSION_COOKIE_SAMESITE: %q", value)
+}
+
+func parseDurationEnv(name string, fallback time.Duration) (time.Duration, error) {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", name, err)
+	}
+	return d, nil
+}
@@ -0,0 +1,12 @@
+package admin
+
+import "errors"
+
+var (
+	ErrSelfAction            = errors.New("admins cannot perform this action on themselves")
+	ErrTargetIsSuperadmin    = errors.New("action not allowed on another superadmin")
+	ErrUserOwnsOrganizations = errors.New("user is the last owner of one or more organizations")
+	ErrInvalidStatus         = errors.New("status must be one of active, suspended, deactivated, locked")
+	ErrTargetInactive        = errors.New("only active users can be impersonated")
+	// Add other admin-related errors here
+)
@@ -0,0 +1,155 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/radiatus-ai/auth-service/internal/repository"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+func (h *Handler) SearchUsers(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 || limit > 500 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 500"})
+		return
+	}
+
+	users, err := h.service.SearchUsers(c.Query("q"), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search users"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"users": users})
+}
+
+func (h *Handler) GetUser(c *gin.Context) {
+	userID, ok := userParam(c)
+	if !ok {
+		return
+	}
+
+	details, err := h.service.GetUserDetails(userID)
+	if err != nil {
+		respondError(c, err, "Failed to retrieve user")
+		return
+	}
+
+	c.JSON(http.StatusOK, details)
+}
+
+func (h *Handler) SetUserStatus(c *gin.Context) {
+	var req struct {
+		Status string `json:"status" binding:"required"`
+		Reason string `json:"reason"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.userAction(c, func(actorID, userID uuid.UUID) error {
+		return h.service.SetUserStatus(actorID, userID, req.Status, req.Reason)
+	}, "Failed to set user status")
+}
+
+func (h *Handler) SuspendUser(c *gin.Context) {
+	h.userAction(c, h.service.SuspendUser, "Failed to suspend user")
+}
+
+func (h *Handler) UnsuspendUser(c *gin.Context) {
+	h.userAction(c, h.service.UnsuspendUser, "Failed to unsuspend user")
+}
+
+func (h *Handler) DeleteUser(c *gin.Context) {
+	h.userAction(c, h.service.DeleteUser, "Failed to delete user")
+}
+
+func (h *Handler) ForceLogout(c *gin.Context) {
+	h.userAction(c, h.service.ForceLogout, "Failed to log out user")
+}
+
+func (h *Handler) Impersonate(c *gin.Context) {
+	actorID, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user"})
+		return
+	}
+	userID, ok := userParam(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Reason string `json:"reason" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A reason is required to impersonate a user"})
+		return
+	}
+
+	impersonation, err := h.service.Impersonate(actorID, userID, req.Reason)
+	if err != nil {
+		respondError(c, err, "Failed to impersonate user")
+		return
+	}
+
+	c.JSON(http.StatusOK, impersonation)
+}
+
+func (h *Handler) userAction(c *gin.Context, action func(actorID, userID uuid.UUID) error, failure string) {
+	actorID, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user"})
+		return
+	}
+	userID, ok := userParam(c)
+	if !ok {
+		return
+	}
+
+	if err := action(actorID, userID); err != nil {
+		respondError(c, err, failure)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func userParam(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("userID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid userID"})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func respondError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, repository.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrSelfAction),
+		errors.Is(err, ErrTargetIsSuperadmin):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrInvalidStatus):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrUserOwnsOrganizations),
+		errors.Is(err, ErrTargetInactive):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
@@ -0,0 +1,205 @@
+package admin
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/radiatus-ai/auth-service/internal/audit"
+	"github.com/radiatus-ai/auth-service/internal/auth"
+	"github.com/radiatus-ai/auth-service/internal/model"
+	"github.com/radiatus-ai/auth-service/internal/rbac"
+	"github.com/radiatus-ai/auth-service/internal/repository"
+)
+
+// Service backs the superadmin-only /admin API. Callers are expected to have
+// checked the platform role before reaching it.
+type Service interface {
+	SearchUsers(query string, limit int) ([]model.User, error)
+	GetUserDetails(userID uuid.UUID) (*UserDetails, error)
+	SetUserStatus(actorID, userID uuid.UUID, status, reason string) error
+	SuspendUser(actorID, userID uuid.UUID) error
+	UnsuspendUser(actorID, userID uuid.UUID) error
+	DeleteUser(actorID, userID uuid.UUID) error
+	ForceLogout(actorID, userID uuid.UUID) error
+	Impersonate(actorID, userID uuid.UUID, reason string) (*Impersonation, error)
+}
+
+type service struct {
+	authService auth.Service
+	userRepo    repository.UserRepository
+	orgRepo     repository.OrganizationRepository
+	audit       audit.Logger
+}
+
+func NewService(authService auth.Service, userRepo repository.UserRepository, orgRepo repository.OrganizationRepository, auditLog audit.Logger) Service {
+	return &service{
+		authService: authService,
+		userRepo:    userRepo,
+		orgRepo:     orgRepo,
+		audit:       auditLog,
+	}
+}
+
+// UserDetails is everything support staff need to see about a user.
+type UserDetails struct {
+	User          model.User           `json:"user"`
+	Memberships   []model.Membership   `json:"memberships"`
+	Organizations []model.Organization `json:"organizations"`
+	Identities    []Identity           `json:"identities"`
+}
+
+// Impersonation is a short-lived token that lets an admin act as a user.
+type Impersonation struct {
+	Token     string    `json:"token"`
+	UserID    uuid.UUID `json:"user_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Identity is an external account linked to a user.
+type Identity struct {
+	Provider string `json:"provider"`
+	Subject  string `json:"subject"`
+}
+
+func (s *service) SearchUsers(query string, limit int) ([]model.User, error) {
+	return s.userRepo.Search(query, limit)
+}
+
+func (s *service) GetUserDetails(userID uuid.UUID) (*UserDetails, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	memberships, err := s.orgRepo.ListUserMemberships(userID)
+	if err != nil {
+		return nil, err
+	}
+	orgs, err := s.orgRepo.GetUserOrganizations(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	identities := []Identity{}
+	if user.GoogleID != "" {
+		identities = append(identities, Identity{Provider: "google", Subject: user.GoogleID})
+	}
+
+	return &UserDetails{
+		User:          *user,
+		Memberships:   memberships,
+		Organizations: orgs,
+		Identities:    identities,
+	}, nil
+}
+
+// SetUserStatus moves the user to a new account status. Every status change
+// revokes the user's outstanding tokens, so a suspension or deactivation takes
+// effect immediately.
+func (s *service) SetUserStatus(actorID, userID uuid.UUID, status, reason string) error {
+	if !model.ValidUserStatus(status) {
+		return ErrInvalidStatus
+	}
+	user, err := s.targetUser(actorID, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.userRepo.SetStatus(userID, status, reason, time.Now()); err != nil {
+		log.Printf("Failed to set user status: %v", err)
+		return err
+	}
+
+	event := audit.PlatformEvent(actorID, audit.ActionUserStatusChanged, "user", userID.String())
+	event.Metadata["from"] = user.Status
+	event.Metadata["to"] = status
+	if reason != "" {
+		event.Metadata["reason"] = reason
+	}
+	s.audit.Record(event)
+	return nil
+}
+
+func (s *service) SuspendUser(actorID, userID uuid.UUID) error {
+	return s.SetUserStatus(actorID, userID, model.UserStatusSuspended, "")
+}
+
+func (s *service) UnsuspendUser(actorID, userID uuid.UUID) error {
+	return s.SetUserStatus(actorID, userID, model.UserStatusActive, "")
+}
+
+// DeleteUser permanently removes the user. It refuses while the user is the
+// only owner of an organization, which would otherwise be left unmanaged.
+func (s *service) DeleteUser(actorID, userID uuid.UUID) error {
+	user, err := s.targetUser(actorID, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.userRepo.DeleteUnlessLastOwner(userID, string(rbac.RoleOwner)); err != nil {
+		if errors.Is(err, repository.ErrLastOwner) {
+			return ErrUserOwnsOrganizations
+		}
+		log.Printf("Failed to delete user: %v", err)
+		return err
+	}
+
+	event := audit.PlatformEvent(actorID, audit.ActionUserDeleted, "user", userID.String())
+	event.Metadata["email"] = user.Email
+	s.audit.Record(event)
+	return nil
+}
+
+// ForceLogout revokes every token issued to the user so far. Like the other
+// user actions, it cannot target the admin themselves or another superadmin.
+func (s *service) ForceLogout(actorID, userID uuid.UUID) error {
+	if _, err := s.targetUser(actorID, userID); err != nil {
+		return err
+	}
+	if err := s.userRepo.RevokeTokens(userID, time.Now()); err != nil {
+		log.Printf("Failed to revoke user tokens: %v", err)
+		return err
+	}
+	s.audit.Record(audit.PlatformEvent(actorID, audit.ActionUserLoggedOut, "user", userID.String()))
+	return nil
+}
+
+// Impersonate issues a token for the user carrying an act claim that names
+// the admin. Other superadmins and inactive users cannot be impersonated.
+func (s *service) Impersonate(actorID, userID uuid.UUID, reason string) (*Impersonation, error) {
+	user, err := s.targetUser(actorID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Status != model.UserStatusActive {
+		return nil, ErrTargetInactive
+	}
+
+	token, expiresAt, err := s.authService.IssueImpersonationToken(actorID, userID)
+	if err != nil {
+		log.Printf("Failed to issue impersonation token: %v", err)
+		return nil, err
+	}
+
+	event := audit.PlatformEvent(actorID, audit.ActionUserImpersonated, "user", userID.String())
+	event.Metadata["reason"] = reason
+	event.Metadata["expires_at"] = expiresAt
+	s.audit.Record(event)
+
+	return &Impersonation{Token: token, UserID: userID, ExpiresAt: expiresAt}, nil
+}
+
+func (s *service) targetUser(actorID, userID uuid.UUID) (*model.User, error) {
+	if actorID == userID {
+		return nil, ErrSelfAction
+	}
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.IsSuperadmin() {
+		return nil, ErrTargetIsSuperadmin
+	}
+	return user, nil
+}
@@ -0,0 +1,128 @@
+package audit
+
+import (
+	"log"
+
+	"github.com/google/uuid"
+
+	"github.com/radiatus-ai/auth-service/internal/model"
+	"github.com/radiatus-ai/auth-service/internal/repository"
+)
+
+const (
+	ActorUser   = "user"
+	ActorSystem = "system"
+	ActorAPIKey = "api_key"
+)
+
+const (
+	ActionTransferInitiated = "organization.transfer.initiated"
+	ActionTransferAccepted  = "organization.transfer.accepted"
+	ActionTransferCancelled = "organization.transfer.cancelled"
+	ActionTransferDeclined  = "organization.transfer.declined"
+	ActionMemberLeft        = "organization.member.left"
+	ActionOrgDeleted        = "organization.deleted"
+	ActionOrgRestored       = "organization.restored"
+	ActionOrgPurged         = "organization.purged"
+
+	ActionServiceAccountCreated       = "organization.service_account.created"
+	ActionServiceAccountUpdated       = "organization.service_account.updated"
+	ActionServiceAccountDeleted       = "organization.service_account.deleted"
+	ActionServiceAccountSecretCreated = "organization.service_account.secret_created"
+	ActionServiceAccountSecretDeleted = "organization.service_account.secret_deleted"
+	ActionTrustPolicyCreated          = "organization.service_account.trust_policy_created"
+	ActionTrustPolicyDeleted          = "organization.service_account.trust_policy_deleted"
+	ActionAPIKeyCreated               = "organization.api_key.created"
+	ActionAPIKeyRotated               = "organization.api_key.rotated"
+	ActionAPIKeyRevoked               = "organization.api_key.revoked"
+
+	ActionUserStatusChanged = "admin.user.status_changed"
+	ActionUserDeleted       = "admin.user.deleted"
+	ActionUserLoggedOut     = "admin.user.logged_out"
+	ActionUserImpersonated  = "admin.user.impersonated"
+	ActionClientCreated     = "admin.client.created"
+	ActionClientUpdated     = "admin.client.updated"
+	ActionClientDisabled    = "admin.client.disabled"
+	// ActionImpersonatedRequest is recorded for each request made with an
+	// impersonation token.
+	ActionImpersonatedRequest = "impersonation.request"
+	// ActionAPIKeyRequest is recorded for each change made with an API key.
+	ActionAPIKeyRequest = "api_key.request"
+)
+
+type Logger interface {
+	Record(event *model.AuditEvent)
+	ListByOrganization(orgID uuid.UUID, limit int) ([]model.AuditEvent, error)
+}
+
+type logger struct {
+	repo repository.AuditRepository
+}
+
+func NewLogger(repo repository.AuditRepository) Logger {
+	return &logger{repo: repo}
+}
+
+// Record persists the event. Failures are logged rather than returned so that
+// a problem with the audit table never blocks the action being audited.
+func (l *logger) Record(event *model.AuditEvent) {
+	if err := l.repo.Create(event); err != nil {
+		log.Printf("Failed to record audit event %s by %s %s: %v", event.Action, event.ActorType, event.ActorID, err)
+	}
+}
+
+func (l *logger) ListByOrganization(orgID uuid.UUID, limit int) ([]model.AuditEvent, error) {
+	return l.repo.ListByOrganization(orgID, limit)
+}
+
+// UserEvent builds an event performed by a user within an organization.
+func UserEvent(actorID, orgID uuid.UUID, action, targetType, targetID string) *model.AuditEvent {
+	return &model.AuditEvent{
+		OrganizationID: &orgID,
+		ActorType:      ActorUser,
+		ActorID:        actorID.String(),
+		Action:         action,
+		TargetType:     targetType,
+		TargetID:       targetID,
+		Metadata:       model.JSONMap{},
+	}
+}
+
+// PlatformEvent builds an event performed by a user outside of any single
+// organization, such as a platform admin action.
+func PlatformEvent(actorID uuid.UUID, action, targetType, targetID string) *model.AuditEvent {
+	return &model.AuditEvent{
+		ActorType:  ActorUser,
+		ActorID:    actorID.String(),
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Metadata:   model.JSONMap{},
+	}
+}
+
+// APIKeyEvent builds an event performed with an organization API key.
+func APIKeyEvent(keyID, orgID uuid.UUID, action, targetType, targetID string) *model.AuditEvent {
+	return &model.AuditEvent{
+		OrganizationID: &orgID,
+		ActorType:      ActorAPIKey,
+		ActorID:        keyID.String(),
+		Action:         action,
+		TargetType:     targetType,
+		TargetID:       targetID,
+		Metadata:       model.JSONMap{},
+	}
+}
+
+// SystemEvent builds an event performed by the service itself, such as a
+// background job.
+func SystemEvent(action, targetType, targetID string) *model.AuditEvent {
+	return &model.AuditEvent{
+		ActorType:  ActorSystem,
+		ActorID:    "auth-service",
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Metadata:   model.JSONMap{},
+	}
+}
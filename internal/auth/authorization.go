@@ -0,0 +1,21 @@
+package auth
+
+import "strings"
+
+// ParseAuthorization reads the token from an Authorization header using the
+// Bearer or DPoP scheme, whose names are case-insensitive. dpop reports
+// whether the DPoP scheme was used; ok is false for a malformed header or
+// another scheme.
+func ParseAuthorization(header string) (token string, dpop bool, ok bool) {
+	parts := strings.Fields(header)
+	if len(parts) != 2 {
+		return "", false, false
+	}
+	switch {
+	case strings.EqualFold(parts[0], "bearer"):
+		return parts[1], false, true
+	case strings.EqualFold(parts[0], "dpop"):
+		return parts[1], true, true
+	}
+	return "", false, false
+}
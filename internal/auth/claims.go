@@ -0,0 +1,294 @@
+package auth
+
+import (
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// Subject types distinguish people from machine identities. The subject of a
+// service account token or an API key is the service account's or key's ID.
+const (
+	SubjectUser           = "user"
+	SubjectServiceAccount = "service_account"
+	SubjectAPIKey         = "api_key"
+)
+
+// Claims are the verified contents of an access token.
+type Claims struct {
+	Subject string
+	// SubjectType is SubjectUser, SubjectServiceAccount or SubjectAPIKey.
+	// Tokens without a sub_type claim were issued to users.
+	SubjectType string
+	// Email is the user's address, set on tokens issued at login so
+	// downstream services need not look it up.
+	Email string
+	// SessionID is the ID of the login session a token issued at login, or
+	// refreshed from one, belongs to. The token stops working once the
+	// session is ended.
+	SessionID string
+	// AuthTime is when the user last authenticated in the session, and ACR
+	// and AMR how (OpenID Connect Core section 2). They are set on tokens
+	// issued for a session and let sensitive actions demand a recent, strong
+	// authentication.
+	AuthTime  time.Time
+	ACR       string
+	AMR       []string
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+	// Audience restricts the token to one downstream service. Tokens issued
+	// at login have no audience.
+	Audience string
+	// Scopes limit what the token may be used for. Tokens issued at login
+	// carry no scopes and are not restricted.
+	Scopes []string
+	// ClientID is the OAuth client the token was issued to, if any.
+	ClientID string
+	// Actor is set when someone other than the subject is acting on the
+	// subject's behalf, following the RFC 8693 "act" claim.
+	Actor *Actor
+	// KeyThumbprint binds the token to a DPoP key (RFC 9449), as the jkt
+	// member of the cnf claim. A bound token is only accepted with a proof
+	// signed by that key.
+	KeyThumbprint string
+	// CertificateThumbprint binds the token to a client certificate (RFC
+	// 8705), as the x5t#S256 member of the cnf claim. A bound token is only
+	// accepted over a connection authenticated with that certificate.
+	CertificateThumbprint string
+	// TokenID identifies the personal access token the request was made
+	// with, and OrganizationID the only organization a personal access token
+	// or API key may be used on. TokenID is never part of a JWT.
+	TokenID        string
+	OrganizationID string
+	// Roles is the role of a service account in its organization. Service
+	// account tokens carry it and OrganizationID (as org_id) for downstream
+	// services; this service resolves access from the account itself and
+	// ignores both when verifying.
+	Roles []string
+	// Custom are the claims added by claims templates and the pre-issue
+	// hook. They never replace the claims above.
+	Custom map[string]interface{}
+}
+
+// Actor identifies the party acting on behalf of a token's subject. A user
+// actor (an impersonating admin) has only a Subject; a client actor also
+// carries its ClientID. Actors nest when a delegated token is delegated again.
+type Actor struct {
+	Subject  string
+	ClientID string
+	Actor    *Actor
+}
+
+func (c *Claims) IsServiceAccount() bool {
+	return c.SubjectType == SubjectServiceAccount
+}
+
+func (c *Claims) IsAPIKey() bool {
+	return c.SubjectType == SubjectAPIKey
+}
+
+func (c *Claims) IsPersonalAccessToken() bool {
+	return c.TokenID != ""
+}
+
+func (c *Claims) IsImpersonated() bool {
+	for actor := c.Actor; actor != nil; actor = actor.Actor {
+		if actor.ClientID == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// IsDelegated reports whether an OAuth client acts on the subject's behalf,
+// as in tokens issued by token exchange.
+func (c *Claims) IsDelegated() bool {
+	for actor := c.Actor; actor != nil; actor = actor.Actor {
+		if actor.ClientID != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Impersonator returns the admin acting as the subject, if any.
+func (c *Claims) Impersonator() string {
+	for actor := c.Actor; actor != nil; actor = actor.Actor {
+		if actor.ClientID == "" {
+			return actor.Subject
+		}
+	}
+	return ""
+}
+
+// HasScope reports whether the token may be used for scope. Unscoped tokens
+// may be used for anything.
+func (c *Claims) HasScope(scope string) bool {
+	if len(c.Scopes) == 0 {
+		return true
+	}
+	for _, s := range c.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Claims) mapClaims() jwt.MapClaims {
+	claims := jwt.MapClaims{
+		"sub": c.Subject,
+		"iat": c.IssuedAt.Unix(),
+		"exp": c.ExpiresAt.Unix(),
+	}
+	if c.SubjectType != "" && c.SubjectType != SubjectUser {
+		claims["sub_type"] = c.SubjectType
+	}
+	if c.Email != "" {
+		claims["email"] = c.Email
+	}
+	if c.SessionID != "" {
+		claims["sid"] = c.SessionID
+	}
+	if !c.AuthTime.IsZero() {
+		claims["auth_time"] = c.AuthTime.Unix()
+	}
+	if c.ACR != "" {
+		claims["acr"] = c.ACR
+	}
+	if len(c.AMR) > 0 {
+		claims["amr"] = c.AMR
+	}
+	if c.Audience != "" {
+		claims["aud"] = c.Audience
+	}
+	if len(c.Scopes) > 0 {
+		claims["scope"] = strings.Join(c.Scopes, " ")
+	}
+	if c.ClientID != "" {
+		claims["client_id"] = c.ClientID
+	}
+	if c.Actor != nil {
+		claims["act"] = c.Actor.mapClaim()
+	}
+	if c.KeyThumbprint != "" || c.CertificateThumbprint != "" {
+		cnf := map[string]interface{}{}
+		if c.KeyThumbprint != "" {
+			cnf["jkt"] = c.KeyThumbprint
+		}
+		if c.CertificateThumbprint != "" {
+			cnf["x5t#S256"] = c.CertificateThumbprint
+		}
+		claims["cnf"] = cnf
+	}
+	if c.OrganizationID != "" {
+		claims["org_id"] = c.OrganizationID
+	}
+	if len(c.Roles) > 0 {
+		claims["roles"] = c.Roles
+	}
+	for name, value := range c.Custom {
+		if !reservedClaims[name] {
+			claims[name] = value
+		}
+	}
+	return claims
+}
+
+func (a *Actor) mapClaim() map[string]interface{} {
+	claim := map[string]interface{}{"sub": a.Subject}
+	if a.ClientID != "" {
+		claim["client_id"] = a.ClientID
+	}
+	if a.Actor != nil {
+		claim["act"] = a.Actor.mapClaim()
+	}
+	return claim
+}
+
+func claimsFromMap(m jwt.MapClaims) (*Claims, error) {
+	subject, ok := m["sub"].(string)
+	if !ok || subject == "" {
+		return nil, ErrInvalidToken
+	}
+
+	claims := &Claims{
+		Subject:   subject,
+		IssuedAt:  unixClaim(m, "iat"),
+		ExpiresAt: unixClaim(m, "exp"),
+	}
+	claims.SubjectType, _ = m["sub_type"].(string)
+	switch claims.SubjectType {
+	case "":
+		claims.SubjectType = SubjectUser
+	case SubjectUser, SubjectServiceAccount:
+	default:
+		return nil, ErrInvalidToken
+	}
+	claims.Email, _ = m["email"].(string)
+	claims.SessionID, _ = m["sid"].(string)
+	claims.AuthTime = unixClaim(m, "auth_time")
+	claims.ACR, _ = m["acr"].(string)
+	if amr, ok := m["amr"].([]interface{}); ok {
+		for _, method := range amr {
+			if method, ok := method.(string); ok {
+				claims.AMR = append(claims.AMR, method)
+			}
+		}
+	}
+	claims.Audience, _ = m["aud"].(string)
+	claims.ClientID, _ = m["client_id"].(string)
+	if scope, ok := m["scope"].(string); ok {
+		claims.Scopes = strings.Fields(scope)
+	}
+	if cnf, ok := m["cnf"].(map[string]interface{}); ok {
+		claims.KeyThumbprint, _ = cnf["jkt"].(string)
+		claims.CertificateThumbprint, _ = cnf["x5t#S256"].(string)
+		if claims.KeyThumbprint == "" && claims.CertificateThumbprint == "" {
+			// Bound to something this service cannot check.
+			return nil, ErrInvalidToken
+		}
+	}
+	if act, ok := m["act"].(map[string]interface{}); ok {
+		actor, err := actorFromMap(act)
+		if err != nil {
+			return nil, err
+		}
+		claims.Actor = actor
+	}
+	for name, value := range m {
+		if reservedClaims[name] {
+			continue
+		}
+		if claims.Custom == nil {
+			claims.Custom = map[string]interface{}{}
+		}
+		claims.Custom[name] = value
+	}
+	return claims, nil
+}
+
+func actorFromMap(m map[string]interface{}) (*Actor, error) {
+	subject, ok := m["sub"].(string)
+	if !ok || subject == "" {
+		return nil, ErrInvalidToken
+	}
+	actor := &Actor{Subject: subject}
+	actor.ClientID, _ = m["client_id"].(string)
+	if act, ok := m["act"].(map[string]interface{}); ok {
+		nested, err := actorFromMap(act)
+		if err != nil {
+			return nil, err
+		}
+		actor.Actor = nested
+	}
+	return actor, nil
+}
+
+func unixClaim(m jwt.MapClaims, name string) time.Time {
+	if v, ok := m[name].(float64); ok {
+		return time.Unix(int64(v), 0)
+	}
+	return time.Time{}
+}
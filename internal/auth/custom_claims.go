@@ -0,0 +1,243 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/radiatus-ai/auth-service/internal/model"
+)
+
+var ErrInvalidClaimsConfig = errors.New("invalid claims config")
+
+// reservedClaims are the claims this service sets itself, plus those the
+// introspection response adds. Templates and the pre-issue hook can never
+// set them, so custom claims cannot widen what a token allows.
+var reservedClaims = map[string]bool{
+	"sub": true, "sub_type": true, "iss": true, "iat": true, "nbf": true, "exp": true, "jti": true,
+	"email": true, "sid": true, "auth_time": true, "acr": true, "amr": true,
+	"aud": true, "scope": true, "client_id": true, "act": true, "cnf": true,
+	"org_id": true, "roles": true, "active": true, "token_type": true,
+}
+
+// ClaimsConfig adds custom claims, such as an organization's plan or feature
+// flags, to issued tokens. Templates are applied first, then the hook, whose
+// claims win.
+type ClaimsConfig struct {
+	Templates []ClaimsTemplate `json:"templates"`
+	Hook      *TokenHook       `json:"hook,omitempty"`
+}
+
+// ClaimsTemplate adds claims to tokens for an audience, a client, or both.
+// A template with neither applies to every token. Later templates override
+// earlier ones.
+//
+// A claim value of the form "{{path}}" is looked up in the token's subject:
+// user.id, user.email and user.platform_role; service_account.id, .name,
+// .role and .client_id; org.id, org.name, org.slug and org.settings.<key>;
+// and token.<claim> for the token's own claims. Values that cannot be found
+// are left out. Any other value is added as is.
+type ClaimsTemplate struct {
+	Audience string                 `json:"audience,omitempty"`
+	ClientID string                 `json:"client_id,omitempty"`
+	Claims   map[string]interface{} `json:"claims"`
+}
+
+// LoadClaimsConfig reads a ClaimsConfig from a JSON file. An empty path
+// means no custom claims.
+func LoadClaimsConfig(path string) (*ClaimsConfig, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var config ClaimsConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	if err := config.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &config, nil
+}
+
+func (c *ClaimsConfig) validate() error {
+	for i, template := range c.Templates {
+		for name := range template.Claims {
+			if name == "" || reservedClaims[name] {
+				return fmt.Errorf("template %d: claim %q: %w", i, name, ErrInvalidClaimsConfig)
+			}
+		}
+	}
+	if c.Hook != nil {
+		u, err := url.Parse(c.Hook.URL)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return fmt.Errorf("hook URL %q: %w", c.Hook.URL, ErrInvalidClaimsConfig)
+		}
+		if c.Hook.TimeoutMS < 0 {
+			return fmt.Errorf("hook timeout: %w", ErrInvalidClaimsConfig)
+		}
+	}
+	return nil
+}
+
+// matching returns the templates that apply to claims, in order.
+func (c *ClaimsConfig) matching(claims *Claims) []ClaimsTemplate {
+	var templates []ClaimsTemplate
+	for _, template := range c.Templates {
+		if template.Audience != "" && template.Audience != claims.Audience {
+			continue
+		}
+		if template.ClientID != "" && template.ClientID != claims.ClientID {
+			continue
+		}
+		templates = append(templates, template)
+	}
+	return templates
+}
+
+// customize replaces the custom claims of a token about to be issued with
+// those of the matching templates and the hook. It fails only when the hook
+// fails and is not configured to fail open.
+func (s *service) customize(claims *Claims) error {
+	if s.claimsConfig == nil {
+		return nil
+	}
+	templates := s.claimsConfig.matching(claims)
+	hook := s.claimsConfig.Hook
+	claims.Custom = nil
+	if len(templates) == 0 && hook == nil {
+		return nil
+	}
+
+	data := s.subjectData(claims)
+	custom := map[string]interface{}{}
+	for _, template := range templates {
+		for name, value := range template.Claims {
+			if resolved, ok := resolveClaim(value, data); ok {
+				custom[name] = resolved
+			}
+		}
+	}
+
+	if hook != nil {
+		claims.Custom = custom
+		data["token"] = map[string]interface{}(claims.mapClaims())
+		added, err := hook.call(data)
+		if err != nil {
+			if !hook.FailOpen {
+				log.Printf("Token hook failed for %s: %v", claims.Subject, err)
+				return fmt.Errorf("%w: %v", ErrTokenHookFailed, err)
+			}
+			log.Printf("Token hook failed for %s, issuing without its claims: %v", claims.Subject, err)
+		}
+		for name, value := range added {
+			if name == "" || reservedClaims[name] {
+				log.Printf("Ignored reserved claim %q from token hook", name)
+				continue
+			}
+			custom[name] = value
+		}
+	}
+
+	claims.Custom = nil
+	if len(custom) > 0 {
+		claims.Custom = custom
+	}
+	return nil
+}
+
+// subjectData is what templates and the hook can see of a token's subject.
+// Lookups that fail are logged and left out, so a missing organization only
+// costs the claims that depend on it.
+func (s *service) subjectData(claims *Claims) map[string]interface{} {
+	data := map[string]interface{}{"token": map[string]interface{}(claims.mapClaims())}
+
+	var org *model.Organization
+	var err error
+	switch claims.SubjectType {
+	case SubjectServiceAccount:
+		var account *model.ServiceAccount
+		if account, err = s.GetServiceAccountByID(claims.Subject); err == nil {
+			data["service_account"] = map[string]interface{}{
+				"id":        account.ID.String(),
+				"name":      account.Name,
+				"role":      account.Role,
+				"client_id": account.ClientID,
+			}
+			org, err = s.orgRepo.GetByID(account.OrganizationID)
+		}
+	case SubjectUser, "":
+		var user *model.User
+		if user, err = s.GetUserByID(claims.Subject); err == nil {
+			data["user"] = map[string]interface{}{
+				"id":            user.ID.String(),
+				"email":         user.Email,
+				"platform_role": user.PlatformRole,
+			}
+			if claims.OrganizationID != "" {
+				var orgID uuid.UUID
+				if orgID, err = uuid.Parse(claims.OrganizationID); err == nil {
+					org, err = s.orgRepo.GetByID(orgID)
+				}
+			} else {
+				org, err = s.orgRepo.GetUserOrganization(user.ID)
+			}
+		}
+	}
+	if err != nil {
+		log.Printf("Custom claims for %s are missing subject data: %v", claims.Subject, err)
+	}
+
+	if org != nil {
+		data["org"] = map[string]interface{}{
+			"id":       org.ID.String(),
+			"name":     org.Name,
+			"slug":     org.Slug,
+			"settings": map[string]interface{}(org.Settings),
+		}
+	}
+	return data
+}
+
+// resolveClaim expands a "{{path}}" template value.
+func resolveClaim(value interface{}, data map[string]interface{}) (interface{}, bool) {
+	template, ok := value.(string)
+	if !ok {
+		return value, true
+	}
+	path, found := strings.CutPrefix(template, "{{")
+	if !found {
+		return value, true
+	}
+	path, found = strings.CutSuffix(path, "}}")
+	if !found {
+		return value, true
+	}
+
+	var current interface{} = data
+	for _, key := range strings.Split(strings.TrimSpace(path), ".") {
+		switch m := current.(type) {
+		case map[string]interface{}:
+			current, ok = m[key]
+		case model.JSONMap:
+			current, ok = m[key]
+		default:
+			ok = false
+		}
+		if !ok {
+			return nil, false
+		}
+	}
+	if current == nil || current == "" {
+		return nil, false
+	}
+	return current, true
+}
@@ -0,0 +1,179 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+	"github.com/radiatus-ai/auth-service/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func newCustomClaimsTestService(t *testing.T, config *ClaimsConfig) (*service, *model.User) {
+	user := &model.User{ID: uuid.New(), Email: "ada@example.com", Status: model.UserStatusActive}
+	org := &model.Organization{ID: uuid.New(), Name: "Acme", Slug: "acme", Settings: model.Settings{
+		"plan":     "enterprise",
+		"features": map[string]interface{}{"beta": true},
+	}}
+	svc := newVerifyTestService(user)
+	svc.orgRepo = &stubOrganizationRepository{orgs: map[uuid.UUID]*model.Organization{org.ID: org}}
+	assert.NoError(t, config.validate())
+	svc.claimsConfig = config
+	return svc, user
+}
+
+// issueCustomized signs claims for user and returns the payload of the token.
+func issueCustomized(t *testing.T, s *service, user *model.User, audience, clientID string) jwt.MapClaims {
+	token, err := s.IssueToken(&Claims{
+		Subject:   user.ID.String(),
+		Email:     user.Email,
+		ExpiresAt: time.Now().Add(time.Minute),
+		Audience:  audience,
+		ClientID:  clientID,
+	})
+	assert.NoError(t, err)
+	parsed, err := jwt.Parse(token, s.verificationKey)
+	assert.NoError(t, err)
+	return parsed.Claims.(jwt.MapClaims)
+}
+
+func TestClaimsTemplates(t *testing.T) {
+	svc, user := newCustomClaimsTestService(t, &ClaimsConfig{Templates: []ClaimsTemplate{
+		{Claims: map[string]interface{}{"tenant": "{{org.slug}}"}},
+		{Audience: "billing", Claims: map[string]interface{}{
+			"plan":     "{{org.settings.plan}}",
+			"features": "{{ org.settings.features }}",
+			"tier":     "gold",
+			"missing":  "{{org.settings.nope}}",
+		}},
+		{ClientID: "gateway", Claims: map[string]interface{}{"tier": "platinum", "user_email": "{{user.email}}"}},
+	}})
+
+	claims := issueCustomized(t, svc, user, "billing", "")
+	assert.Equal(t, "acme", claims["tenant"])
+	assert.Equal(t, "enterprise", claims["plan"])
+	assert.Equal(t, map[string]interface{}{"beta": true}, claims["features"])
+	assert.Equal(t, "gold", claims["tier"])
+	assert.NotContains(t, claims, "missing")
+	assert.NotContains(t, claims, "user_email")
+
+	claims = issueCustomized(t, svc, user, "billing", "gateway")
+	assert.Equal(t, "platinum", claims["tier"], "later templates win")
+	assert.Equal(t, user.Email, claims["user_email"])
+
+	claims = issueCustomized(t, svc, user, "search", "")
+	assert.Equal(t, "acme", claims["tenant"])
+	assert.NotContains(t, claims, "plan")
+}
+
+func TestClaimsConfig_RejectsReservedClaims(t *testing.T) {
+	for _, name := range []string{"sub", "scope", "roles", "cnf", "org_id", ""} {
+		config := &ClaimsConfig{Templates: []ClaimsTemplate{{Claims: map[string]interface{}{name: "x"}}}}
+		assert.ErrorIs(t, config.validate(), ErrInvalidClaimsConfig, name)
+	}
+	config := &ClaimsConfig{Hook: &TokenHook{URL: "ftp://hooks.example.com"}}
+	assert.ErrorIs(t, config.validate(), ErrInvalidClaimsConfig)
+}
+
+func TestLoadClaimsConfig(t *testing.T) {
+	config, err := LoadClaimsConfig("")
+	assert.NoError(t, err)
+	assert.Nil(t, config)
+
+	path := filepath.Join(t.TempDir(), "claims.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`{
+		"templates": [{"audience": "billing", "claims": {"plan": "{{org.settings.plan}}"}}],
+		"hook": {"url": "https://hooks.example.com/token", "timeout_ms": 500, "fail_open": true}
+	}`), 0o600))
+	config, err = LoadClaimsConfig(path)
+	assert.NoError(t, err)
+	if assert.NotNil(t, config) {
+		assert.Len(t, config.Templates, 1)
+		assert.Equal(t, 500*time.Millisecond, config.Hook.timeout())
+		assert.True(t, config.Hook.FailOpen)
+	}
+
+	assert.NoError(t, os.WriteFile(path, []byte(`{"templates": [{"claims": {"aud": "x"}}]}`), 0o600))
+	_, err = LoadClaimsConfig(path)
+	assert.ErrorIs(t, err, ErrInvalidClaimsConfig)
+}
+
+func TestTokenHook(t *testing.T) {
+	var received map[string]interface{}
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mac := hmac.New(sha256.New, []byte("hook-secret"))
+		mac.Write(body)
+		assert.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), r.Header.Get(TokenHookSignatureHeader))
+		assert.NoError(t, json.Unmarshal(body, &received))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"claims": {"display_name": "Ada Lovelace", "tier": "hook", "scope": "admin"}}`))
+	}))
+	defer hook.Close()
+
+	svc, user := newCustomClaimsTestService(t, &ClaimsConfig{
+		Templates: []ClaimsTemplate{{Claims: map[string]interface{}{"tier": "template"}}},
+		Hook:      &TokenHook{URL: hook.URL, Secret: "hook-secret"},
+	})
+	claims := issueCustomized(t, svc, user, "billing", "")
+
+	assert.Equal(t, "Ada Lovelace", claims["display_name"])
+	assert.Equal(t, "hook", claims["tier"], "the hook overrides templates")
+	assert.NotContains(t, claims, "scope", "reserved claims from the hook are ignored")
+
+	token := received["token"].(map[string]interface{})
+	assert.Equal(t, user.ID.String(), token["sub"])
+	assert.Equal(t, "billing", token["aud"])
+	assert.Equal(t, "template", token["tier"], "the hook sees template claims")
+	assert.Equal(t, user.Email, received["user"].(map[string]interface{})["email"])
+	assert.Equal(t, "acme", received["org"].(map[string]interface{})["slug"])
+}
+
+func TestTokenHook_FailurePolicy(t *testing.T) {
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		w.Write([]byte(`{"claims": {"tier": "late"}}`))
+	}))
+	defer slow.Close()
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer broken.Close()
+
+	for name, url := range map[string]string{"timeout": slow.URL, "error status": broken.URL} {
+		t.Run(name, func(t *testing.T) {
+			svc, user := newCustomClaimsTestService(t, &ClaimsConfig{
+				Templates: []ClaimsTemplate{{Claims: map[string]interface{}{"tier": "template"}}},
+				Hook:      &TokenHook{URL: url, TimeoutMS: 50},
+			})
+			_, err := svc.IssueToken(&Claims{Subject: user.ID.String(), ExpiresAt: time.Now().Add(time.Minute)})
+			assert.ErrorIs(t, err, ErrTokenHookFailed, "hooks fail closed by default")
+
+			svc.claimsConfig.Hook.FailOpen = true
+			claims := issueCustomized(t, svc, user, "", "")
+			assert.Equal(t, "template", claims["tier"], "failing open keeps template claims")
+		})
+	}
+}
+
+func TestCustomClaims_ReferenceTokens(t *testing.T) {
+	svc, user := newCustomClaimsTestService(t, &ClaimsConfig{Templates: []ClaimsTemplate{
+		{Claims: map[string]interface{}{"plan": "{{org.settings.plan}}"}},
+	}})
+
+	token, err := svc.IssueReferenceToken(&Claims{Subject: user.ID.String(), ExpiresAt: time.Now().Add(time.Minute)}, "gateway")
+	assert.NoError(t, err)
+	claims, err := svc.VerifyClaims(token)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"plan": "enterprise"}, claims.Custom)
+}
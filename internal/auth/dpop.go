@@ -0,0 +1,27 @@
+package auth
+
+import (
+	"net/http"
+
+	"github.com/radiatus-ai/auth-service/pkg/dpop"
+)
+
+// DPoPHeader carries a DPoP proof (RFC 9449): a JWT signed with a key the
+// client holds, naming the request it was made for.
+const DPoPHeader = dpop.Header
+
+var ErrInvalidDPoPProof = dpop.ErrInvalidProof
+
+// VerifyDPoPProof checks a proof was made for a method and URL request, just
+// now and only once, and returns the thumbprint of the key it was signed
+// with. When presented with an access token, the proof must also be bound to
+// it through the ath claim; at the token endpoint accessToken is "".
+func (s *service) VerifyDPoPProof(proof, method, url, accessToken string) (string, error) {
+	return s.dpop.Verify(proof, method, url, accessToken)
+}
+
+// RequestURL is the URL a client called to make r, as a DPoP proof's htu
+// names it.
+func RequestURL(r *http.Request) string {
+	return dpop.RequestURL(r)
+}
@@ -0,0 +1,152 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+	"github.com/radiatus-ai/auth-service/internal/model"
+	"github.com/radiatus-ai/auth-service/pkg/jwks"
+	"github.com/stretchr/testify/assert"
+)
+
+const testDPoPURL = "https://auth.example.com/api/organizations"
+
+type dpopTestKey struct {
+	key *ecdsa.PrivateKey
+	jwk map[string]interface{}
+	jkt string
+}
+
+func newDPoPTestKey(t *testing.T) *dpopTestKey {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	coordinate := func(b []byte) string {
+		padded := make([]byte, 32)
+		copy(padded[32-len(b):], b)
+		return base64.RawURLEncoding.EncodeToString(padded)
+	}
+	public := jwks.JSONWebKey{Kty: "EC", Crv: "P-256", X: coordinate(key.X.Bytes()), Y: coordinate(key.Y.Bytes())}
+	jkt, err := public.Thumbprint()
+	assert.NoError(t, err)
+	return &dpopTestKey{
+		key: key,
+		jwk: map[string]interface{}{"kty": public.Kty, "crv": public.Crv, "x": public.X, "y": public.Y},
+		jkt: jkt,
+	}
+}
+
+// proof signs a DPoP proof, applying edit to its claims and header first.
+func (k *dpopTestKey) proof(t *testing.T, method, url, accessToken string, edit func(jwt.MapClaims, map[string]interface{})) string {
+	claims := jwt.MapClaims{
+		"jti": uuid.NewString(),
+		"htm": method,
+		"htu": url,
+		"iat": time.Now().Unix(),
+	}
+	if accessToken != "" {
+		sum := sha256.Sum256([]byte(accessToken))
+		claims["ath"] = base64.RawURLEncoding.EncodeToString(sum[:])
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
+	token.Header["typ"] = "dpop+jwt"
+	token.Header["jwk"] = k.jwk
+	if edit != nil {
+		edit(claims, token.Header)
+	}
+	signed, err := token.SignedString(k.key)
+	assert.NoError(t, err)
+	return signed
+}
+
+func TestVerifyDPoPProof(t *testing.T) {
+	svc := newVerifyTestService()
+	key := newDPoPTestKey(t)
+
+	proof := key.proof(t, "GET", testDPoPURL, "access-token", nil)
+	jkt, err := svc.VerifyDPoPProof(proof, "GET", testDPoPURL+"?page=2", "access-token")
+	assert.NoError(t, err)
+	assert.Equal(t, key.jkt, jkt)
+
+	_, err = svc.VerifyDPoPProof(proof, "GET", testDPoPURL, "access-token")
+	assert.ErrorIs(t, err, ErrInvalidDPoPProof, "proofs can only be used once")
+
+	for name, tc := range map[string]struct {
+		method, url, accessToken string
+		edit                     func(jwt.MapClaims, map[string]interface{})
+	}{
+		"missing proof":       {},
+		"wrong method":        {method: "POST", url: testDPoPURL},
+		"wrong URL":           {method: "GET", url: "https://auth.example.com/api/users"},
+		"other access token":  {method: "GET", url: testDPoPURL, accessToken: "other-token"},
+		"missing ath":         {method: "GET", url: testDPoPURL, edit: func(c jwt.MapClaims, _ map[string]interface{}) { delete(c, "ath") }},
+		"stale":               {method: "GET", url: testDPoPURL, edit: func(c jwt.MapClaims, _ map[string]interface{}) { c["iat"] = time.Now().Add(-10 * time.Minute).Unix() }},
+		"from the future":     {method: "GET", url: testDPoPURL, edit: func(c jwt.MapClaims, _ map[string]interface{}) { c["iat"] = time.Now().Add(10 * time.Minute).Unix() }},
+		"missing jti":         {method: "GET", url: testDPoPURL, edit: func(c jwt.MapClaims, _ map[string]interface{}) { delete(c, "jti") }},
+		"wrong typ":           {method: "GET", url: testDPoPURL, edit: func(_ jwt.MapClaims, h map[string]interface{}) { h["typ"] = "JWT" }},
+		"missing jwk":         {method: "GET", url: testDPoPURL, edit: func(_ jwt.MapClaims, h map[string]interface{}) { delete(h, "jwk") }},
+		"signed by other key": {method: "GET", url: testDPoPURL, edit: func(_ jwt.MapClaims, h map[string]interface{}) { h["jwk"] = newDPoPTestKey(t).jwk }},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var proof string
+			if tc.method != "" {
+				proof = key.proof(t, "GET", testDPoPURL, "access-token", tc.edit)
+			}
+			accessToken := tc.accessToken
+			if accessToken == "" {
+				accessToken = "access-token"
+			}
+			_, err := svc.VerifyDPoPProof(proof, tc.method, tc.url, accessToken)
+			assert.ErrorIs(t, err, ErrInvalidDPoPProof)
+		})
+	}
+}
+
+func TestVerifyDPoPProof_RejectsSymmetricKeys(t *testing.T) {
+	svc := newVerifyTestService()
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"jti": uuid.NewString(),
+		"htm": "GET",
+		"htu": testDPoPURL,
+		"iat": time.Now().Unix(),
+	})
+	token.Header["typ"] = "dpop+jwt"
+	token.Header["jwk"] = map[string]interface{}{"kty": "oct", "k": "c2VjcmV0"}
+	proof, err := token.SignedString([]byte("secret"))
+	assert.NoError(t, err)
+
+	_, err = svc.VerifyDPoPProof(proof, "GET", testDPoPURL, "")
+	assert.ErrorIs(t, err, ErrInvalidDPoPProof)
+}
+
+func TestDPoPBoundSession(t *testing.T) {
+	user := &model.User{ID: uuid.New(), Email: "ada@example.com", Status: model.UserStatusActive}
+	svc := newVerifyTestService(user)
+	key := newDPoPTestKey(t)
+
+	claims, refreshToken, err := svc.startSession(user, model.AuthMethodGoogle, ACRSingleFactor, []string{AMRFederated}, ClientInfo{KeyThumbprint: key.jkt})
+	assert.NoError(t, err)
+	token, err := svc.signClaims(claims)
+	assert.NoError(t, err)
+	verified, err := svc.VerifyClaims(token)
+	assert.NoError(t, err)
+	assert.Equal(t, key.jkt, verified.KeyThumbprint)
+
+	_, err = svc.RefreshAccessToken(refreshToken, "")
+	assert.ErrorIs(t, err, ErrInvalidDPoPProof, "bound refresh tokens need a proof")
+	_, err = svc.RefreshAccessToken(refreshToken, newDPoPTestKey(t).jkt)
+	assert.ErrorIs(t, err, ErrInvalidDPoPProof)
+
+	tokens, err := svc.RefreshAccessToken(refreshToken, key.jkt)
+	assert.NoError(t, err)
+	assert.Equal(t, key.jkt, tokens.KeyThumbprint)
+	refreshed, err := svc.VerifyClaims(tokens.AccessToken)
+	assert.NoError(t, err)
+	assert.Equal(t, key.jkt, refreshed.KeyThumbprint)
+}
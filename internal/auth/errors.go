@@ -1,11 +1,74 @@
 package auth
 
-import "errors"
+import (
+	"errors"
+
+	"github.com/radiatus-ai/auth-service/internal/model"
+)
 
 var (
 	ErrUserAlreadyExists  = errors.New("user already exists")
 	ErrInvalidCredentials = errors.New("invalid credentials")
 	ErrUnauthorizedEmail  = errors.New("email not authorized")
 	ErrInvalidToken       = errors.New("invalid token")
+	ErrTokenRevoked       = errors.New("token has been revoked")
+	ErrUserSuspended      = errors.New("user is suspended")
+	ErrUserDeactivated    = errors.New("user is deactivated")
+	ErrUserLocked         = errors.New("user is locked")
 	// Add other auth-related errors here
 )
+
+// Error codes returned to clients alongside a rejected authentication attempt.
+const (
+	CodeInvalidToken      = "invalid_token"
+	CodeTokenRevoked      = "token_revoked"
+	CodeUnauthorizedEmail = "unauthorized_email"
+	CodeUserSuspended     = "user_suspended"
+	CodeUserDeactivated   = "user_deactivated"
+	CodeUserLocked        = "user_locked"
+	CodeAddressNotAllowed = "address_not_allowed"
+)
+
+var errorCodes = map[error]string{
+	ErrTokenRevoked:      CodeTokenRevoked,
+	ErrUnauthorizedEmail: CodeUnauthorizedEmail,
+	ErrUserSuspended:     CodeUserSuspended,
+	ErrUserDeactivated:   CodeUserDeactivated,
+	ErrUserLocked:        CodeUserLocked,
+	ErrAddressNotAllowed: CodeAddressNotAllowed,
+}
+
+// ErrorCode returns the client-facing code for an authentication error.
+// Anything unrecognized is reported as an invalid token.
+func ErrorCode(err error) string {
+	for target, code := range errorCodes {
+		if errors.Is(err, target) {
+			return code
+		}
+	}
+	return CodeInvalidToken
+}
+
+// IsAccountStatusError reports whether err rejects a user because of the
+// status of their account rather than because of their credentials.
+func IsAccountStatusError(err error) bool {
+	return errors.Is(err, ErrUserSuspended) ||
+		errors.Is(err, ErrUserDeactivated) ||
+		errors.Is(err, ErrUserLocked)
+}
+
+// statusError maps a user's account status to the error that rejects them,
+// or nil if the account is active.
+func statusError(status string) error {
+	switch status {
+	case model.UserStatusActive:
+		return nil
+	case model.UserStatusSuspended:
+		return ErrUserSuspended
+	case model.UserStatusDeactivated:
+		return ErrUserDeactivated
+	case model.UserStatusLocked:
+		return ErrUserLocked
+	}
+	return ErrInvalidCredentials
+}
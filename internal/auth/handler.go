@@ -1,22 +1,32 @@
 package auth
 
 import (
+	"errors"
+	"log"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 )
 
 type Handler struct {
-	service Service
+	service  Service
+	sessions *SessionCookies
 }
 
-func NewHandler(service Service) *Handler {
-	return &Handler{service: service}
+// NewHandler returns the login handlers. sessions may be nil, in which case
+// logins cannot ask for a cookie session.
+func NewHandler(service Service, sessions *SessionCookies) *Handler {
+	return &Handler{service: service, sessions: sessions}
 }
 
+// LoginGoogle signs a user in with a Google ID token. By default the access
+// token is returned in the body; with "cookie": true it is set in an HttpOnly
+// session cookie instead, and the body carries the CSRF token to send with
+// unsafe requests.
 func (h *Handler) LoginGoogle(c *gin.Context) {
 	var req struct {
-		Token string `json:"token" binding:"required"`
+		Token  string `json:"token" binding:"required"`
+		Cookie bool   `json:"cookie"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -24,9 +34,27 @@ func (h *Handler) LoginGoogle(c *gin.Context) {
 		return
 	}
 
-	userData, err := h.service.LoginGoogle(req.Token)
+	client := ClientInfo{
+		UserAgent: c.Request.UserAgent(),
+		IPAddress: c.ClientIP(),
+	}
+	if proof := c.GetHeader(DPoPHeader); proof != "" {
+		jkt, err := h.service.VerifyDPoPProof(proof, c.Request.Method, RequestURL(c.Request), "")
+		if err != nil {
+			log.Printf("Rejected DPoP proof at login: %v", err)
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid DPoP proof", "code": "invalid_dpop_proof"})
+			return
+		}
+		client.KeyThumbprint = jkt
+	}
+
+	userData, err := h.service.LoginGoogle(req.Token, client)
 	if err == ErrUnauthorizedEmail {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized email"})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized email", "code": CodeUnauthorizedEmail})
+		return
+	}
+	if IsAccountStatusError(err) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error(), "code": ErrorCode(err)})
 		return
 	}
 	if err != nil {
@@ -34,30 +62,194 @@ func (h *Handler) LoginGoogle(c *gin.Context) {
 		return
 	}
 
+	if req.Cookie {
+		if h.sessions == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Cookie sessions are not enabled"})
+			return
+		}
+		csrfToken := h.service.CSRFToken(userData.SessionID)
+		h.sessions.Set(c, userData.Token, csrfToken, userData.ExpiresAt)
+		c.JSON(http.StatusOK, gin.H{
+			"user":            userData.User,
+			"organization_id": userData.OrganizationID,
+			"csrf_token":      csrfToken,
+		})
+		return
+	}
+
 	c.JSON(http.StatusOK, userData)
 }
 
-func (h *Handler) VerifyToken(c *gin.Context) {
+// Reauthenticate lets the signed-in user sign in again with a Google ID token
+// to satisfy a step-up challenge. The new token belongs to the same session
+// and carries a fresh auth_time; cookie sessions get it in their cookie.
+func (h *Handler) Reauthenticate(c *gin.Context) {
 	var req struct {
 		Token string `json:"token" binding:"required"`
 	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	value, _ := c.Get("claims")
+	claims, ok := value.(*Claims)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user"})
+		return
+	}
+
+	token, expiresAt, err := h.service.Reauthenticate(claims, req.Token)
+	switch {
+	case errors.Is(err, ErrNoSession):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only signed-in sessions can re-authenticate"})
+		return
+	case errors.Is(err, ErrInvalidIdentityToken):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		return
+	case errors.Is(err, ErrReauthenticationUser):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to re-authenticate"})
+		return
+	}
+
+	if h.sessions != nil && c.GetHeader("Authorization") == "" {
+		h.sessions.Set(c, token, h.service.CSRFToken(claims.SessionID), expiresAt)
+		c.JSON(http.StatusOK, gin.H{"expires_at": expiresAt})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"token": token, "expires_at": expiresAt})
+}
+
+// Logout ends the session of the token in the Authorization header or the
+// session cookie, and clears the cookie. A malformed Authorization header is
+// rejected rather than treated as a logout without a session.
+func (h *Handler) Logout(c *gin.Context) {
+	token := ""
+	if header := c.GetHeader("Authorization"); header != "" {
+		var ok bool
+		if token, _, ok = ParseAuthorization(header); !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid authorization header format"})
+			return
+		}
+	} else if h.sessions != nil {
+		token = h.sessions.Token(c)
+	}
+	if token != "" {
+		if err := h.service.EndSession(token); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log out"})
+			return
+		}
+	}
+	if h.sessions != nil {
+		h.sessions.Clear(c)
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// VerifyToken verifies a token for a downstream service. A token bound to a
+// client certificate is only valid with the thumbprint of the certificate
+// the service's client connected with.
+func (h *Handler) VerifyToken(c *gin.Context) {
+	var req struct {
+		Token                 string `json:"token" binding:"required"`
+		CertificateThumbprint string `json:"certificate_thumbprint"`
+	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	userID, err := h.service.VerifyToken(req.Token)
+	claims, err := h.service.VerifyClaims(req.Token)
+	if err == nil {
+		err = CheckCertificateBinding(claims, req.CertificateThumbprint)
+	}
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token", "code": ErrorCode(err)})
 		return
 	}
 
-	user, err := h.service.GetUserByID(userID)
+	if claims.IsServiceAccount() {
+		account, err := h.service.GetServiceAccountByID(claims.Subject)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve service account data"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"subject_type": claims.SubjectType, "service_account": account})
+		return
+	}
+
+	user, err := h.service.GetUserByID(claims.Subject)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve user data"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"user": user})
+	c.JSON(http.StatusOK, gin.H{"subject_type": claims.SubjectType, "user": user})
+}
+
+// JWKS publishes the keys tokens are signed with, for services verifying
+// tokens locally.
+func (h *Handler) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.service.JWKS())
+}
+
+// Introspect implements OAuth 2.0 token introspection (RFC 7662) for every
+// kind of token, including the opaque ones downstream services cannot verify
+// themselves. Besides the standard members the response carries sub_type,
+// email, org_id and roles. A client_ip parameter passes the address of the
+// original caller, for API keys with an allowlist.
+//
+// Callers must be authenticated, by oauth.Handler.RequireClient, which sets
+// "client_id"; requests without it are refused.
+func (h *Handler) Introspect(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+
+	if c.GetString("client_id") == "" {
+		c.Header("WWW-Authenticate", `Basic realm="token"`)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid_client"})
+		return
+	}
+
+	token := c.PostForm("token")
+	if token == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_request", "error_description": "token is required"})
+		return
+	}
+
+	claims, err := h.service.VerifyRequest(token, c.PostForm("client_ip"))
+	if err != nil {
+		c.JSON(http.StatusOK, gin.H{"active": false})
+		return
+	}
+
+	resp := claims.mapClaims()
+	resp["active"] = true
+	resp["token_type"] = "Bearer"
+	resp["sub_type"] = claims.SubjectType
+	if claims.ExpiresAt.IsZero() {
+		delete(resp, "exp")
+	}
+	switch {
+	case claims.IsServiceAccount():
+		account, err := h.service.GetServiceAccountByID(claims.Subject)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve service account data"})
+			return
+		}
+		resp["org_id"] = account.OrganizationID.String()
+		resp["roles"] = []string{account.Role}
+	case claims.SubjectType == SubjectUser && claims.Email == "":
+		user, err := h.service.GetUserByID(claims.Subject)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve user data"})
+			return
+		}
+		resp["email"] = user.Email
+	}
+
+	c.JSON(http.StatusOK, resp)
 }
@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+var ErrCertificateMismatch = errors.New("token is bound to another client certificate")
+
+// CertificateThumbprint is the x5t#S256 thumbprint of a client certificate
+// (RFC 8705 section 3.1): the SHA-256 hash of its DER encoding.
+func CertificateThumbprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// ClientCertificate returns the certificate the client making r
+// authenticated with, if the server terminated TLS and verified it against
+// its client CA bundle.
+func ClientCertificate(r *http.Request) *x509.Certificate {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return nil
+	}
+	return r.TLS.VerifiedChains[0][0]
+}
+
+// ClientCertificateThumbprint is the thumbprint of the verified certificate
+// the client making r authenticated with, or "" if there is none.
+func ClientCertificateThumbprint(r *http.Request) string {
+	cert := ClientCertificate(r)
+	if cert == nil {
+		return ""
+	}
+	return CertificateThumbprint(cert)
+}
+
+// CheckCertificateBinding rejects a token bound to a client certificate that
+// was presented without it. thumbprint is the x5t#S256 thumbprint of the
+// certificate the client authenticated with, or "" if it did not.
+func CheckCertificateBinding(claims *Claims, thumbprint string) error {
+	if claims.CertificateThumbprint != "" && claims.CertificateThumbprint != thumbprint {
+		return ErrCertificateMismatch
+	}
+	return nil
+}
+
+// ParseForwardedCertificate reads a client certificate passed on by a proxy
+// that terminated TLS: URL-encoded PEM, as sent by nginx
+// ($ssl_client_escaped_cert) and Envoy, or base64 DER, as sent by Traefik.
+func ParseForwardedCertificate(value string) (*x509.Certificate, error) {
+	decoded, err := url.PathUnescape(value)
+	if err != nil {
+		return nil, err
+	}
+	var der []byte
+	if block, _ := pem.Decode([]byte(decoded)); block != nil {
+		der = block.Bytes
+	} else if der, err = base64.StdEncoding.DecodeString(strings.TrimSpace(decoded)); err != nil {
+		return nil, errors.New("client certificate is neither PEM nor base64 DER")
+	}
+	return x509.ParseCertificate(der)
+}
@@ -0,0 +1,99 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestCertificate(t *testing.T, commonName string) *x509.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName, Organization: []string{"Radiatus"}},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.NoError(t, err)
+	return cert
+}
+
+func TestCertificateBoundClaims(t *testing.T) {
+	svc := newVerifyTestService()
+	cert := newTestCertificate(t, "billing")
+	thumbprint := CertificateThumbprint(cert)
+
+	token, err := svc.IssueToken(&Claims{
+		Subject:               "00000000-0000-0000-0000-000000000001",
+		SubjectType:           SubjectServiceAccount,
+		IssuedAt:              time.Now(),
+		ExpiresAt:             time.Now().Add(time.Minute),
+		CertificateThumbprint: thumbprint,
+	})
+	assert.NoError(t, err)
+	parsed, err := jwt.Parse(token, svc.verificationKey)
+	assert.NoError(t, err)
+	claims, err := claimsFromMap(parsed.Claims.(jwt.MapClaims))
+	assert.NoError(t, err)
+	assert.Equal(t, thumbprint, claims.CertificateThumbprint)
+	assert.Empty(t, claims.KeyThumbprint)
+
+	assert.NoError(t, CheckCertificateBinding(claims, thumbprint))
+	assert.ErrorIs(t, CheckCertificateBinding(claims, ""), ErrCertificateMismatch)
+	assert.ErrorIs(t, CheckCertificateBinding(claims, CertificateThumbprint(newTestCertificate(t, "other"))), ErrCertificateMismatch)
+	assert.NoError(t, CheckCertificateBinding(&Claims{}, ""), "unbound tokens need no certificate")
+}
+
+func TestClientCertificate(t *testing.T) {
+	cert := newTestCertificate(t, "billing")
+
+	req := httptest.NewRequest("POST", "/oauth/token", nil)
+	assert.Nil(t, ClientCertificate(req))
+	assert.Empty(t, ClientCertificateThumbprint(req))
+
+	req.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}
+	assert.Nil(t, ClientCertificate(req), "unverified certificates are ignored")
+
+	req.TLS.VerifiedChains = [][]*x509.Certificate{{cert}}
+	assert.Equal(t, cert, ClientCertificate(req))
+	assert.Equal(t, CertificateThumbprint(cert), ClientCertificateThumbprint(req))
+}
+
+func TestParseForwardedCertificate(t *testing.T) {
+	cert := newTestCertificate(t, "billing")
+	pemCert := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}))
+
+	for name, value := range map[string]string{
+		"URL-encoded PEM": url.PathEscape(pemCert),
+		"base64 DER":      base64.StdEncoding.EncodeToString(cert.Raw),
+	} {
+		t.Run(name, func(t *testing.T) {
+			parsed, err := ParseForwardedCertificate(value)
+			assert.NoError(t, err)
+			if assert.NotNil(t, parsed) {
+				assert.Equal(t, CertificateThumbprint(cert), CertificateThumbprint(parsed))
+			}
+		})
+	}
+
+	_, err := ParseForwardedCertificate("not a certificate")
+	assert.Error(t, err)
+}
@@ -0,0 +1,34 @@
+package auth
+
+import (
+	"log"
+	"time"
+)
+
+// StartPurgeJob runs PurgeExpiredReferenceTokens every interval until the
+// returned stop function is called.
+func StartPurgeJob(service Service, interval time.Duration) (stop func()) {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				purged, err := service.PurgeExpiredReferenceTokens()
+				if err != nil {
+					log.Printf("Reference token purge failed: %v", err)
+					continue
+				}
+				if purged > 0 {
+					log.Printf("Purged %d expired reference tokens", purged)
+				}
+			case <-done:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+
+	return func() { close(done) }
+}
@@ -0,0 +1,123 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/radiatus-ai/auth-service/internal/credential"
+	"github.com/radiatus-ai/auth-service/internal/model"
+	"github.com/radiatus-ai/auth-service/internal/repository"
+)
+
+// ReferenceTokenPrefix starts every reference token. Reference tokens are
+// opaque access tokens: their claims are stored here rather than in the
+// token, so nothing about the subject can be read from them and revoking one
+// takes effect immediately.
+const ReferenceTokenPrefix = "r7at_"
+
+var ErrTokenNotOwned = errors.New("token was issued to another client")
+
+func isReferenceToken(token string) bool {
+	return strings.HasPrefix(token, ReferenceTokenPrefix)
+}
+
+// IssueReferenceToken stores claims and returns an opaque handle for them,
+// for clients that must not be given readable tokens. clientID records who
+// the token was issued to, so that only they can revoke it.
+func (s *service) IssueReferenceToken(claims *Claims, clientID string) (string, error) {
+	if claims.IssuedAt.IsZero() {
+		claims.IssuedAt = time.Now()
+	}
+	if claims.ExpiresAt.IsZero() {
+		return "", errors.New("token expiry is required")
+	}
+	if err := s.customize(claims); err != nil {
+		return "", err
+	}
+
+	// Round-trip through JSON so the stored claims look the same as claims
+	// parsed from a JWT or read back from the database.
+	encoded, err := json.Marshal(claims.mapClaims())
+	if err != nil {
+		return "", err
+	}
+	var stored model.JSONMap
+	if err := json.Unmarshal(encoded, &stored); err != nil {
+		return "", err
+	}
+
+	secret, err := credential.Generate(32)
+	if err != nil {
+		return "", err
+	}
+	token := ReferenceTokenPrefix + secret
+	if err := s.referenceTokenRepo.Create(&model.ReferenceToken{
+		TokenHash: credential.Hash(token),
+		Subject:   claims.Subject,
+		ClientID:  clientID,
+		Claims:    stored,
+		ExpiresAt: claims.ExpiresAt,
+	}); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// verifyReferenceToken looks the token up by its hash and checks its claims
+// the same way as a JWT's.
+func (s *service) verifyReferenceToken(token string) (*Claims, error) {
+	stored, err := s.referenceTokenRepo.GetByHash(credential.Hash(token))
+	if errors.Is(err, repository.ErrReferenceTokenNotFound) {
+		log.Println("Unknown reference token")
+		return nil, ErrInvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	if stored.Expired(time.Now()) {
+		log.Printf("Rejected expired reference token %s", stored.ID)
+		return nil, ErrInvalidToken
+	}
+
+	claims, err := claimsFromMap(jwt.MapClaims(stored.Claims))
+	if err != nil {
+		log.Printf("Invalid claims in reference token %s", stored.ID)
+		return nil, err
+	}
+	return s.checkClaims(claims)
+}
+
+// RevokeReferenceToken deletes a reference token issued to clientID. Unknown
+// and already expired tokens are not an error, since either way the token no
+// longer works.
+func (s *service) RevokeReferenceToken(token, clientID string) error {
+	if !isReferenceToken(token) {
+		return ErrInvalidToken
+	}
+	stored, err := s.referenceTokenRepo.GetByHash(credential.Hash(token))
+	if errors.Is(err, repository.ErrReferenceTokenNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if stored.ClientID != clientID {
+		log.Printf("Client %s tried to revoke reference token %s issued to %s", clientID, stored.ID, stored.ClientID)
+		return ErrTokenNotOwned
+	}
+	if err := s.referenceTokenRepo.Delete(stored.ID); err != nil && !errors.Is(err, repository.ErrReferenceTokenNotFound) {
+		return err
+	}
+	log.Printf("Revoked reference token %s", stored.ID)
+	return nil
+}
+
+// PurgeExpiredReferenceTokens deletes reference tokens that have expired,
+// returning how many there were.
+func (s *service) PurgeExpiredReferenceTokens() (int64, error) {
+	return s.referenceTokenRepo.DeleteExpired(time.Now())
+}
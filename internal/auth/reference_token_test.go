@@ -0,0 +1,135 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/radiatus-ai/auth-service/internal/model"
+	"github.com/radiatus-ai/auth-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+type stubReferenceTokenRepository struct {
+	repository.ReferenceTokenRepository
+	tokens map[string]*model.ReferenceToken
+}
+
+func (s *stubReferenceTokenRepository) Create(token *model.ReferenceToken) error {
+	token.ID = uuid.New()
+	s.tokens[token.TokenHash] = token
+	return nil
+}
+
+func (s *stubReferenceTokenRepository) GetByHash(hash string) (*model.ReferenceToken, error) {
+	token, ok := s.tokens[hash]
+	if !ok {
+		return nil, repository.ErrReferenceTokenNotFound
+	}
+	return token, nil
+}
+
+func (s *stubReferenceTokenRepository) Delete(id uuid.UUID) error {
+	for hash, token := range s.tokens {
+		if token.ID == id {
+			delete(s.tokens, hash)
+			return nil
+		}
+	}
+	return repository.ErrReferenceTokenNotFound
+}
+
+func (s *stubReferenceTokenRepository) DeleteExpired(before time.Time) (int64, error) {
+	var purged int64
+	for hash, token := range s.tokens {
+		if token.ExpiresAt.Before(before) {
+			delete(s.tokens, hash)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+func TestReferenceToken(t *testing.T) {
+	user := &model.User{ID: uuid.New(), Email: "ada@example.com", Status: model.UserStatusActive}
+	svc := newVerifyTestService(user)
+
+	token, err := svc.IssueReferenceToken(&Claims{
+		Subject:     user.ID.String(),
+		SubjectType: SubjectUser,
+		Email:       user.Email,
+		ExpiresAt:   time.Now().Add(time.Minute),
+		Audience:    "billing",
+		Scopes:      []string{"invoices:read"},
+		ClientID:    "gateway",
+		Actor:       &Actor{Subject: "gateway", ClientID: "gateway"},
+	}, "gateway")
+	assert.NoError(t, err)
+	assert.True(t, isReferenceToken(token))
+	assert.NotContains(t, token, user.Email)
+
+	claims, err := svc.VerifyClaims(token)
+	assert.NoError(t, err)
+	assert.Equal(t, user.ID.String(), claims.Subject)
+	assert.Equal(t, user.Email, claims.Email)
+	assert.Equal(t, "billing", claims.Audience)
+	assert.Equal(t, []string{"invoices:read"}, claims.Scopes)
+	if assert.NotNil(t, claims.Actor) {
+		assert.Equal(t, "gateway", claims.Actor.ClientID)
+	}
+
+	subject, err := svc.VerifyToken(token)
+	assert.NoError(t, err)
+	assert.Equal(t, user.ID.String(), subject)
+
+	assert.ErrorIs(t, svc.RevokeReferenceToken(token, "other"), ErrTokenNotOwned)
+	_, err = svc.VerifyClaims(token)
+	assert.NoError(t, err, "only the client the token was issued to can revoke it")
+
+	assert.NoError(t, svc.RevokeReferenceToken(token, "gateway"))
+	_, err = svc.VerifyClaims(token)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+	assert.NoError(t, svc.RevokeReferenceToken(token, "gateway"), "revoking twice is not an error")
+}
+
+func TestReferenceToken_ChecksSubject(t *testing.T) {
+	user := &model.User{ID: uuid.New(), Email: "ada@example.com", Status: model.UserStatusActive}
+	svc := newVerifyTestService(user)
+
+	token, err := svc.IssueReferenceToken(&Claims{
+		Subject:   user.ID.String(),
+		ExpiresAt: time.Now().Add(time.Minute),
+	}, "gateway")
+	assert.NoError(t, err)
+
+	user.Status = model.UserStatusSuspended
+	_, err = svc.VerifyClaims(token)
+	assert.ErrorIs(t, err, ErrUserSuspended)
+}
+
+func TestReferenceToken_Expiry(t *testing.T) {
+	user := &model.User{ID: uuid.New(), Email: "ada@example.com", Status: model.UserStatusActive}
+	svc := newVerifyTestService(user)
+
+	_, err := svc.IssueReferenceToken(&Claims{Subject: user.ID.String()}, "gateway")
+	assert.Error(t, err, "reference tokens must expire")
+
+	token, err := svc.IssueReferenceToken(&Claims{
+		Subject:   user.ID.String(),
+		IssuedAt:  time.Now().Add(-time.Hour),
+		ExpiresAt: time.Now().Add(-time.Minute),
+	}, "gateway")
+	assert.NoError(t, err)
+	_, err = svc.VerifyClaims(token)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	purged, err := svc.PurgeExpiredReferenceTokens()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+}
+
+func TestVerifyClaims_UnknownReferenceToken(t *testing.T) {
+	svc := newVerifyTestService()
+	_, err := svc.VerifyClaims(ReferenceTokenPrefix + "unknown")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
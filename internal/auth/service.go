@@ -11,53 +11,105 @@ import (
 	"github.com/golang-jwt/jwt"
 	"github.com/google/uuid"
 	"github.com/radiatus-ai/auth-service/internal/model"
+	"github.com/radiatus-ai/auth-service/internal/rbac"
 	"github.com/radiatus-ai/auth-service/internal/repository"
+	"github.com/radiatus-ai/auth-service/pkg/dpop"
+	"github.com/radiatus-ai/auth-service/pkg/jwks"
 	"google.golang.org/api/idtoken"
 )
 
 type Service interface {
-	LoginGoogle(token string) (*UserData, error)
+	LoginGoogle(token string, client ClientInfo) (*UserData, error)
+	EndSession(token string) error
 	VerifyToken(token string) (string, error)
+	VerifyClaims(token string) (*Claims, error)
+	VerifyRequest(token, clientIP string) (*Claims, error)
 	GetUserByID(userID string) (*model.User, error)
+	GetServiceAccountByID(accountID string) (*model.ServiceAccount, error)
+	IssueImpersonationToken(adminID, userID uuid.UUID) (string, time.Time, error)
+	IssueToken(claims *Claims) (string, error)
+	JWKS() jwks.Set
+	CSRFToken(sessionID string) string
+	VerifyCSRFToken(sessionID, csrfToken string) bool
+	RefreshSession(claims *Claims) (string, time.Time, error)
+	RefreshAccessToken(refreshToken, keyThumbprint string) (*SessionTokens, error)
+	Reauthenticate(claims *Claims, googleToken string) (string, time.Time, error)
+	VerifyDPoPProof(proof, method, url, accessToken string) (string, error)
+	IssueReferenceToken(claims *Claims, clientID string) (string, error)
+	RevokeReferenceToken(token, clientID string) error
+	PurgeExpiredReferenceTokens() (int64, error)
 }
 
+// impersonationTTL keeps impersonation tokens short-lived, since they let an
+// admin act as someone else.
+const impersonationTTL = 15 * time.Minute
+
 type service struct {
-	userRepo        repository.UserRepository
-	orgRepo         repository.OrganizationRepository
-	jwtSecret       string
-	googleClientIDs []string
-	emailWhitelist  []string
+	userRepo           repository.UserRepository
+	orgRepo            repository.OrganizationRepository
+	serviceAccountRepo repository.ServiceAccountRepository
+	patRepo            repository.PersonalAccessTokenRepository
+	apiKeyRepo         repository.APIKeyRepository
+	sessionRepo        repository.SessionRepository
+	referenceTokenRepo repository.ReferenceTokenRepository
+	sessionPolicy      SessionPolicy
+	jwtSecret          string
+	signingKey         *SigningKey
+	googleClientIDs    []string
+	emailWhitelist     []string
+	superadminEmails   []string
+	validateIDToken    func(ctx context.Context, token, audience string) (*idtoken.Payload, error)
+	dpop               *dpop.Verifier
+	claimsConfig       *ClaimsConfig
 }
 
-func NewService(userRepo repository.UserRepository, orgRepo repository.OrganizationRepository, jwtSecret string, googleClientIDs []string, emailWhitelist []string) Service {
+func NewService(userRepo repository.UserRepository, orgRepo repository.OrganizationRepository, serviceAccountRepo repository.ServiceAccountRepository, patRepo repository.PersonalAccessTokenRepository, apiKeyRepo repository.APIKeyRepository, sessionRepo repository.SessionRepository, referenceTokenRepo repository.ReferenceTokenRepository, sessionPolicy SessionPolicy, jwtSecret string, signingKey *SigningKey, googleClientIDs []string, emailWhitelist []string, superadminEmails []string, claimsConfig *ClaimsConfig) Service {
 	return &service{
-		userRepo:        userRepo,
-		orgRepo:         orgRepo,
-		jwtSecret:       jwtSecret,
-		googleClientIDs: googleClientIDs,
-		emailWhitelist:  emailWhitelist,
+		userRepo:           userRepo,
+		orgRepo:            orgRepo,
+		serviceAccountRepo: serviceAccountRepo,
+		patRepo:            patRepo,
+		apiKeyRepo:         apiKeyRepo,
+		sessionRepo:        sessionRepo,
+		referenceTokenRepo: referenceTokenRepo,
+		sessionPolicy:      sessionPolicy,
+		jwtSecret:          jwtSecret,
+		signingKey:         signingKey,
+		googleClientIDs:    googleClientIDs,
+		emailWhitelist:     emailWhitelist,
+		superadminEmails:   superadminEmails,
+		validateIDToken:    idtoken.Validate,
+		dpop:               dpop.NewVerifier(),
+		claimsConfig:       claimsConfig,
 	}
 }
 
 type UserData struct {
-	Token          string     `json:"token"`
+	Token string `json:"token,omitempty"`
+	// RefreshToken can be redeemed at /token for a new token before the
+	// session's refresh token lifetime is up.
+	RefreshToken   string     `json:"refresh_token,omitempty"`
 	User           model.User `json:"user"`
 	OrganizationID uuid.UUID  `json:"organization_id"`
+	// SessionID and ExpiresAt describe the session the token belongs to.
+	SessionID string    `json:"-"`
+	ExpiresAt time.Time `json:"-"`
 }
 
-func (s *service) LoginGoogle(token string) (*UserData, error) {
-	log.Println("Starting Google login process")
-
-	var payload *idtoken.Payload
-	var err error
+// ClientInfo describes the device a login comes from, as shown in the user's
+// list of sessions.
+type ClientInfo struct {
+	UserAgent string
+	IPAddress string
+	// KeyThumbprint is the DPoP key the login was made with, if any. The
+	// session's tokens are then bound to it.
+	KeyThumbprint string
+}
 
-	for _, clientID := range s.googleClientIDs {
-		payload, err = idtoken.Validate(context.Background(), token, clientID)
-		if err == nil {
-			break
-		}
-	}
+func (s *service) LoginGoogle(token string, client ClientInfo) (*UserData, error) {
+	log.Println("Starting Google login process")
 
+	payload, err := s.validateGoogleToken(token)
 	if err != nil {
 		log.Printf("Failed to validate Google token: %v", err)
 		return nil, err
@@ -87,15 +139,16 @@ func (s *service) LoginGoogle(token string) (*UserData, error) {
 			}
 
 			// Create new organization
+			// The repository derives a unique slug from the name.
 			org := &model.Organization{
-				Name: email, // todo: need to change this to a different naming convention
+				Name: defaultOrganizationName(email),
 			}
 			if err := s.orgRepo.Create(org); err != nil {
 				log.Printf("Failed to create organization: %v", err)
 				return nil, err
 			}
 
-			if err := s.orgRepo.AddUser(org.ID, user.ID); err != nil {
+			if err := s.orgRepo.AddMember(org.ID, user.ID, string(rbac.RoleOwner)); err != nil {
 				log.Printf("Failed to add user to organization: %v", err)
 				return nil, err
 			}
@@ -108,16 +161,34 @@ func (s *service) LoginGoogle(token string) (*UserData, error) {
 		}
 	} else {
 		log.Printf("Existing user found for email: %s", email)
-		// Get the user's organization
+		if err := statusError(user.Status); err != nil {
+			log.Printf("Rejected login for %s user: %s", user.Status, email)
+			return nil, err
+		}
+		// Get the user's organization. Users whose organizations have all
+		// been left or deleted can still sign in without one.
 		org, err := s.orgRepo.GetUserOrganization(user.ID)
-		if err != nil {
+		if err != nil && !errors.Is(err, repository.ErrOrganizationNotFound) {
 			log.Printf("Failed to get user organization: %v", err)
 			return nil, err
 		}
-		organizationID = org.ID
+		if org != nil {
+			organizationID = org.ID
+		}
+	}
+
+	if err := s.bootstrapSuperadmin(user); err != nil {
+		log.Printf("Failed to grant superadmin role: %v", err)
+		return nil, err
 	}
 
-	token, err = s.generateToken(user.ID)
+	acr, amr := googleAuthentication(payload)
+	claims, refreshToken, err := s.startSession(user, model.AuthMethodGoogle, acr, amr, client)
+	if err != nil {
+		log.Printf("Failed to create session: %v", err)
+		return nil, err
+	}
+	token, err = s.signClaims(claims)
 	if err != nil {
 		log.Printf("Failed to generate token: %v", err)
 		return nil, err
@@ -125,12 +196,44 @@ func (s *service) LoginGoogle(token string) (*UserData, error) {
 	log.Println("Successfully generated token")
 
 	return &UserData{
+		SessionID:      claims.SessionID,
+		ExpiresAt:      claims.ExpiresAt,
 		Token:          token,
+		RefreshToken:   refreshToken,
 		User:           *user,
 		OrganizationID: organizationID,
 	}, nil
 }
 
+// defaultOrganizationName names a new user's personal organization after the
+// local part of their email address.
+func defaultOrganizationName(email string) string {
+	if local, _, found := strings.Cut(email, "@"); found && local != "" {
+		return local
+	}
+	return email
+}
+
+// bootstrapSuperadmin grants the superadmin platform role to users whose email
+// is listed in the configuration, so the first admin can sign in without
+// anyone editing the database.
+func (s *service) bootstrapSuperadmin(user *model.User) error {
+	if user.IsSuperadmin() {
+		return nil
+	}
+	for _, email := range s.superadminEmails {
+		if strings.EqualFold(email, user.Email) {
+			log.Printf("Granting superadmin role to %s from configuration", user.Email)
+			if err := s.userRepo.SetPlatformRole(user.ID, model.PlatformRoleSuperadmin); err != nil {
+				return err
+			}
+			user.PlatformRole = model.PlatformRoleSuperadmin
+			return nil
+		}
+	}
+	return nil
+}
+
 func (s *service) isEmailAllowed(email string) bool {
 	log.Printf("Checking if email is allowed: %s", email)
 	for _, allowed := range s.emailWhitelist {
@@ -144,36 +247,151 @@ func (s *service) isEmailAllowed(email string) bool {
 }
 
 func (s *service) VerifyToken(tokenString string) (string, error) {
+	claims, err := s.VerifyClaims(tokenString)
+	if err != nil {
+		return "", err
+	}
+	return claims.Subject, nil
+}
+
+// VerifyRequest verifies a token presented by a client at clientIP. The
+// address only matters for API keys with an allowlist.
+func (s *service) VerifyRequest(tokenString, clientIP string) (*Claims, error) {
+	if isAPIKey(tokenString) {
+		return s.verifyAPIKey(tokenString, clientIP)
+	}
+	return s.VerifyClaims(tokenString)
+}
+
+// VerifyClaims checks the token's signature and expiry, then that the subject
+// (and, for impersonation tokens, the acting admin) may still use it.
+// Personal access tokens, API keys and reference tokens are recognized by
+// their prefix and looked up instead.
+func (s *service) VerifyClaims(tokenString string) (*Claims, error) {
+	if isPersonalAccessToken(tokenString) {
+		return s.verifyPersonalAccessToken(tokenString)
+	}
+	if isAPIKey(tokenString) {
+		return s.verifyAPIKey(tokenString, "")
+	}
+	if isReferenceToken(tokenString) {
+		return s.verifyReferenceToken(tokenString)
+	}
+
 	log.Printf("Received token for verification: %s", tokenString)
 
 	parts := strings.Split(tokenString, ".")
 	log.Printf("Token parts: %d", len(parts))
 
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			log.Printf("Unexpected signing method: %v", token.Header["alg"])
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(s.jwtSecret), nil
-	})
+	token, err := jwt.Parse(tokenString, s.verificationKey)
 
 	if err != nil {
 		log.Printf("Error parsing token: %v", err)
-		return "", err
+		return nil, err
+	}
+
+	mapClaims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		log.Println("Invalid token")
+		return nil, ErrInvalidToken
+	}
+
+	claims, err := claimsFromMap(mapClaims)
+	if err != nil {
+		log.Println("Invalid user ID in token")
+		return nil, err
+	}
+	return s.checkClaims(claims)
+}
+
+// checkClaims checks that the subject of verified claims may still use
+// them: that the service account or user is active and, for users, that the
+// session is live and any impersonator may still impersonate.
+func (s *service) checkClaims(claims *Claims) (*Claims, error) {
+	if claims.IsServiceAccount() {
+		if _, err := s.checkServiceAccountActive(claims.Subject); err != nil {
+			return nil, err
+		}
+		log.Printf("Token verified for service account ID: %s", claims.Subject)
+		return claims, nil
+	}
+	if _, err := s.checkUserActive(claims.Subject, claims.IssuedAt); err != nil {
+		return nil, err
 	}
+	if claims.SessionID != "" {
+		if err := s.checkSession(claims); err != nil {
+			return nil, err
+		}
+	}
+	if claims.IsImpersonated() {
+		if err := s.checkImpersonator(claims); err != nil {
+			return nil, err
+		}
+		log.Printf("Token verified for user ID %s impersonated by %s", claims.Subject, claims.Impersonator())
+		return claims, nil
+	}
+
+	log.Printf("Token verified for user ID: %s", claims.Subject)
+	return claims, nil
+}
+
+// checkUserActive rejects tokens for users who are not active and tokens
+// issued before the user's tokens were last revoked.
+func (s *service) checkUserActive(userID string, issuedAt time.Time) (*model.User, error) {
+	user, err := s.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := statusError(user.Status); err != nil {
+		log.Printf("Rejected token for %s user: %s", user.Status, userID)
+		return nil, err
+	}
+	// Tokens only record the second they were issued in, so one issued in
+	// the same second as a revocation may predate it and is rejected too.
+	if user.TokensValidAfter != nil && issuedAt.Unix() <= user.TokensValidAfter.Unix() {
+		log.Printf("Rejected revoked token for user: %s", userID)
+		return nil, ErrTokenRevoked
+	}
+	return user, nil
+}
 
-	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		userID, ok := claims["sub"].(string)
-		if !ok {
-			log.Println("Invalid user ID in token")
-			return "", errors.New("invalid user ID in token")
+// checkServiceAccountActive rejects tokens for service accounts that have
+// been disabled or deleted, or whose organization has been deleted.
+func (s *service) checkServiceAccountActive(accountID string) (*model.ServiceAccount, error) {
+	account, err := s.GetServiceAccountByID(accountID)
+	if errors.Is(err, repository.ErrServiceAccountNotFound) {
+		return nil, ErrTokenRevoked
+	}
+	if err != nil {
+		return nil, err
+	}
+	if account.Disabled {
+		log.Printf("Rejected token for disabled service account: %s", accountID)
+		return nil, ErrTokenRevoked
+	}
+	if _, err := s.orgRepo.GetByID(account.OrganizationID); err != nil {
+		if errors.Is(err, repository.ErrOrganizationNotFound) {
+			log.Printf("Rejected token for service account of deleted organization: %s", accountID)
+			return nil, ErrTokenRevoked
 		}
-		log.Printf("Token verified for user ID: %s", userID)
-		return userID, nil
+		return nil, err
 	}
+	return account, nil
+}
 
-	log.Println("Invalid token")
-	return "", errors.New("invalid token")
+// checkImpersonator makes an impersonation token stop working as soon as the
+// acting admin loses the superadmin role or is themselves blocked or logged out.
+func (s *service) checkImpersonator(claims *Claims) error {
+	adminID := claims.Impersonator()
+	admin, err := s.checkUserActive(adminID, claims.IssuedAt)
+	if err != nil {
+		return err
+	}
+	if !admin.IsSuperadmin() {
+		log.Printf("Rejected impersonation token from non-superadmin: %s", adminID)
+		return ErrTokenRevoked
+	}
+	return nil
 }
 
 func (s *service) GetUserByID(userID string) (*model.User, error) {
@@ -193,12 +411,90 @@ func (s *service) GetUserByID(userID string) (*model.User, error) {
 	return user, nil
 }
 
-func (s *service) generateToken(userID uuid.UUID) (string, error) {
-	log.Printf("Generating token for user ID: %s", userID)
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"sub": userID.String(),
-		"exp": time.Now().Add(time.Hour * 24).Unix(),
-	})
+func (s *service) GetServiceAccountByID(accountID string) (*model.ServiceAccount, error) {
+	id, err := uuid.Parse(accountID)
+	if err != nil {
+		return nil, errors.New("invalid service account ID")
+	}
+	return s.serviceAccountRepo.GetByID(id)
+}
+
+func (s *service) generateToken(user *model.User) (string, error) {
+	claims, _, err := s.startSession(user, model.AuthMethodGoogle, ACRSingleFactor, []string{AMRFederated}, ClientInfo{})
+	if err != nil {
+		return "", err
+	}
+	return s.signClaims(claims)
+}
 
+// IssueImpersonationToken mints a short-lived token for userID whose act claim
+// names the admin. Policy checks are the caller's responsibility.
+func (s *service) IssueImpersonationToken(adminID, userID uuid.UUID) (string, time.Time, error) {
+	log.Printf("Generating impersonation token for user ID %s requested by admin %s", userID, adminID)
+	now := time.Now()
+	claims := &Claims{
+		Subject:   userID.String(),
+		IssuedAt:  now,
+		ExpiresAt: now.Add(impersonationTTL),
+		Actor:     &Actor{Subject: adminID.String()},
+	}
+	token, err := s.signClaims(claims)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return token, claims.ExpiresAt, nil
+}
+
+// IssueToken signs arbitrary claims, for flows such as token exchange that
+// build their own. IssuedAt defaults to now.
+func (s *service) IssueToken(claims *Claims) (string, error) {
+	if claims.IssuedAt.IsZero() {
+		claims.IssuedAt = time.Now()
+	}
+	if claims.ExpiresAt.IsZero() {
+		return "", errors.New("token expiry is required")
+	}
+	return s.signClaims(claims)
+}
+
+// signClaims signs with the RSA signing key when one is configured, and
+// with the shared secret otherwise. Custom claims are added first.
+func (s *service) signClaims(claims *Claims) (string, error) {
+	if err := s.customize(claims); err != nil {
+		return "", err
+	}
+	if s.signingKey != nil {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims.mapClaims())
+		token.Header["kid"] = s.signingKey.ID
+		return token.SignedString(s.signingKey.key)
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims.mapClaims())
 	return token.SignedString([]byte(s.jwtSecret))
 }
+
+// verificationKey accepts tokens signed with the signing key and, so tokens
+// issued before a signing key was configured stay valid until they expire,
+// with the shared secret.
+func (s *service) verificationKey(token *jwt.Token) (interface{}, error) {
+	switch token.Method.(type) {
+	case *jwt.SigningMethodHMAC:
+		return []byte(s.jwtSecret), nil
+	case *jwt.SigningMethodRSA:
+		if s.signingKey != nil && token.Header["kid"] == s.signingKey.ID {
+			return &s.signingKey.key.PublicKey, nil
+		}
+		return nil, fmt.Errorf("unknown signing key %v", token.Header["kid"])
+	}
+	log.Printf("Unexpected signing method: %v", token.Header["alg"])
+	return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+}
+
+// JWKS is the set of public keys tokens may be verified with. It is empty
+// when tokens are signed with the shared secret.
+func (s *service) JWKS() jwks.Set {
+	set := jwks.Set{Keys: []jwks.JSONWebKey{}}
+	if s.signingKey != nil {
+		set.Keys = append(set.Keys, s.signingKey.PublicJWK())
+	}
+	return set
+}
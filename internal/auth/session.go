@@ -0,0 +1,215 @@
+package auth
+
+import (
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/radiatus-ai/auth-service/internal/credential"
+	"github.com/radiatus-ai/auth-service/internal/model"
+	"github.com/radiatus-ai/auth-service/internal/repository"
+)
+
+// RefreshTokenPrefix marks refresh tokens, which are opaque.
+const RefreshTokenPrefix = "r7rt_"
+
+// SessionTokens are the tokens issued for a session at login or when its
+// refresh token is redeemed.
+type SessionTokens struct {
+	AccessToken  string
+	RefreshToken string
+	// ExpiresAt is when AccessToken expires.
+	ExpiresAt time.Time
+	// KeyThumbprint is the DPoP key the tokens are bound to, if any.
+	KeyThumbprint string
+}
+
+// startSession records a new login session for user, who authenticated at
+// the acr level with the amr methods, and returns the claims of its first
+// token and its refresh token.
+func (s *service) startSession(user *model.User, authMethod, acr string, amr []string, client ClientInfo) (*Claims, string, error) {
+	log.Printf("Generating token for user ID: %s", user.ID)
+	policy, err := s.userSessionPolicy(user.ID)
+	if err != nil {
+		return nil, "", err
+	}
+	now := time.Now()
+	session := &model.Session{
+		UserID:        user.ID,
+		AuthMethod:    authMethod,
+		UserAgent:     client.UserAgent,
+		IPAddress:     client.IPAddress,
+		AuthTime:      now,
+		ACR:           acr,
+		AMR:           model.StringList(amr),
+		CreatedAt:     now,
+		LastSeenAt:    now,
+		KeyThumbprint: client.KeyThumbprint,
+	}
+	refreshToken, err := renewSession(session, policy, now)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := s.sessionRepo.Create(session); err != nil {
+		return nil, "", err
+	}
+	return sessionClaims(user, session, now, policy), refreshToken, nil
+}
+
+// renewSession applies policy to a session at now, as it issues a new access
+// token, and gives it a new refresh token. The session's absolute lifetime
+// always counts from when it started, so a stricter policy takes effect on
+// existing sessions the next time they are renewed.
+func renewSession(session *model.Session, policy SessionPolicy, now time.Time) (string, error) {
+	session.IdleTimeoutSeconds = int(policy.IdleTimeout / time.Second)
+	session.AbsoluteExpiresAt = nil
+	if policy.AbsoluteLifetime > 0 {
+		absoluteExpiresAt := session.CreatedAt.Add(policy.AbsoluteLifetime)
+		session.AbsoluteExpiresAt = &absoluteExpiresAt
+	}
+
+	secret, err := credential.Generate(32)
+	if err != nil {
+		return "", err
+	}
+	refreshToken := RefreshTokenPrefix + secret
+	refreshTokenHash := credential.Hash(refreshToken)
+	refreshExpiresAt := session.Cap(now.Add(policy.RefreshTokenTTL))
+	session.RefreshTokenHash = &refreshTokenHash
+	session.RefreshExpiresAt = &refreshExpiresAt
+
+	session.ExpiresAt = session.Cap(now.Add(policy.TokenTTL))
+	if refreshExpiresAt.After(session.ExpiresAt) {
+		session.ExpiresAt = refreshExpiresAt
+	}
+	return refreshToken, nil
+}
+
+// checkSession rejects tokens whose session has been ended or has timed out,
+// and records that the session is in use.
+func (s *service) checkSession(claims *Claims) error {
+	id, err := uuid.Parse(claims.SessionID)
+	if err != nil {
+		return ErrInvalidToken
+	}
+	session, err := s.sessionRepo.GetByID(id)
+	if errors.Is(err, repository.ErrSessionNotFound) {
+		log.Printf("Rejected token for ended session %s of user: %s", id, claims.Subject)
+		return ErrTokenRevoked
+	}
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	if session.UserID.String() != claims.Subject {
+		return ErrTokenRevoked
+	}
+	if session.Expired(now) {
+		log.Printf("Rejected token for expired session %s of user: %s", id, claims.Subject)
+		return ErrTokenRevoked
+	}
+
+	if now.Sub(session.LastSeenAt) >= lastUsedResolution {
+		if err := s.sessionRepo.Touch(session.ID, now); err != nil {
+			log.Printf("Failed to record use of session %s: %v", session.ID, err)
+		}
+	}
+	return nil
+}
+
+// RefreshAccessToken redeems a session's refresh token for a new access token
+// and a new refresh token; the redeemed one stops working. The session policy
+// is looked up again, so changes to it apply from the next refresh. The
+// refresh token of a session bound to a DPoP key is only accepted with a
+// proof signed by that key, whose thumbprint is keyThumbprint.
+func (s *service) RefreshAccessToken(refreshToken, keyThumbprint string) (*SessionTokens, error) {
+	if !isRefreshToken(refreshToken) {
+		return nil, ErrInvalidToken
+	}
+	refreshTokenHash := credential.Hash(refreshToken)
+	session, err := s.sessionRepo.GetByRefreshTokenHash(refreshTokenHash)
+	if errors.Is(err, repository.ErrSessionNotFound) {
+		return nil, ErrInvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if session.RefreshExpiresAt == nil || !now.Before(*session.RefreshExpiresAt) || session.Expired(now) {
+		log.Printf("Rejected expired refresh token of session %s", session.ID)
+		return nil, ErrTokenRevoked
+	}
+	if session.KeyThumbprint != "" && session.KeyThumbprint != keyThumbprint {
+		log.Printf("Rejected refresh token of session %s without a proof of its DPoP key", session.ID)
+		return nil, ErrInvalidDPoPProof
+	}
+	user, err := s.checkUserActive(session.UserID.String(), now)
+	if err != nil {
+		return nil, err
+	}
+
+	policy, err := s.userSessionPolicy(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	newRefreshToken, err := renewSession(session, policy, now)
+	if err != nil {
+		return nil, err
+	}
+	session.LastSeenAt = now
+	err = s.sessionRepo.Rotate(session, refreshTokenHash)
+	if errors.Is(err, repository.ErrSessionNotFound) {
+		// Another request redeemed the same refresh token first. Only one of
+		// them can be its rightful holder, so neither keeps the session.
+		log.Printf("Refresh token of session %s was redeemed twice, ending the session", session.ID)
+		if err := s.sessionRepo.Delete(session.UserID, session.ID); err != nil && !errors.Is(err, repository.ErrSessionNotFound) {
+			return nil, err
+		}
+		return nil, ErrTokenRevoked
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	claims := sessionClaims(user, session, now, policy)
+	token, err := s.signClaims(claims)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Refreshed session %s for user ID: %s", session.ID, user.ID)
+	return &SessionTokens{
+		AccessToken:   token,
+		RefreshToken:  newRefreshToken,
+		ExpiresAt:     claims.ExpiresAt,
+		KeyThumbprint: session.KeyThumbprint,
+	}, nil
+}
+
+// EndSession signs out the session token belongs to. Tokens outside of a
+// session, and tokens that are no longer valid, are ignored.
+func (s *service) EndSession(token string) error {
+	claims, err := s.VerifyClaims(token)
+	if err != nil || claims.SessionID == "" {
+		return nil
+	}
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return nil
+	}
+	sessionID, err := uuid.Parse(claims.SessionID)
+	if err != nil {
+		return nil
+	}
+	err = s.sessionRepo.Delete(userID, sessionID)
+	if err != nil && !errors.Is(err, repository.ErrSessionNotFound) {
+		return err
+	}
+	log.Printf("Ended session %s for user ID: %s", sessionID, userID)
+	return nil
+}
+
+func isRefreshToken(token string) bool {
+	return strings.HasPrefix(token, RefreshTokenPrefix)
+}
@@ -0,0 +1,144 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// CSRFHeader carries the CSRF token on unsafe requests authenticated with a
+// session cookie.
+const CSRFHeader = "X-CSRF-Token"
+
+// SessionCookieConfig configures cookie sessions, which keep the token out of
+// reach of page scripts.
+type SessionCookieConfig struct {
+	// Name is the HttpOnly cookie holding the token. The CSRF token is kept
+	// in a cookie of the same name with a "_csrf" suffix, readable by the
+	// frontend so it can echo it in CSRFHeader.
+	Name   string
+	Domain string
+	// Insecure drops the Secure attribute, for local development over http.
+	Insecure bool
+	SameSite http.SameSite
+}
+
+// SessionCookies reads and writes session cookies.
+type SessionCookies struct {
+	cfg SessionCookieConfig
+}
+
+func NewSessionCookies(cfg SessionCookieConfig) *SessionCookies {
+	if cfg.SameSite == 0 {
+		cfg.SameSite = http.SameSiteLaxMode
+	}
+	return &SessionCookies{cfg: cfg}
+}
+
+// Set stores the session token and its CSRF token until expiresAt.
+func (s *SessionCookies) Set(c *gin.Context, token, csrfToken string, expiresAt time.Time) {
+	maxAge := int(time.Until(expiresAt).Seconds())
+	s.write(c, s.cfg.Name, token, maxAge, true)
+	s.write(c, s.csrfName(), csrfToken, maxAge, false)
+}
+
+func (s *SessionCookies) Clear(c *gin.Context) {
+	s.write(c, s.cfg.Name, "", -1, true)
+	s.write(c, s.csrfName(), "", -1, false)
+}
+
+// Token returns the session token sent with the request, if any.
+func (s *SessionCookies) Token(c *gin.Context) string {
+	token, err := c.Cookie(s.cfg.Name)
+	if err != nil {
+		return ""
+	}
+	return token
+}
+
+func (s *SessionCookies) Name() string {
+	return s.cfg.Name
+}
+
+func (s *SessionCookies) csrfName() string {
+	return s.cfg.Name + "_csrf"
+}
+
+func (s *SessionCookies) write(c *gin.Context, name, value string, maxAge int, httpOnly bool) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/",
+		Domain:   s.cfg.Domain,
+		MaxAge:   maxAge,
+		Secure:   !s.cfg.Insecure,
+		HttpOnly: httpOnly,
+		SameSite: s.cfg.SameSite,
+	})
+}
+
+// CSRFToken derives the CSRF token of a session. It is bound to the session
+// ID rather than the token, so it survives the token being refreshed while
+// requests are in flight.
+func (s *service) CSRFToken(sessionID string) string {
+	mac := hmac.New(sha256.New, []byte(s.jwtSecret))
+	mac.Write([]byte("csrf:" + sessionID))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyCSRFToken reports whether csrfToken belongs to the session.
+func (s *service) VerifyCSRFToken(sessionID, csrfToken string) bool {
+	if sessionID == "" || csrfToken == "" {
+		return false
+	}
+	return hmac.Equal([]byte(s.CSRFToken(sessionID)), []byte(csrfToken))
+}
+
+// RefreshSession renews the session of a cookie session token and reissues
+// the token, once it is past half of its lifetime. It returns "" if the token
+// is still fresh or cannot outlive the session.
+func (s *service) RefreshSession(claims *Claims) (string, time.Time, error) {
+	now := time.Now()
+	if claims.SessionID == "" || now.Sub(claims.IssuedAt) < claims.ExpiresAt.Sub(claims.IssuedAt)/2 {
+		return "", time.Time{}, nil
+	}
+	sessionID, err := uuid.Parse(claims.SessionID)
+	if err != nil {
+		return "", time.Time{}, ErrInvalidToken
+	}
+	session, err := s.sessionRepo.GetByID(sessionID)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	policy, err := s.userSessionPolicy(session.UserID)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	// Cookie sessions are renewed by reissuing the cookie; the refresh token
+	// is never handed out.
+	if _, err := renewSession(session, policy, now); err != nil {
+		return "", time.Time{}, err
+	}
+	expiresAt := session.Cap(now.Add(policy.TokenTTL))
+	if !expiresAt.After(claims.ExpiresAt) {
+		return "", time.Time{}, nil
+	}
+	session.LastSeenAt = now
+	if err := s.sessionRepo.Update(session); err != nil {
+		return "", time.Time{}, err
+	}
+
+	refreshed := *claims
+	refreshed.IssuedAt = now
+	refreshed.ExpiresAt = expiresAt
+	token, err := s.signClaims(&refreshed)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return token, refreshed.ExpiresAt, nil
+}
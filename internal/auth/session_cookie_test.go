@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/radiatus-ai/auth-service/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCSRFToken(t *testing.T) {
+	svc := newVerifyTestService()
+
+	token := svc.CSRFToken("sid_1")
+	assert.NotEmpty(t, token)
+	assert.Equal(t, token, svc.CSRFToken("sid_1"))
+	assert.True(t, svc.VerifyCSRFToken("sid_1", token))
+	assert.False(t, svc.VerifyCSRFToken("sid_2", token))
+	assert.False(t, svc.VerifyCSRFToken("sid_1", ""))
+	assert.False(t, svc.VerifyCSRFToken("", svc.CSRFToken("")))
+}
+
+func TestRefreshSession(t *testing.T) {
+	user := &model.User{ID: uuid.New(), Email: "ada@example.com", Status: model.UserStatusActive}
+	svc := newVerifyTestService(user)
+	token, err := svc.generateToken(user)
+	assert.NoError(t, err)
+	claims, err := svc.VerifyClaims(token)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, claims.SessionID)
+
+	refreshed, _, err := svc.RefreshSession(claims)
+	assert.NoError(t, err)
+	assert.Empty(t, refreshed, "fresh sessions are not refreshed")
+
+	claims.IssuedAt = time.Now().Add(-testSessionPolicy.TokenTTL / 2)
+	claims.ExpiresAt = claims.IssuedAt.Add(testSessionPolicy.TokenTTL)
+	refreshed, expiresAt, err := svc.RefreshSession(claims)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, refreshed)
+	assert.WithinDuration(t, time.Now().Add(testSessionPolicy.TokenTTL), expiresAt, time.Minute)
+
+	refreshedClaims, err := svc.VerifyClaims(refreshed)
+	assert.NoError(t, err)
+	assert.Equal(t, claims.SessionID, refreshedClaims.SessionID)
+	assert.Equal(t, user.Email, refreshedClaims.Email)
+
+	refreshed, _, err = svc.RefreshSession(&Claims{Subject: user.ID.String(), IssuedAt: claims.IssuedAt})
+	assert.NoError(t, err)
+	assert.Empty(t, refreshed, "tokens outside a session are not refreshed")
+}
+
+func TestSessionCookies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	sessions := NewSessionCookies(SessionCookieConfig{Name: "r7_token", Domain: "example.com"})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/login/google", nil)
+	sessions.Set(c, "token", "csrf", time.Now().Add(time.Hour))
+
+	cookies := w.Result().Cookies()
+	assert.Len(t, cookies, 2)
+	session, csrf := cookies[0], cookies[1]
+	assert.Equal(t, "r7_token", session.Name)
+	assert.Equal(t, "token", session.Value)
+	assert.True(t, session.HttpOnly)
+	assert.True(t, session.Secure)
+	assert.Equal(t, http.SameSiteLaxMode, session.SameSite)
+	assert.Equal(t, "example.com", session.Domain)
+	assert.Equal(t, "r7_token_csrf", csrf.Name)
+	assert.Equal(t, "csrf", csrf.Value)
+	assert.False(t, csrf.HttpOnly)
+
+	c.Request.AddCookie(session)
+	assert.Equal(t, "token", sessions.Token(c))
+}
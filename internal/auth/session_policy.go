@@ -0,0 +1,180 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/radiatus-ai/auth-service/internal/model"
+)
+
+// SessionPolicySetting is the organization settings key overriding the global
+// session policy. It holds an object with any of the policy's JSON fields;
+// fields left out inherit the global policy.
+const SessionPolicySetting = "session_policy"
+
+const (
+	minSessionPolicyDuration = time.Minute
+	maxSessionPolicyDuration = 90 * 24 * time.Hour
+)
+
+// SessionPolicy bounds how long users stay signed in. A zero IdleTimeout or
+// AbsoluteLifetime means no limit.
+type SessionPolicy struct {
+	// TokenTTL is the lifetime of each access token.
+	TokenTTL time.Duration
+	// RefreshTokenTTL is how long a refresh token can be redeemed for a new
+	// access token. Each redemption issues a new refresh token.
+	RefreshTokenTTL time.Duration
+	// IdleTimeout ends a session that has not been used for that long.
+	IdleTimeout time.Duration
+	// AbsoluteLifetime ends a session that long after sign-in, however
+	// active it is.
+	AbsoluteLifetime time.Duration
+}
+
+// sessionPolicyJSON is the wire form of SessionPolicy, in whole seconds.
+type sessionPolicyJSON struct {
+	TokenTTL         int64 `json:"token_ttl_seconds"`
+	RefreshTokenTTL  int64 `json:"refresh_token_ttl_seconds"`
+	IdleTimeout      int64 `json:"idle_timeout_seconds"`
+	AbsoluteLifetime int64 `json:"absolute_lifetime_seconds"`
+}
+
+func (p SessionPolicy) MarshalJSON() ([]byte, error) {
+	return json.Marshal(sessionPolicyJSON{
+		TokenTTL:         int64(p.TokenTTL / time.Second),
+		RefreshTokenTTL:  int64(p.RefreshTokenTTL / time.Second),
+		IdleTimeout:      int64(p.IdleTimeout / time.Second),
+		AbsoluteLifetime: int64(p.AbsoluteLifetime / time.Second),
+	})
+}
+
+// WithSettings returns the policy as overridden by an organization's
+// settings. It fails if the override is malformed.
+func (p SessionPolicy) WithSettings(settings model.Settings) (SessionPolicy, error) {
+	value, ok := settings[SessionPolicySetting]
+	if !ok {
+		return p, nil
+	}
+	override, ok := value.(map[string]interface{})
+	if !ok {
+		return p, fmt.Errorf("must be an object")
+	}
+	fields := p.fields()
+	for key, value := range override {
+		field, ok := fields[key]
+		if !ok {
+			return p, fmt.Errorf("has unknown key %q", key)
+		}
+		seconds, ok := value.(float64)
+		if !ok || seconds != float64(int64(seconds)) {
+			return p, fmt.Errorf("%s must be a whole number of seconds", key)
+		}
+		*field = time.Duration(seconds) * time.Second
+		if err := validateSessionPolicyField(key, *field); err != nil {
+			return p, err
+		}
+	}
+	return p, nil
+}
+
+// ValidateSessionPolicySetting checks the value of an organization's
+// SessionPolicySetting.
+func ValidateSessionPolicySetting(value interface{}) error {
+	_, err := SessionPolicy{}.WithSettings(model.Settings{SessionPolicySetting: value})
+	return err
+}
+
+// Validate checks every duration is within bounds.
+func (p SessionPolicy) Validate() error {
+	for key, field := range p.fields() {
+		if err := validateSessionPolicyField(key, *field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *SessionPolicy) fields() map[string]*time.Duration {
+	return map[string]*time.Duration{
+		"token_ttl_seconds":         &p.TokenTTL,
+		"refresh_token_ttl_seconds": &p.RefreshTokenTTL,
+		"idle_timeout_seconds":      &p.IdleTimeout,
+		"absolute_lifetime_seconds": &p.AbsoluteLifetime,
+	}
+}
+
+// validateSessionPolicyField checks a duration is within bounds. Only the
+// idle timeout and the absolute lifetime may be zero.
+func validateSessionPolicyField(key string, d time.Duration) error {
+	optional := key == "idle_timeout_seconds" || key == "absolute_lifetime_seconds"
+	if optional && d == 0 {
+		return nil
+	}
+	if d < minSessionPolicyDuration || d > maxSessionPolicyDuration {
+		return fmt.Errorf("%s must be between %d and %d", key,
+			int64(minSessionPolicyDuration/time.Second), int64(maxSessionPolicyDuration/time.Second))
+	}
+	return nil
+}
+
+// Stricter returns the shortest of each of the two policies' limits.
+func (p SessionPolicy) Stricter(other SessionPolicy) SessionPolicy {
+	return SessionPolicy{
+		TokenTTL:         minDuration(p.TokenTTL, other.TokenTTL),
+		RefreshTokenTTL:  minDuration(p.RefreshTokenTTL, other.RefreshTokenTTL),
+		IdleTimeout:      minLimit(p.IdleTimeout, other.IdleTimeout),
+		AbsoluteLifetime: minLimit(p.AbsoluteLifetime, other.AbsoluteLifetime),
+	}
+}
+
+func minDuration(a, b time.Duration) time.Duration {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// minLimit is minDuration where zero means no limit.
+func minLimit(a, b time.Duration) time.Duration {
+	if a == 0 {
+		return b
+	}
+	if b == 0 {
+		return a
+	}
+	return minDuration(a, b)
+}
+
+// userSessionPolicy is the policy for a user's sessions: the strictest of the
+// policies of the organizations they belong to, so that no organization's
+// members escape its policy through another one. Users without an
+// organization get the global policy.
+func (s *service) userSessionPolicy(userID uuid.UUID) (SessionPolicy, error) {
+	orgs, err := s.orgRepo.GetUserOrganizations(userID)
+	if err != nil {
+		return SessionPolicy{}, err
+	}
+	if len(orgs) == 0 {
+		return s.sessionPolicy, nil
+	}
+	var policy SessionPolicy
+	for i, org := range orgs {
+		orgPolicy, err := s.sessionPolicy.WithSettings(org.Settings)
+		if err != nil {
+			// Settings are validated when they are updated, so this can
+			// only be data predating the setting.
+			log.Printf("Ignoring invalid session policy of organization %s: %v", org.ID, err)
+			orgPolicy = s.sessionPolicy
+		}
+		if i == 0 {
+			policy = orgPolicy
+		} else {
+			policy = policy.Stricter(orgPolicy)
+		}
+	}
+	return policy, nil
+}
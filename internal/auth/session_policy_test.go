@@ -0,0 +1,174 @@
+package auth
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/radiatus-ai/auth-service/internal/credential"
+	"github.com/radiatus-ai/auth-service/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessionPolicy_WithSettings(t *testing.T) {
+	policy, err := testSessionPolicy.WithSettings(model.Settings{
+		SessionPolicySetting: map[string]interface{}{
+			"token_ttl_seconds":    float64(900),
+			"idle_timeout_seconds": float64(1800),
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 15*time.Minute, policy.TokenTTL)
+	assert.Equal(t, testSessionPolicy.RefreshTokenTTL, policy.RefreshTokenTTL)
+	assert.Equal(t, 30*time.Minute, policy.IdleTimeout)
+	assert.Zero(t, policy.AbsoluteLifetime)
+
+	policy, err = testSessionPolicy.WithSettings(model.Settings{"locale": "en"})
+	assert.NoError(t, err)
+	assert.Equal(t, testSessionPolicy, policy)
+
+	for name, value := range map[string]interface{}{
+		"not an object":  "1h",
+		"unknown key":    map[string]interface{}{"ttl": float64(60)},
+		"not a number":   map[string]interface{}{"token_ttl_seconds": "60"},
+		"fractional":     map[string]interface{}{"token_ttl_seconds": 60.5},
+		"too short":      map[string]interface{}{"token_ttl_seconds": float64(10)},
+		"too long":       map[string]interface{}{"refresh_token_ttl_seconds": float64(365 * 24 * 3600)},
+		"zero token ttl": map[string]interface{}{"token_ttl_seconds": float64(0)},
+	} {
+		assert.Error(t, ValidateSessionPolicySetting(value), name)
+	}
+	assert.NoError(t, ValidateSessionPolicySetting(map[string]interface{}{"absolute_lifetime_seconds": float64(0)}))
+}
+
+func TestSessionPolicy_MarshalJSON(t *testing.T) {
+	b, err := json.Marshal(SessionPolicy{TokenTTL: time.Hour, RefreshTokenTTL: 24 * time.Hour, IdleTimeout: time.Minute})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"token_ttl_seconds":3600,"refresh_token_ttl_seconds":86400,"idle_timeout_seconds":60,"absolute_lifetime_seconds":0}`, string(b))
+}
+
+func TestUserSessionPolicy_StrictestOrganization(t *testing.T) {
+	lax := &model.Organization{ID: uuid.New(), Settings: model.Settings{
+		SessionPolicySetting: map[string]interface{}{"token_ttl_seconds": float64(7 * 24 * 3600)},
+	}}
+	strict := &model.Organization{ID: uuid.New(), Settings: model.Settings{
+		SessionPolicySetting: map[string]interface{}{"idle_timeout_seconds": float64(600), "absolute_lifetime_seconds": float64(3600)},
+	}}
+	svc := newVerifyTestService()
+	orgRepo := svc.orgRepo.(*stubOrganizationRepository)
+
+	policy, err := svc.userSessionPolicy(uuid.New())
+	assert.NoError(t, err)
+	assert.Equal(t, testSessionPolicy, policy, "users without organizations get the global policy")
+
+	orgRepo.orgs[lax.ID] = lax
+	policy, err = svc.userSessionPolicy(uuid.New())
+	assert.NoError(t, err)
+	assert.Equal(t, 7*24*time.Hour, policy.TokenTTL, "organizations can lengthen the global policy")
+
+	orgRepo.orgs[strict.ID] = strict
+	policy, err = svc.userSessionPolicy(uuid.New())
+	assert.NoError(t, err)
+	assert.Equal(t, SessionPolicy{
+		TokenTTL:         testSessionPolicy.TokenTTL,
+		RefreshTokenTTL:  testSessionPolicy.RefreshTokenTTL,
+		IdleTimeout:      10 * time.Minute,
+		AbsoluteLifetime: time.Hour,
+	}, policy)
+}
+
+func TestVerifyClaims_SessionTimeouts(t *testing.T) {
+	user := &model.User{ID: uuid.New(), Status: model.UserStatusActive}
+	svc := newVerifyTestService(user)
+	svc.sessionPolicy = SessionPolicy{
+		TokenTTL:         24 * time.Hour,
+		RefreshTokenTTL:  24 * time.Hour,
+		IdleTimeout:      time.Hour,
+		AbsoluteLifetime: 8 * time.Hour,
+	}
+	sessions := svc.sessionRepo.(*stubSessionRepository)
+
+	token, err := svc.generateToken(user)
+	assert.NoError(t, err)
+	claims, err := svc.VerifyClaims(token)
+	assert.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(8*time.Hour), claims.ExpiresAt, time.Minute,
+		"tokens do not outlive the absolute lifetime")
+
+	session := sessions.sessions[uuid.MustParse(claims.SessionID)]
+	session.LastSeenAt = time.Now().Add(-2 * time.Hour)
+	_, err = svc.VerifyClaims(token)
+	assert.ErrorIs(t, err, ErrTokenRevoked, "idle sessions end")
+
+	session.LastSeenAt = time.Now()
+	session.ExpiresAt = time.Now().Add(-time.Second)
+	_, err = svc.VerifyClaims(token)
+	assert.ErrorIs(t, err, ErrTokenRevoked, "expired sessions end")
+}
+
+func TestRefreshAccessToken(t *testing.T) {
+	user := &model.User{ID: uuid.New(), Email: "ada@example.com", Status: model.UserStatusActive}
+	svc := newVerifyTestService(user)
+	svc.sessionPolicy = SessionPolicy{TokenTTL: 15 * time.Minute, RefreshTokenTTL: 24 * time.Hour}
+	claims, refreshToken, err := svc.startSession(user, model.AuthMethodGoogle, ACRSingleFactor, []string{AMRFederated}, ClientInfo{})
+	assert.NoError(t, err)
+	assert.True(t, isRefreshToken(refreshToken))
+
+	tokens, err := svc.RefreshAccessToken(refreshToken, "")
+	assert.NoError(t, err)
+	assert.NotEqual(t, refreshToken, tokens.RefreshToken)
+	assert.WithinDuration(t, time.Now().Add(15*time.Minute), tokens.ExpiresAt, time.Minute)
+	refreshed, err := svc.VerifyClaims(tokens.AccessToken)
+	assert.NoError(t, err)
+	assert.Equal(t, claims.SessionID, refreshed.SessionID)
+	assert.Equal(t, user.Email, refreshed.Email)
+
+	_, err = svc.RefreshAccessToken(refreshToken, "")
+	assert.ErrorIs(t, err, ErrInvalidToken, "refresh tokens can only be redeemed once")
+
+	session := svc.sessionRepo.(*stubSessionRepository).sessions[uuid.MustParse(claims.SessionID)]
+	expired := time.Now().Add(-time.Second)
+	session.RefreshExpiresAt = &expired
+	_, err = svc.RefreshAccessToken(tokens.RefreshToken, "")
+	assert.ErrorIs(t, err, ErrTokenRevoked)
+
+	_, err = svc.RefreshAccessToken("r7rt_"+credential.Hash("unknown"), "")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+// racingSessionRepository lets another request redeem a refresh token
+// between it being looked up and the session being rotated.
+type racingSessionRepository struct {
+	*stubSessionRepository
+	race func()
+}
+
+func (r *racingSessionRepository) GetByRefreshTokenHash(hash string) (*model.Session, error) {
+	session, err := r.stubSessionRepository.GetByRefreshTokenHash(hash)
+	if race := r.race; race != nil {
+		r.race = nil
+		race()
+	}
+	return session, err
+}
+
+func TestRefreshAccessToken_ConcurrentRedemptionEndsSession(t *testing.T) {
+	user := &model.User{ID: uuid.New(), Email: "ada@example.com", Status: model.UserStatusActive}
+	svc := newVerifyTestService(user)
+	sessions := svc.sessionRepo.(*stubSessionRepository)
+	claims, refreshToken, err := svc.startSession(user, model.AuthMethodGoogle, ACRSingleFactor, []string{AMRFederated}, ClientInfo{})
+	assert.NoError(t, err)
+
+	var winner *SessionTokens
+	svc.sessionRepo = &racingSessionRepository{stubSessionRepository: sessions, race: func() {
+		winner, err = svc.RefreshAccessToken(refreshToken, "")
+		assert.NoError(t, err)
+	}}
+
+	_, err = svc.RefreshAccessToken(refreshToken, "")
+	assert.ErrorIs(t, err, ErrTokenRevoked)
+	assert.NotContains(t, sessions.sessions, uuid.MustParse(claims.SessionID))
+	_, err = svc.RefreshAccessToken(winner.RefreshToken, "")
+	assert.Error(t, err, "neither request keeps the session")
+}
@@ -0,0 +1,87 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/radiatus-ai/auth-service/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyClaims_Session(t *testing.T) {
+	user := &model.User{ID: uuid.New(), Status: model.UserStatusActive}
+	svc := newVerifyTestService(user)
+	sessions := svc.sessionRepo.(*stubSessionRepository)
+	token, err := svc.generateToken(user)
+	assert.NoError(t, err)
+	claims, err := svc.VerifyClaims(token)
+	assert.NoError(t, err)
+	sessionID := uuid.MustParse(claims.SessionID)
+	assert.Equal(t, user.ID, sessions.sessions[sessionID].UserID)
+	assert.Equal(t, 0, sessions.touched, "fresh sessions are not touched")
+
+	sessions.sessions[sessionID].LastSeenAt = time.Now().Add(-time.Hour)
+	_, err = svc.VerifyClaims(token)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, sessions.touched)
+
+	assert.NoError(t, svc.EndSession(token))
+	_, err = svc.VerifyClaims(token)
+	assert.ErrorIs(t, err, ErrTokenRevoked)
+	assert.NoError(t, svc.EndSession(token), "ending an ended session is a no-op")
+}
+
+func TestVerifyClaims_SessionOfAnotherUser(t *testing.T) {
+	user := &model.User{ID: uuid.New(), Status: model.UserStatusActive}
+	other := &model.User{ID: uuid.New(), Status: model.UserStatusActive}
+	svc := newVerifyTestService(user, other)
+	token, err := svc.generateToken(other)
+	assert.NoError(t, err)
+	claims, err := svc.VerifyClaims(token)
+	assert.NoError(t, err)
+
+	forged, err := svc.IssueToken(&Claims{
+		Subject:   user.ID.String(),
+		SessionID: claims.SessionID,
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+	assert.NoError(t, err)
+	_, err = svc.VerifyClaims(forged)
+	assert.ErrorIs(t, err, ErrTokenRevoked)
+}
+
+func TestLogout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	user := &model.User{ID: uuid.New(), Status: model.UserStatusActive}
+	svc := newVerifyTestService(user)
+	router := gin.New()
+	router.POST("/logout", NewHandler(svc, nil).Logout)
+	logout := func(header string) int {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/logout", nil)
+		req.Header.Set("Authorization", header)
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	for _, scheme := range []string{"bearer", "DPoP"} {
+		token, err := svc.generateToken(user)
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusNoContent, logout(scheme+" "+token), scheme)
+		_, err = svc.VerifyClaims(token)
+		assert.ErrorIs(t, err, ErrTokenRevoked, scheme)
+	}
+
+	token, err := svc.generateToken(user)
+	assert.NoError(t, err)
+	for _, header := range []string{"Token " + token, "Bearer", "Bearer " + token + " extra"} {
+		assert.Equal(t, http.StatusBadRequest, logout(header), header)
+	}
+	_, err = svc.VerifyClaims(token)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,71 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+
+	"github.com/radiatus-ai/auth-service/pkg/jwks"
+)
+
+var ErrInvalidSigningKey = errors.New("signing key must be a PEM encoded RSA private key")
+
+// SigningKey is the RSA key tokens are signed with (RS256) when one is
+// configured, which lets downstream services verify tokens themselves
+// against the published JWKS.
+type SigningKey struct {
+	// ID is the RFC 7638 thumbprint of the public key, used as the kid, so a
+	// new key always gets a new ID.
+	ID  string
+	key *rsa.PrivateKey
+}
+
+// ParseSigningKey reads a PKCS #1 or PKCS #8 PEM encoded RSA private key.
+func ParseSigningKey(data []byte) (*SigningKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, ErrInvalidSigningKey
+	}
+
+	var key *rsa.PrivateKey
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, ErrInvalidSigningKey
+		}
+		key = parsed
+	case "PRIVATE KEY":
+		parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, ErrInvalidSigningKey
+		}
+		rsaKey, ok := parsed.(*rsa.PrivateKey)
+		if !ok {
+			return nil, ErrInvalidSigningKey
+		}
+		key = rsaKey
+	default:
+		return nil, ErrInvalidSigningKey
+	}
+	if key.N.BitLen() < 2048 {
+		return nil, ErrInvalidSigningKey
+	}
+	return NewSigningKey(key), nil
+}
+
+func NewSigningKey(key *rsa.PrivateKey) *SigningKey {
+	return &SigningKey{ID: thumbprint(&key.PublicKey), key: key}
+}
+
+// PublicJWK is the key as published in the JWKS.
+func (k *SigningKey) PublicJWK() jwks.JSONWebKey {
+	return jwks.RSAKey(k.ID, &k.key.PublicKey)
+}
+
+func thumbprint(key *rsa.PublicKey) string {
+	// An RSA key always has the members a thumbprint needs.
+	id, _ := jwks.RSAKey("", key).Thumbprint()
+	return id
+}
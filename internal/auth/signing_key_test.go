@@ -0,0 +1,135 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/radiatus-ai/auth-service/internal/model"
+)
+
+func newTestSigningKey(t *testing.T) *SigningKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	return NewSigningKey(key)
+}
+
+func TestParseSigningKey(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(key)
+	assert.NoError(t, err)
+
+	for name, block := range map[string]*pem.Block{
+		"pkcs1": {Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)},
+		"pkcs8": {Type: "PRIVATE KEY", Bytes: pkcs8},
+	} {
+		parsed, err := ParseSigningKey(pem.EncodeToMemory(block))
+		assert.NoError(t, err, name)
+		assert.Equal(t, NewSigningKey(key).ID, parsed.ID, name)
+	}
+
+	_, err = ParseSigningKey([]byte("not a key"))
+	assert.ErrorIs(t, err, ErrInvalidSigningKey)
+}
+
+func TestSignClaims_RS256(t *testing.T) {
+	user := &model.User{ID: uuid.New(), Email: "ada@example.com", Status: model.UserStatusActive}
+	svc := newVerifyTestService(user)
+	svc.signingKey = newTestSigningKey(t)
+
+	token, err := svc.generateToken(user)
+	assert.NoError(t, err)
+
+	parsed, _, err := new(jwt.Parser).ParseUnverified(token, jwt.MapClaims{})
+	assert.NoError(t, err)
+	assert.Equal(t, "RS256", parsed.Method.Alg())
+	assert.Equal(t, svc.signingKey.ID, parsed.Header["kid"])
+
+	claims, err := svc.VerifyClaims(token)
+	assert.NoError(t, err)
+	assert.Equal(t, "ada@example.com", claims.Email)
+}
+
+func TestVerifyClaims_AcceptsLegacySecretAfterKeyIsConfigured(t *testing.T) {
+	user := &model.User{ID: uuid.New(), Status: model.UserStatusActive}
+	svc := newVerifyTestService(user)
+	legacy, err := svc.generateToken(user)
+	assert.NoError(t, err)
+
+	svc.signingKey = newTestSigningKey(t)
+	_, err = svc.VerifyClaims(legacy)
+	assert.NoError(t, err)
+
+	// Tokens signed with a key that is no longer configured are rejected.
+	other := newVerifyTestService(user)
+	other.signingKey = newTestSigningKey(t)
+	token, err := other.generateToken(user)
+	assert.NoError(t, err)
+	_, err = svc.VerifyClaims(token)
+	assert.Error(t, err)
+}
+
+func TestJWKS(t *testing.T) {
+	svc := newVerifyTestService()
+	assert.Empty(t, svc.JWKS().Keys)
+
+	svc.signingKey = newTestSigningKey(t)
+	set := svc.JWKS()
+	assert.Len(t, set.Keys, 1)
+	assert.Equal(t, svc.signingKey.ID, set.Keys[0].Kid)
+	assert.Equal(t, "RS256", set.Keys[0].Alg)
+}
+
+func TestIntrospect(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	user := &model.User{ID: uuid.New(), Email: "ada@example.com", Status: model.UserStatusActive}
+	svc := newVerifyTestService(user)
+	svc.signingKey = newTestSigningKey(t)
+	token, err := svc.generateToken(user)
+	assert.NoError(t, err)
+
+	router := gin.New()
+	router.POST("/introspect", func(c *gin.Context) { c.Set("client_id", "billing") }, NewHandler(svc, nil).Introspect)
+	router.POST("/unauthenticated/introspect", NewHandler(svc, nil).Introspect)
+	introspect := func(token string) map[string]interface{} {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/introspect", strings.NewReader(url.Values{"token": {token}}.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var body map[string]interface{}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		return body
+	}
+
+	body := introspect(token)
+	assert.Equal(t, true, body["active"])
+	assert.Equal(t, user.ID.String(), body["sub"])
+	assert.Equal(t, SubjectUser, body["sub_type"])
+	assert.Equal(t, "ada@example.com", body["email"])
+
+	assert.Equal(t, map[string]interface{}{"active": false}, introspect("garbage"))
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/unauthenticated/introspect", strings.NewReader(url.Values{"token": {token}}.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code, "callers must be authenticated")
+	assert.NotContains(t, w.Body.String(), "ada@example.com")
+}
@@ -0,0 +1,155 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/api/idtoken"
+
+	"github.com/radiatus-ai/auth-service/internal/model"
+)
+
+// Authentication context class references, after the NIST SP 800-63B
+// authenticator assurance levels. Higher levels satisfy lower ones.
+const (
+	ACRSingleFactor = "aal1"
+	ACRMultiFactor  = "aal2"
+)
+
+var acrLevels = map[string]int{
+	ACRSingleFactor: 1,
+	ACRMultiFactor:  2,
+}
+
+// AMRFederated is the authentication method (RFC 8176) of a sign-in with an
+// external identity provider.
+const AMRFederated = "fed"
+
+// multiFactorMethods are the RFC 8176 methods that only a second factor
+// provides.
+var multiFactorMethods = map[string]bool{
+	"mfa": true,
+	"hwk": true,
+	"otp": true,
+	"sms": true,
+	"swk": true,
+}
+
+var (
+	ErrNoSession            = errors.New("token does not belong to a session")
+	ErrReauthenticationUser = errors.New("re-authentication must be done by the signed-in user")
+	ErrInvalidIdentityToken = errors.New("invalid identity token")
+)
+
+// ValidACR reports whether acr is one of the classes this service issues.
+func ValidACR(acr string) bool {
+	_, ok := acrLevels[acr]
+	return ok
+}
+
+// ACRSatisfies reports whether an authentication of class acr meets the
+// required class.
+func ACRSatisfies(acr, required string) bool {
+	if required == "" {
+		return true
+	}
+	return acrLevels[acr] >= acrLevels[required] && acrLevels[acr] > 0
+}
+
+// googleAuthentication returns how a Google ID token says the user signed
+// in. Google passes on the methods when the account used a second factor;
+// otherwise the sign-in is single-factor.
+func googleAuthentication(payload *idtoken.Payload) (string, []string) {
+	amr := []string{AMRFederated}
+	acr := ACRSingleFactor
+	if methods, ok := payload.Claims["amr"].([]interface{}); ok {
+		for _, method := range methods {
+			if method, ok := method.(string); ok && method != AMRFederated {
+				amr = append(amr, method)
+				if multiFactorMethods[method] {
+					acr = ACRMultiFactor
+				}
+			}
+		}
+	}
+	return acr, amr
+}
+
+// validateGoogleToken checks a Google ID token was issued to one of our
+// clients.
+func (s *service) validateGoogleToken(token string) (*idtoken.Payload, error) {
+	var payload *idtoken.Payload
+	err := ErrInvalidIdentityToken
+	for _, clientID := range s.googleClientIDs {
+		payload, err = s.validateIDToken(context.Background(), token, clientID)
+		if err == nil {
+			return payload, nil
+		}
+	}
+	return nil, err
+}
+
+// Reauthenticate records a fresh sign-in to the session of claims with a
+// Google ID token, and issues a token for the session carrying the new
+// auth_time, acr and amr. It is how a user satisfies a step-up challenge.
+func (s *service) Reauthenticate(claims *Claims, googleToken string) (string, time.Time, error) {
+	if claims.SessionID == "" || claims.IsImpersonated() {
+		return "", time.Time{}, ErrNoSession
+	}
+	sessionID, err := uuid.Parse(claims.SessionID)
+	if err != nil {
+		return "", time.Time{}, ErrNoSession
+	}
+	payload, err := s.validateGoogleToken(googleToken)
+	if err != nil {
+		log.Printf("Failed to validate Google token for re-authentication: %v", err)
+		return "", time.Time{}, ErrInvalidIdentityToken
+	}
+	user, err := s.userRepo.GetByGoogleID(payload.Subject)
+	if err != nil || user.ID.String() != claims.Subject {
+		log.Printf("Rejected re-authentication of user %s by another account", claims.Subject)
+		return "", time.Time{}, ErrReauthenticationUser
+	}
+
+	session, err := s.sessionRepo.GetByID(sessionID)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	now := time.Now()
+	session.AuthTime = now
+	session.ACR, session.AMR = googleAuthentication(payload)
+	session.LastSeenAt = now
+	if err := s.sessionRepo.Update(session); err != nil {
+		return "", time.Time{}, err
+	}
+
+	policy, err := s.userSessionPolicy(user.ID)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	upgraded := sessionClaims(user, session, now, policy)
+	token, err := s.signClaims(upgraded)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	log.Printf("User %s re-authenticated session %s at %s", user.ID, session.ID, session.ACR)
+	return token, upgraded.ExpiresAt, nil
+}
+
+// sessionClaims are the claims of a token issued for session at now.
+func sessionClaims(user *model.User, session *model.Session, now time.Time, policy SessionPolicy) *Claims {
+	return &Claims{
+		Subject:       user.ID.String(),
+		Email:         user.Email,
+		SessionID:     session.ID.String(),
+		AuthTime:      session.AuthTime,
+		ACR:           session.ACR,
+		AMR:           session.AMR,
+		IssuedAt:      now,
+		ExpiresAt:     session.Cap(now.Add(policy.TokenTTL)),
+		KeyThumbprint: session.KeyThumbprint,
+	}
+}
@@ -0,0 +1,71 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/radiatus-ai/auth-service/internal/model"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/idtoken"
+)
+
+func TestACRSatisfies(t *testing.T) {
+	assert.True(t, ACRSatisfies(ACRSingleFactor, ACRSingleFactor))
+	assert.True(t, ACRSatisfies(ACRMultiFactor, ACRSingleFactor))
+	assert.False(t, ACRSatisfies(ACRSingleFactor, ACRMultiFactor))
+	assert.False(t, ACRSatisfies("", ACRSingleFactor))
+	assert.False(t, ACRSatisfies("bogus", ACRSingleFactor))
+	assert.True(t, ACRSatisfies("", ""))
+}
+
+func TestGoogleAuthentication(t *testing.T) {
+	acr, amr := googleAuthentication(&idtoken.Payload{Claims: map[string]interface{}{}})
+	assert.Equal(t, ACRSingleFactor, acr)
+	assert.Equal(t, []string{AMRFederated}, amr)
+
+	acr, amr = googleAuthentication(&idtoken.Payload{Claims: map[string]interface{}{"amr": []interface{}{"pwd", "hwk"}}})
+	assert.Equal(t, ACRMultiFactor, acr)
+	assert.Equal(t, []string{AMRFederated, "pwd", "hwk"}, amr)
+}
+
+func TestReauthenticate(t *testing.T) {
+	user := &model.User{ID: uuid.New(), GoogleID: "google-ada", Email: "ada@example.com", Status: model.UserStatusActive}
+	other := &model.User{ID: uuid.New(), GoogleID: "google-bob", Status: model.UserStatusActive}
+	svc := newVerifyTestService(user, other)
+	svc.googleClientIDs = []string{"client"}
+	svc.validateIDToken = func(ctx context.Context, token, audience string) (*idtoken.Payload, error) {
+		switch token {
+		case "ada-mfa":
+			return &idtoken.Payload{Subject: user.GoogleID, Claims: map[string]interface{}{"amr": []interface{}{"mfa"}}}, nil
+		case "bob":
+			return &idtoken.Payload{Subject: other.GoogleID, Claims: map[string]interface{}{}}, nil
+		}
+		return nil, errors.New("invalid token")
+	}
+
+	claims, _, err := svc.startSession(user, model.AuthMethodGoogle, ACRSingleFactor, []string{AMRFederated}, ClientInfo{})
+	assert.NoError(t, err)
+	signedInAt := time.Now().Add(-time.Hour)
+	session := svc.sessionRepo.(*stubSessionRepository).sessions[uuid.MustParse(claims.SessionID)]
+	session.AuthTime = signedInAt
+
+	_, _, err = svc.Reauthenticate(claims, "garbage")
+	assert.ErrorIs(t, err, ErrInvalidIdentityToken)
+	_, _, err = svc.Reauthenticate(claims, "bob")
+	assert.ErrorIs(t, err, ErrReauthenticationUser)
+	_, _, err = svc.Reauthenticate(&Claims{Subject: user.ID.String()}, "ada-mfa")
+	assert.ErrorIs(t, err, ErrNoSession)
+
+	token, _, err := svc.Reauthenticate(claims, "ada-mfa")
+	assert.NoError(t, err)
+	upgraded, err := svc.VerifyClaims(token)
+	assert.NoError(t, err)
+	assert.Equal(t, claims.SessionID, upgraded.SessionID)
+	assert.WithinDuration(t, time.Now(), upgraded.AuthTime, time.Minute)
+	assert.Equal(t, ACRMultiFactor, upgraded.ACR)
+	assert.Equal(t, []string{AMRFederated, "mfa"}, upgraded.AMR)
+	assert.Equal(t, ACRMultiFactor, session.ACR, "the session keeps the upgrade across refreshes")
+}
@@ -0,0 +1,446 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/radiatus-ai/auth-service/internal/credential"
+	"github.com/radiatus-ai/auth-service/internal/model"
+	"github.com/radiatus-ai/auth-service/internal/repository"
+	"github.com/radiatus-ai/auth-service/pkg/dpop"
+	"github.com/stretchr/testify/assert"
+)
+
+const testSecret = "test-secret"
+
+var testSessionPolicy = SessionPolicy{TokenTTL: 24 * time.Hour, RefreshTokenTTL: 30 * 24 * time.Hour}
+
+type stubUserRepository struct {
+	repository.UserRepository
+	users map[uuid.UUID]*model.User
+}
+
+func (s *stubUserRepository) GetByID(id uuid.UUID) (*model.User, error) {
+	user, ok := s.users[id]
+	if !ok {
+		return nil, repository.ErrUserNotFound
+	}
+	return user, nil
+}
+
+type stubSessionRepository struct {
+	repository.SessionRepository
+	sessions map[uuid.UUID]*model.Session
+	touched  int
+}
+
+func (s *stubSessionRepository) Create(session *model.Session) error {
+	session.ID = uuid.New()
+	s.sessions[session.ID] = session
+	return nil
+}
+
+func (s *stubSessionRepository) GetByID(id uuid.UUID) (*model.Session, error) {
+	session, ok := s.sessions[id]
+	if !ok {
+		return nil, repository.ErrSessionNotFound
+	}
+	return session, nil
+}
+
+func (s *stubSessionRepository) Touch(id uuid.UUID, at time.Time) error {
+	s.touched++
+	s.sessions[id].LastSeenAt = at
+	return nil
+}
+
+// GetByRefreshTokenHash returns a copy, as a database would, so that a
+// rotation only takes effect through Update or Rotate.
+func (s *stubSessionRepository) GetByRefreshTokenHash(hash string) (*model.Session, error) {
+	for _, session := range s.sessions {
+		if session.RefreshTokenHash != nil && *session.RefreshTokenHash == hash {
+			copied := *session
+			return &copied, nil
+		}
+	}
+	return nil, repository.ErrSessionNotFound
+}
+
+func (s *stubSessionRepository) Rotate(session *model.Session, oldHash string) error {
+	stored, ok := s.sessions[session.ID]
+	if !ok || stored.RefreshTokenHash == nil || *stored.RefreshTokenHash != oldHash {
+		return repository.ErrSessionNotFound
+	}
+	*stored = *session
+	return nil
+}
+
+func (s *stubSessionRepository) Update(session *model.Session) error {
+	s.sessions[session.ID] = session
+	return nil
+}
+
+func (s *stubSessionRepository) Delete(userID, id uuid.UUID) error {
+	session, ok := s.sessions[id]
+	if !ok || session.UserID != userID {
+		return repository.ErrSessionNotFound
+	}
+	delete(s.sessions, id)
+	return nil
+}
+
+func (s *stubUserRepository) GetByGoogleID(googleID string) (*model.User, error) {
+	for _, user := range s.users {
+		if user.GoogleID == googleID {
+			return user, nil
+		}
+	}
+	return nil, repository.ErrUserNotFound
+}
+
+func newVerifyTestService(users ...*model.User) *service {
+	repo := &stubUserRepository{users: map[uuid.UUID]*model.User{}}
+	for _, user := range users {
+		repo.users[user.ID] = user
+	}
+	return &service{
+		userRepo:           repo,
+		orgRepo:            &stubOrganizationRepository{orgs: map[uuid.UUID]*model.Organization{}},
+		sessionRepo:        &stubSessionRepository{sessions: map[uuid.UUID]*model.Session{}},
+		referenceTokenRepo: &stubReferenceTokenRepository{tokens: map[string]*model.ReferenceToken{}},
+		sessionPolicy:      testSessionPolicy,
+		jwtSecret:          testSecret,
+		dpop:               dpop.NewVerifier(),
+	}
+}
+
+func TestVerifyToken_UserStatus(t *testing.T) {
+	for status, want := range map[string]error{
+		model.UserStatusActive:      nil,
+		model.UserStatusSuspended:   ErrUserSuspended,
+		model.UserStatusDeactivated: ErrUserDeactivated,
+		model.UserStatusLocked:      ErrUserLocked,
+	} {
+		t.Run(status, func(t *testing.T) {
+			user := &model.User{ID: uuid.New(), Status: status}
+			svc := newVerifyTestService(user)
+			token, err := svc.generateToken(user)
+			assert.NoError(t, err)
+
+			userID, err := svc.VerifyToken(token)
+
+			if want == nil {
+				assert.NoError(t, err)
+				assert.Equal(t, user.ID.String(), userID)
+			} else {
+				assert.ErrorIs(t, err, want)
+				assert.True(t, IsAccountStatusError(err))
+			}
+		})
+	}
+}
+
+func TestVerifyToken_RevokedAfterStatusChange(t *testing.T) {
+	user := &model.User{ID: uuid.New(), Status: model.UserStatusActive}
+	svc := newVerifyTestService(user)
+	token, err := svc.generateToken(user)
+	assert.NoError(t, err)
+
+	revokedAt := time.Now().Add(time.Second)
+	user.TokensValidAfter = &revokedAt
+
+	_, err = svc.VerifyToken(token)
+
+	assert.ErrorIs(t, err, ErrTokenRevoked)
+	assert.Equal(t, CodeTokenRevoked, ErrorCode(err))
+}
+
+func TestVerifyToken_RevokedInTheSameSecond(t *testing.T) {
+	user := &model.User{ID: uuid.New(), Status: model.UserStatusActive}
+	svc := newVerifyTestService(user)
+	token, err := svc.generateToken(user)
+	assert.NoError(t, err)
+	claims, err := svc.VerifyClaims(token)
+	assert.NoError(t, err)
+
+	// The token's iat is truncated to the second, so a revocation later in
+	// that second looks simultaneous.
+	revokedAt := claims.IssuedAt.Add(500 * time.Millisecond)
+	user.TokensValidAfter = &revokedAt
+
+	_, err = svc.VerifyToken(token)
+
+	assert.ErrorIs(t, err, ErrTokenRevoked)
+}
+
+func TestVerifyClaims_Impersonation(t *testing.T) {
+	admin := &model.User{ID: uuid.New(), Status: model.UserStatusActive, PlatformRole: model.PlatformRoleSuperadmin}
+	user := &model.User{ID: uuid.New(), Status: model.UserStatusActive}
+	svc := newVerifyTestService(admin, user)
+
+	token, expiresAt, err := svc.IssueImpersonationToken(admin.ID, user.ID)
+	assert.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(impersonationTTL), expiresAt, time.Second)
+
+	claims, err := svc.VerifyClaims(token)
+	assert.NoError(t, err)
+	assert.Equal(t, user.ID.String(), claims.Subject)
+	assert.True(t, claims.IsImpersonated())
+	assert.Equal(t, admin.ID.String(), claims.Actor.Subject)
+
+	// Demoting the admin invalidates tokens they issued while impersonating.
+	admin.PlatformRole = ""
+	_, err = svc.VerifyClaims(token)
+	assert.ErrorIs(t, err, ErrTokenRevoked)
+}
+
+func TestVerifyClaims_DelegatedToken(t *testing.T) {
+	user := &model.User{ID: uuid.New(), Status: model.UserStatusActive}
+	svc := newVerifyTestService(user)
+
+	token, err := svc.IssueToken(&Claims{
+		Subject:   user.ID.String(),
+		ExpiresAt: time.Now().Add(time.Minute),
+		Audience:  "billing",
+		Scopes:    []string{"invoices:read", "invoices:write"},
+		ClientID:  "gateway",
+		Actor:     &Actor{Subject: "gateway", ClientID: "gateway"},
+	})
+	assert.NoError(t, err)
+
+	claims, err := svc.VerifyClaims(token)
+	assert.NoError(t, err)
+	assert.Equal(t, "billing", claims.Audience)
+	assert.Equal(t, []string{"invoices:read", "invoices:write"}, claims.Scopes)
+	assert.Equal(t, "gateway", claims.Actor.ClientID)
+	assert.False(t, claims.IsImpersonated())
+	assert.True(t, claims.HasScope("invoices:read"))
+	assert.False(t, claims.HasScope("invoices:delete"))
+}
+
+func TestVerifyClaims_DelegatedImpersonationToken(t *testing.T) {
+	admin := &model.User{ID: uuid.New(), Status: model.UserStatusActive, PlatformRole: model.PlatformRoleSuperadmin}
+	user := &model.User{ID: uuid.New(), Status: model.UserStatusActive}
+	svc := newVerifyTestService(admin, user)
+
+	token, err := svc.IssueToken(&Claims{
+		Subject:   user.ID.String(),
+		ExpiresAt: time.Now().Add(time.Minute),
+		Audience:  "billing",
+		Actor: &Actor{
+			Subject:  "gateway",
+			ClientID: "gateway",
+			Actor:    &Actor{Subject: admin.ID.String()},
+		},
+	})
+	assert.NoError(t, err)
+
+	claims, err := svc.VerifyClaims(token)
+	assert.NoError(t, err)
+	assert.True(t, claims.IsImpersonated())
+	assert.Equal(t, admin.ID.String(), claims.Impersonator())
+
+	// The nested impersonator is still checked after delegation.
+	admin.PlatformRole = ""
+	_, err = svc.VerifyClaims(token)
+	assert.ErrorIs(t, err, ErrTokenRevoked)
+}
+
+type stubServiceAccountRepository struct {
+	repository.ServiceAccountRepository
+	accounts map[uuid.UUID]*model.ServiceAccount
+}
+
+func (s *stubServiceAccountRepository) GetByID(id uuid.UUID) (*model.ServiceAccount, error) {
+	account, ok := s.accounts[id]
+	if !ok {
+		return nil, repository.ErrServiceAccountNotFound
+	}
+	return account, nil
+}
+
+type stubOrganizationRepository struct {
+	repository.OrganizationRepository
+	orgs map[uuid.UUID]*model.Organization
+}
+
+func (s *stubOrganizationRepository) GetByID(id uuid.UUID) (*model.Organization, error) {
+	org, ok := s.orgs[id]
+	if !ok {
+		return nil, repository.ErrOrganizationNotFound
+	}
+	return org, nil
+}
+
+// GetUserOrganizations treats every user as a member of every organization.
+func (s *stubOrganizationRepository) GetUserOrganizations(userID uuid.UUID) ([]model.Organization, error) {
+	var orgs []model.Organization
+	for _, org := range s.orgs {
+		orgs = append(orgs, *org)
+	}
+	return orgs, nil
+}
+
+func (s *stubOrganizationRepository) GetUserOrganization(userID uuid.UUID) (*model.Organization, error) {
+	for _, org := range s.orgs {
+		return org, nil
+	}
+	return nil, repository.ErrOrganizationNotFound
+}
+
+func TestVerifyClaims_ServiceAccount(t *testing.T) {
+	org := &model.Organization{ID: uuid.New()}
+	account := &model.ServiceAccount{ID: uuid.New(), OrganizationID: org.ID, ClientID: "sa_ci"}
+	svc := newVerifyTestService()
+	svc.serviceAccountRepo = &stubServiceAccountRepository{accounts: map[uuid.UUID]*model.ServiceAccount{account.ID: account}}
+	orgRepo := &stubOrganizationRepository{orgs: map[uuid.UUID]*model.Organization{org.ID: org}}
+	svc.orgRepo = orgRepo
+
+	token, err := svc.IssueToken(&Claims{
+		Subject:     account.ID.String(),
+		SubjectType: SubjectServiceAccount,
+		ExpiresAt:   time.Now().Add(time.Minute),
+		ClientID:    account.ClientID,
+	})
+	assert.NoError(t, err)
+
+	claims, err := svc.VerifyClaims(token)
+	assert.NoError(t, err)
+	assert.True(t, claims.IsServiceAccount())
+	assert.Equal(t, account.ID.String(), claims.Subject)
+
+	account.Disabled = true
+	_, err = svc.VerifyClaims(token)
+	assert.ErrorIs(t, err, ErrTokenRevoked)
+
+	account.Disabled = false
+	delete(orgRepo.orgs, org.ID)
+	_, err = svc.VerifyClaims(token)
+	assert.ErrorIs(t, err, ErrTokenRevoked)
+}
+
+func TestVerifyClaims_UserTokenHasUserSubjectType(t *testing.T) {
+	user := &model.User{ID: uuid.New(), Status: model.UserStatusActive}
+	svc := newVerifyTestService(user)
+	token, err := svc.generateToken(user)
+	assert.NoError(t, err)
+
+	claims, err := svc.VerifyClaims(token)
+
+	assert.NoError(t, err)
+	assert.Equal(t, SubjectUser, claims.SubjectType)
+	assert.False(t, claims.IsServiceAccount())
+}
+
+type stubPersonalAccessTokenRepository struct {
+	repository.PersonalAccessTokenRepository
+	tokens  map[string]*model.PersonalAccessToken
+	touched int
+}
+
+func (s *stubPersonalAccessTokenRepository) GetByHash(hash string) (*model.PersonalAccessToken, error) {
+	token, ok := s.tokens[hash]
+	if !ok {
+		return nil, repository.ErrPersonalAccessTokenNotFound
+	}
+	return token, nil
+}
+
+func (s *stubPersonalAccessTokenRepository) TouchLastUsed(id uuid.UUID, at time.Time) error {
+	s.touched++
+	return nil
+}
+
+func TestVerifyClaims_PersonalAccessToken(t *testing.T) {
+	user := &model.User{ID: uuid.New(), Status: model.UserStatusActive}
+	orgID := uuid.New()
+	plaintext := PersonalAccessTokenPrefix + "secret"
+	pat := &model.PersonalAccessToken{
+		ID:             uuid.New(),
+		UserID:         user.ID,
+		OrganizationID: &orgID,
+		Scopes:         model.StringList{ScopeAPIRead},
+		CreatedAt:      time.Now(),
+	}
+	patRepo := &stubPersonalAccessTokenRepository{tokens: map[string]*model.PersonalAccessToken{credential.Hash(plaintext): pat}}
+	svc := newVerifyTestService(user)
+	svc.patRepo = patRepo
+
+	claims, err := svc.VerifyClaims(plaintext)
+	assert.NoError(t, err)
+	assert.Equal(t, user.ID.String(), claims.Subject)
+	assert.True(t, claims.IsPersonalAccessToken())
+	assert.Equal(t, orgID.String(), claims.OrganizationID)
+	assert.Equal(t, []string{ScopeAPIRead}, claims.Scopes)
+	assert.Equal(t, 1, patRepo.touched)
+
+	// Last use is only recorded once per lastUsedResolution.
+	now := time.Now()
+	pat.LastUsedAt = &now
+	_, err = svc.VerifyClaims(plaintext)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, patRepo.touched)
+
+	_, err = svc.VerifyClaims(PersonalAccessTokenPrefix + "unknown")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	expired := time.Now().Add(-time.Minute)
+	pat.ExpiresAt = &expired
+	_, err = svc.VerifyClaims(plaintext)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	pat.ExpiresAt = nil
+	user.Status = model.UserStatusSuspended
+	_, err = svc.VerifyClaims(plaintext)
+	assert.ErrorIs(t, err, ErrUserSuspended)
+}
+
+type stubAPIKeyRepository struct {
+	repository.APIKeyRepository
+	keys map[string]*model.APIKey
+}
+
+func (s *stubAPIKeyRepository) GetByHash(hash string) (*model.APIKey, error) {
+	key, ok := s.keys[hash]
+	if !ok {
+		return nil, repository.ErrAPIKeyNotFound
+	}
+	return key, nil
+}
+
+func (s *stubAPIKeyRepository) TouchLastUsed(id uuid.UUID, at time.Time, ip string) error {
+	return nil
+}
+
+func TestVerifyRequest_APIKey(t *testing.T) {
+	org := &model.Organization{ID: uuid.New()}
+	plaintext := APIKeyPrefix + "secret"
+	key := &model.APIKey{
+		ID:             uuid.New(),
+		OrganizationID: org.ID,
+		Scopes:         model.StringList{"org:read"},
+		AllowedCIDRs:   model.StringList{"10.0.0.0/8"},
+	}
+	svc := newVerifyTestService()
+	svc.orgRepo = &stubOrganizationRepository{orgs: map[uuid.UUID]*model.Organization{org.ID: org}}
+	svc.apiKeyRepo = &stubAPIKeyRepository{keys: map[string]*model.APIKey{credential.Hash(plaintext): key}}
+
+	claims, err := svc.VerifyRequest(plaintext, "10.1.2.3")
+	assert.NoError(t, err)
+	assert.True(t, claims.IsAPIKey())
+	assert.Equal(t, key.ID.String(), claims.Subject)
+	assert.Equal(t, org.ID.String(), claims.OrganizationID)
+
+	_, err = svc.VerifyRequest(plaintext, "192.0.2.1")
+	assert.ErrorIs(t, err, ErrAddressNotAllowed)
+
+	// Without an address an allowlisted key cannot be verified.
+	_, err = svc.VerifyClaims(plaintext)
+	assert.ErrorIs(t, err, ErrAddressNotAllowed)
+
+	expired := time.Now().Add(-time.Second)
+	key.ExpiresAt = &expired
+	_, err = svc.VerifyRequest(plaintext, "10.1.2.3")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
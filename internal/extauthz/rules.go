@@ -0,0 +1,117 @@
+package extauthz
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/radiatus-ai/auth-service/internal/rbac"
+)
+
+var ErrInvalidRule = errors.New("invalid ext_authz rule")
+
+// Rule decides how requests to a route are authorized. The first rule whose
+// path prefix and methods match a request applies; requests no rule matches
+// only need a valid token, like routes behind AuthMiddleware. Prefixes match
+// whole path segments of the cleaned path, so "/admin" matches "/admin" and
+// "/admin/users" but not "/adminx", and "/public/../admin" is "/admin".
+type Rule struct {
+	PathPrefix string `json:"path_prefix"`
+	// Methods limits the rule to these HTTP methods; empty matches any.
+	Methods []string `json:"methods,omitempty"`
+	// Public routes are let through without a token.
+	Public bool `json:"public,omitempty"`
+	// Organization is the ID or slug of the organization the route belongs
+	// to. OrganizationHeader names a request header carrying it instead, for
+	// services shared by several organizations.
+	Organization       string `json:"organization,omitempty"`
+	OrganizationHeader string `json:"organization_header,omitempty"`
+	// Permission must be held in the organization.
+	Permission rbac.Permission `json:"permission,omitempty"`
+	// Audience is the name of the service behind the route, whose exchanged
+	// tokens it accepts as well.
+	Audience string `json:"audience,omitempty"`
+}
+
+// LoadRules reads a JSON array of rules from path. An empty path means no
+// rules.
+func LoadRules(path string) ([]Rule, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var rules []Rule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	for i := range rules {
+		if err := rules[i].validate(); err != nil {
+			return nil, fmt.Errorf("rule %d (%s): %w", i, rules[i].PathPrefix, err)
+		}
+	}
+	return rules, nil
+}
+
+func (r *Rule) validate() error {
+	if !strings.HasPrefix(r.PathPrefix, "/") {
+		return ErrInvalidRule
+	}
+	if r.Organization != "" && r.OrganizationHeader != "" {
+		return ErrInvalidRule
+	}
+	if r.Permission != "" && (!r.Permission.Valid() || (r.Organization == "" && r.OrganizationHeader == "")) {
+		return ErrInvalidRule
+	}
+	r.PathPrefix = path.Clean(r.PathPrefix)
+	r.OrganizationHeader = strings.ToLower(r.OrganizationHeader)
+	for i, method := range r.Methods {
+		r.Methods[i] = strings.ToUpper(method)
+	}
+	return nil
+}
+
+// matches reports whether the rule applies to a request; path must have
+// been cleaned with cleanPath.
+func (r *Rule) matches(method, path string) bool {
+	prefix := strings.TrimSuffix(r.PathPrefix, "/")
+	if prefix != "" && path != prefix && !strings.HasPrefix(path, prefix+"/") {
+		return false
+	}
+	if len(r.Methods) == 0 {
+		return true
+	}
+	for _, m := range r.Methods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+// cleanPath decodes and cleans a request path the way the service behind the
+// proxy will see it, so that encoded characters, dot segments and repeated
+// slashes cannot steer a request past the rule meant for it. It reports
+// false for paths that cannot be decoded.
+func cleanPath(raw string) (string, bool) {
+	decoded, err := url.PathUnescape(raw)
+	if err != nil {
+		return "", false
+	}
+	return path.Clean("/" + decoded), true
+}
+
+func matchRule(rules []Rule, method, path string) *Rule {
+	for i := range rules {
+		if rules[i].matches(method, path) {
+			return &rules[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package extauthz
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"strings"
+
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	authv3 "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
+	typev3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	"google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	"github.com/radiatus-ai/auth-service/internal/auth"
+	"github.com/radiatus-ai/auth-service/internal/forwardauth"
+)
+
+// identityHeaders are set on every allowed request, overwriting whatever the
+// client sent, so upstreams can trust them.
+var identityHeaders = []string{
+	forwardauth.HeaderUserID,
+	forwardauth.HeaderSubjectType,
+	forwardauth.HeaderEmail,
+	forwardauth.HeaderImpersonator,
+	forwardauth.HeaderOrgID,
+	forwardauth.HeaderOrgSlug,
+	forwardauth.HeaderRoles,
+}
+
+// Server implements Envoy's external authorization API
+// (envoy.service.auth.v3.Authorization), so services in the mesh are
+// authorized by Envoy instead of linking AuthMiddleware. Decisions are made
+// by the forward-auth service; rules pick the organization and permission
+// for each route.
+type Server struct {
+	authv3.UnimplementedAuthorizationServer
+	service forwardauth.Service
+	rules   []Rule
+}
+
+func NewServer(service forwardauth.Service, rules []Rule) *Server {
+	return &Server{service: service, rules: rules}
+}
+
+func (s *Server) Check(ctx context.Context, req *authv3.CheckRequest) (*authv3.CheckResponse, error) {
+	httpReq := req.GetAttributes().GetRequest().GetHttp()
+	method, path := httpReq.GetMethod(), httpReq.GetPath()
+	if i := strings.IndexByte(path, '?'); i >= 0 {
+		path = path[:i]
+	}
+	headers := httpReq.GetHeaders()
+
+	cleaned, ok := cleanPath(path)
+	if !ok {
+		return deny(codes.InvalidArgument, typev3.StatusCode_BadRequest, "Invalid path"), nil
+	}
+	rule := matchRule(s.rules, method, cleaned)
+	if rule != nil && rule.Public {
+		return allow(&forwardauth.Identity{}), nil
+	}
+
+	token, _, _ := auth.ParseAuthorization(headers["authorization"])
+	authReq := forwardauth.Request{
+		Token:    token,
+		ClientIP: req.GetAttributes().GetSource().GetAddress().GetSocketAddress().GetAddress(),
+		Method:   method,
+	}
+	if rule != nil {
+		authReq.Organization = rule.Organization
+		if rule.OrganizationHeader != "" {
+			authReq.Organization = headers[rule.OrganizationHeader]
+		}
+		authReq.Permission = rule.Permission
+		authReq.Audience = rule.Audience
+	}
+	if value := req.GetAttributes().GetSource().GetCertificate(); value != "" {
+		cert, err := auth.ParseForwardedCertificate(value)
+		if err != nil {
+			log.Printf("Ignoring unreadable client certificate for %s %s: %v", method, path, err)
+		} else {
+			authReq.CertificateThumbprint = auth.CertificateThumbprint(cert)
+		}
+	}
+	if proof := headers["dpop"]; proof != "" {
+		authReq.DPoPProof = proof
+		authReq.URL = httpReq.GetScheme() + "://" + httpReq.GetHost() + path
+	}
+
+	identity, err := s.service.Authorize(authReq)
+	switch {
+	case err == nil:
+		return allow(identity), nil
+	case errors.Is(err, forwardauth.ErrUnauthenticated):
+		return deny(codes.Unauthenticated, typev3.StatusCode_Unauthorized, "Authentication required"), nil
+	case errors.Is(err, forwardauth.ErrForbidden),
+		errors.Is(err, forwardauth.ErrInvalidPermission),
+		auth.IsAccountStatusError(err),
+		errors.Is(err, auth.ErrAddressNotAllowed):
+		return deny(codes.PermissionDenied, typev3.StatusCode_Forbidden, "Forbidden"), nil
+	default:
+		// Envoy fails closed or open on errors depending on its
+		// failure_mode_allow setting; that choice belongs to the mesh.
+		log.Printf("ext_authz check failed for %s %s: %v", method, path, err)
+		return nil, err
+	}
+}
+
+func allow(identity *forwardauth.Identity) *authv3.CheckResponse {
+	values := []string{
+		identity.Subject,
+		identity.SubjectType,
+		identity.Email,
+		identity.ImpersonatorID,
+		identity.OrganizationID,
+		identity.OrganizationSlug,
+		strings.Join(identity.Roles, ","),
+	}
+	ok := &authv3.OkHttpResponse{}
+	for i, name := range identityHeaders {
+		if values[i] == "" {
+			ok.HeadersToRemove = append(ok.HeadersToRemove, strings.ToLower(name))
+			continue
+		}
+		ok.Headers = append(ok.Headers, &corev3.HeaderValueOption{
+			Header:       &corev3.HeaderValue{Key: name, Value: values[i]},
+			AppendAction: corev3.HeaderValueOption_OVERWRITE_IF_EXISTS_OR_ADD,
+		})
+	}
+	return &authv3.CheckResponse{
+		Status:       &status.Status{Code: int32(codes.OK)},
+		HttpResponse: &authv3.CheckResponse_OkResponse{OkResponse: ok},
+	}
+}
+
+func deny(code codes.Code, httpStatus typev3.StatusCode, message string) *authv3.CheckResponse {
+	body := `{"error":"` + message + `"}`
+	headers := []*corev3.HeaderValueOption{{
+		Header: &corev3.HeaderValue{Key: "Content-Type", Value: "application/json"},
+	}}
+	if httpStatus == typev3.StatusCode_Unauthorized {
+		headers = append(headers, &corev3.HeaderValueOption{
+			Header: &corev3.HeaderValue{Key: "WWW-Authenticate", Value: `Bearer realm="ext_authz"`},
+		})
+	}
+	return &authv3.CheckResponse{
+		Status: &status.Status{Code: int32(code), Message: message},
+		HttpResponse: &authv3.CheckResponse_DeniedResponse{DeniedResponse: &authv3.DeniedHttpResponse{
+			Status:  &typev3.HttpStatus{Code: httpStatus},
+			Headers: headers,
+			Body:    body,
+		}},
+	}
+}
+
+// Serve registers server on a new gRPC server and serves it on lis until the
+// listener fails.
+func Serve(lis net.Listener, server *Server) error {
+	grpcServer := grpc.NewServer()
+	authv3.RegisterAuthorizationServer(grpcServer, server)
+	return grpcServer.Serve(lis)
+}
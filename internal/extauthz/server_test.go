@@ -0,0 +1,242 @@
+package extauthz
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	authv3 "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
+	typev3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+
+	"github.com/radiatus-ai/auth-service/internal/auth"
+	"github.com/radiatus-ai/auth-service/internal/forwardauth"
+	"github.com/radiatus-ai/auth-service/internal/model"
+	"github.com/radiatus-ai/auth-service/internal/rbac"
+)
+
+type stubForwardAuth struct {
+	requests []forwardauth.Request
+	identity *forwardauth.Identity
+	err      error
+}
+
+func (s *stubForwardAuth) Authorize(req forwardauth.Request) (*forwardauth.Identity, error) {
+	s.requests = append(s.requests, req)
+	return s.identity, s.err
+}
+
+type stubAuthService struct {
+	auth.Service
+	claims map[string]*auth.Claims
+}
+
+func (s *stubAuthService) VerifyRequest(token, clientIP string) (*auth.Claims, error) {
+	claims, ok := s.claims[token]
+	if !ok {
+		return nil, auth.ErrInvalidToken
+	}
+	return claims, nil
+}
+
+func (s *stubAuthService) GetUserByID(userID string) (*model.User, error) {
+	return &model.User{Email: "ada@example.com"}, nil
+}
+
+func checkRequest(method, path string, headers map[string]string) *authv3.CheckRequest {
+	return &authv3.CheckRequest{Attributes: &authv3.AttributeContext{
+		Source: &authv3.AttributeContext_Peer{Address: &corev3.Address{
+			Address: &corev3.Address_SocketAddress{SocketAddress: &corev3.SocketAddress{Address: "10.0.0.7"}},
+		}},
+		Request: &authv3.AttributeContext_Request{Http: &authv3.AttributeContext_HttpRequest{
+			Method:  method,
+			Path:    path,
+			Headers: headers,
+		}},
+	}}
+}
+
+func okHeaders(resp *authv3.CheckResponse) map[string]string {
+	headers := map[string]string{}
+	for _, option := range resp.GetOkResponse().GetHeaders() {
+		headers[option.GetHeader().GetKey()] = option.GetHeader().GetValue()
+	}
+	return headers
+}
+
+var testRules = []Rule{
+	{PathPrefix: "/healthz", Public: true},
+	{PathPrefix: "/billing/", Methods: []string{"POST"}, Organization: "acme", Permission: rbac.PermOrgUpdate},
+	{PathPrefix: "/reports/", OrganizationHeader: "x-org-id", Permission: rbac.PermAuditRead},
+}
+
+func TestCheck_AllowsWithIdentityHeaders(t *testing.T) {
+	stub := &stubForwardAuth{identity: &forwardauth.Identity{
+		Subject:          "user-1",
+		SubjectType:      auth.SubjectUser,
+		Email:            "ada@example.com",
+		OrganizationID:   "org-1",
+		OrganizationSlug: "acme",
+		Roles:            []string{"admin"},
+	}}
+	server := NewServer(stub, testRules)
+
+	resp, err := server.Check(context.Background(), checkRequest("POST", "/billing/invoices?draft=1", map[string]string{
+		"authorization": "Bearer token-1",
+	}))
+
+	assert.NoError(t, err)
+	assert.Equal(t, int32(codes.OK), resp.GetStatus().GetCode())
+	assert.Equal(t, forwardauth.Request{
+		Token:        "token-1",
+		ClientIP:     "10.0.0.7",
+		Method:       "POST",
+		Organization: "acme",
+		Permission:   rbac.PermOrgUpdate,
+	}, stub.requests[0])
+	headers := okHeaders(resp)
+	assert.Equal(t, "user-1", headers[forwardauth.HeaderUserID])
+	assert.Equal(t, "ada@example.com", headers[forwardauth.HeaderEmail])
+	assert.Equal(t, "admin", headers[forwardauth.HeaderRoles])
+	// Headers without a value are stripped so clients cannot forge them.
+	assert.Contains(t, resp.GetOkResponse().GetHeadersToRemove(), "x-auth-impersonator-id")
+}
+
+func TestCheck_RuleSelection(t *testing.T) {
+	stub := &stubForwardAuth{identity: &forwardauth.Identity{Subject: "user-1"}}
+	server := NewServer(stub, testRules)
+
+	_, _ = server.Check(context.Background(), checkRequest("GET", "/reports/monthly", map[string]string{"x-org-id": "labs"}))
+	_, _ = server.Check(context.Background(), checkRequest("GET", "/billing/invoices", nil))
+
+	assert.Equal(t, "labs", stub.requests[0].Organization)
+	assert.Equal(t, rbac.PermAuditRead, stub.requests[0].Permission)
+	// GET does not match the POST-only billing rule, so only a valid token is
+	// needed.
+	assert.Empty(t, stub.requests[1].Organization)
+	assert.Empty(t, stub.requests[1].Permission)
+}
+
+func TestCheck_RulesMatchCleanedPathSegments(t *testing.T) {
+	rules := []Rule{
+		{PathPrefix: "/public", Public: true},
+		{PathPrefix: "/admin", Organization: "acme", Permission: rbac.PermOrgUpdate},
+	}
+	for path, want := range map[string]rbac.Permission{
+		"/admin":                     rbac.PermOrgUpdate,
+		"/admin/users":               rbac.PermOrgUpdate,
+		"/public/../admin":           rbac.PermOrgUpdate,
+		"/%61dmin":                   rbac.PermOrgUpdate,
+		"//admin":                    rbac.PermOrgUpdate,
+		"/public/%2e%2e/admin/users": rbac.PermOrgUpdate,
+		"/adminx":                    "",
+		"/publicity":                 "",
+	} {
+		stub := &stubForwardAuth{identity: &forwardauth.Identity{Subject: "user-1"}}
+		_, err := NewServer(stub, rules).Check(context.Background(), checkRequest("GET", path, nil))
+		assert.NoError(t, err)
+		if assert.Len(t, stub.requests, 1, path) {
+			assert.Equal(t, want, stub.requests[0].Permission, path)
+		}
+	}
+
+	stub := &stubForwardAuth{}
+	resp, err := NewServer(stub, rules).Check(context.Background(), checkRequest("GET", "/public/%zz", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, typev3.StatusCode_BadRequest, resp.GetDeniedResponse().GetStatus().GetCode())
+	assert.Empty(t, stub.requests)
+}
+
+func TestCheck_PublicRoute(t *testing.T) {
+	stub := &stubForwardAuth{err: forwardauth.ErrUnauthenticated}
+	server := NewServer(stub, testRules)
+
+	resp, err := server.Check(context.Background(), checkRequest("GET", "/healthz", nil))
+
+	assert.NoError(t, err)
+	assert.Equal(t, int32(codes.OK), resp.GetStatus().GetCode())
+	assert.Empty(t, stub.requests)
+}
+
+func TestCheck_Denials(t *testing.T) {
+	for name, tc := range map[string]struct {
+		err    error
+		code   codes.Code
+		status typev3.StatusCode
+	}{
+		"unauthenticated": {forwardauth.ErrUnauthenticated, codes.Unauthenticated, typev3.StatusCode_Unauthorized},
+		"forbidden":       {forwardauth.ErrForbidden, codes.PermissionDenied, typev3.StatusCode_Forbidden},
+		"suspended":       {auth.ErrUserSuspended, codes.PermissionDenied, typev3.StatusCode_Forbidden},
+	} {
+		t.Run(name, func(t *testing.T) {
+			server := NewServer(&stubForwardAuth{err: tc.err}, testRules)
+
+			resp, err := server.Check(context.Background(), checkRequest("GET", "/api", nil))
+
+			assert.NoError(t, err)
+			assert.Equal(t, int32(tc.code), resp.GetStatus().GetCode())
+			assert.Equal(t, tc.status, resp.GetDeniedResponse().GetStatus().GetCode())
+		})
+	}
+}
+
+func TestCheck_TokensForAnotherService(t *testing.T) {
+	authService := &stubAuthService{claims: map[string]*auth.Claims{
+		"plain":     {Subject: "user-1", SubjectType: auth.SubjectUser},
+		"billing":   {Subject: "user-1", SubjectType: auth.SubjectUser, Audience: "billing"},
+		"reporting": {Subject: "user-1", SubjectType: auth.SubjectUser, Audience: "reporting"},
+		"delegated": {Subject: "user-1", SubjectType: auth.SubjectUser, Actor: &auth.Actor{Subject: "gateway", ClientID: "gateway"}},
+	}}
+	rules := []Rule{{PathPrefix: "/billing", Audience: "billing"}}
+	server := NewServer(forwardauth.NewService(authService, nil), rules)
+
+	for name, tc := range map[string]struct {
+		path  string
+		token string
+		code  codes.Code
+	}{
+		"plain token":                 {"/api", "plain", codes.OK},
+		"audience token":              {"/api", "billing", codes.Unauthenticated},
+		"delegated token":             {"/api", "delegated", codes.Unauthenticated},
+		"audience token for its rule": {"/billing/invoices", "billing", codes.OK},
+		"audience token for another":  {"/billing/invoices", "reporting", codes.Unauthenticated},
+		"delegated without audience":  {"/billing/invoices", "delegated", codes.Unauthenticated},
+	} {
+		t.Run(name, func(t *testing.T) {
+			resp, err := server.Check(context.Background(), checkRequest("GET", tc.path, map[string]string{
+				"authorization": "Bearer " + tc.token,
+			}))
+
+			assert.NoError(t, err)
+			assert.Equal(t, int32(tc.code), resp.GetStatus().GetCode())
+		})
+	}
+}
+
+func TestLoadRules(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		return path
+	}
+
+	rules, err := LoadRules(write("valid.json", `[
+		{"path_prefix": "/reports/", "methods": ["get"], "organization_header": "X-Org-Id", "permission": "audit:read"}
+	]`))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"GET"}, rules[0].Methods)
+	assert.Equal(t, "x-org-id", rules[0].OrganizationHeader)
+
+	for name, content := range map[string]string{
+		"relative prefix":        `[{"path_prefix": "reports"}]`,
+		"unknown permission":     `[{"path_prefix": "/", "organization": "acme", "permission": "nope"}]`,
+		"permission without org": `[{"path_prefix": "/", "permission": "audit:read"}]`,
+	} {
+		_, err := LoadRules(write(name+".json", content))
+		assert.ErrorIs(t, err, ErrInvalidRule, name)
+	}
+}
@@ -0,0 +1,162 @@
+package forwardauth
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/radiatus-ai/auth-service/internal/auth"
+	"github.com/radiatus-ai/auth-service/internal/rbac"
+)
+
+// Headers set on successful responses, for the proxy to copy upstream
+// (auth_request_set in nginx, authResponseHeaders in Traefik).
+const (
+	HeaderUserID       = "X-Auth-User-Id"
+	HeaderSubjectType  = "X-Auth-Subject-Type"
+	HeaderEmail        = "X-Auth-Email"
+	HeaderImpersonator = "X-Auth-Impersonator-Id"
+	HeaderOrgID        = "X-Auth-Org-Id"
+	HeaderOrgSlug      = "X-Auth-Org-Slug"
+	HeaderRoles        = "X-Auth-Roles"
+)
+
+type Handler struct {
+	service    Service
+	cookieName string
+	certHeader string
+}
+
+// NewHandler returns the forward-auth handler. Requests without an
+// Authorization header are authenticated with the token in the cookie named
+// cookieName, so browser sessions work behind the proxy; as on the API,
+// unsafe methods then need the session's CSRF token in X-CSRF-Token, which
+// the proxy must pass on. certHeader names the
+// header the proxy passes the client certificate in, for certificate-bound
+// tokens; when it is "" such tokens are rejected. Clients must not be able to
+// set it themselves, as certificates are public.
+func NewHandler(service Service, cookieName, certHeader string) *Handler {
+	return &Handler{service: service, cookieName: cookieName, certHeader: certHeader}
+}
+
+// ForwardAuth answers nginx auth_request and Traefik ForwardAuth subrequests
+// with 200 and identity headers, 401 or 403. The protected app picks its
+// organization and required permission with query parameters, e.g.
+// /forward-auth?org=acme&permission=audit:read.
+func (h *Handler) ForwardAuth(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+
+	token, fromCookie := h.token(c)
+	identity, err := h.service.Authorize(Request{
+		Token:        token,
+		ClientIP:     c.ClientIP(),
+		FromCookie:   fromCookie,
+		CSRFToken:    c.GetHeader(auth.CSRFHeader),
+		Method:       originalMethod(c),
+		Organization: c.Query("org"),
+		Permission:   rbac.Permission(c.Query("permission")),
+		DPoPProof:    c.GetHeader(auth.DPoPHeader),
+		URL:          originalURL(c),
+
+		CertificateThumbprint: h.certificateThumbprint(c),
+	})
+	switch {
+	case err == nil:
+	case errors.Is(err, ErrUnauthenticated):
+		c.Header("WWW-Authenticate", `Bearer realm="forward-auth"`)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	case errors.Is(err, ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+		return
+	case auth.IsAccountStatusError(err), errors.Is(err, auth.ErrAddressNotAllowed):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error(), "code": auth.ErrorCode(err)})
+		return
+	case errors.Is(err, ErrInvalidPermission):
+		// Proxies treat anything but 2xx, 401 and 403 as a failure of the
+		// auth service itself, which is right for a misconfigured route.
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to authorize request"})
+		return
+	}
+
+	c.Header(HeaderUserID, identity.Subject)
+	c.Header(HeaderSubjectType, identity.SubjectType)
+	c.Header(HeaderEmail, identity.Email)
+	c.Header(HeaderImpersonator, identity.ImpersonatorID)
+	c.Header(HeaderOrgID, identity.OrganizationID)
+	c.Header(HeaderOrgSlug, identity.OrganizationSlug)
+	c.Header(HeaderRoles, strings.Join(identity.Roles, ","))
+	c.Status(http.StatusOK)
+}
+
+// token returns the request's token and whether it came from the session
+// cookie.
+func (h *Handler) token(c *gin.Context) (string, bool) {
+	if header := c.GetHeader("Authorization"); header != "" {
+		token, _, _ := auth.ParseAuthorization(header)
+		return token, false
+	}
+	if h.cookieName == "" {
+		return "", false
+	}
+	token, err := c.Cookie(h.cookieName)
+	if err != nil || token == "" {
+		return "", false
+	}
+	return token, true
+}
+
+// certificateThumbprint is the thumbprint of the client certificate the
+// proxy passed on, or "" if there is none.
+func (h *Handler) certificateThumbprint(c *gin.Context) string {
+	if h.certHeader == "" {
+		return ""
+	}
+	value := c.GetHeader(h.certHeader)
+	if value == "" {
+		return ""
+	}
+	cert, err := auth.ParseForwardedCertificate(value)
+	if err != nil {
+		log.Printf("Ignoring unreadable client certificate in %s: %v", h.certHeader, err)
+		return ""
+	}
+	return auth.CertificateThumbprint(cert)
+}
+
+// originalMethod is the method of the request being authorized. Traefik
+// always calls with GET and sends X-Forwarded-Method; nginx subrequests keep
+// the original method, which some configs also pass as X-Original-Method.
+func originalMethod(c *gin.Context) string {
+	if method := c.GetHeader("X-Forwarded-Method"); method != "" {
+		return method
+	}
+	if method := c.GetHeader("X-Original-Method"); method != "" {
+		return method
+	}
+	return c.Request.Method
+}
+
+// originalURL is the URL of the request being authorized, which DPoP proofs
+// name. Traefik sends it in X-Forwarded-Proto, X-Forwarded-Host and
+// X-Forwarded-Uri; nginx configs pass the proxy's $scheme://$host$request_uri
+// as X-Original-URL.
+func originalURL(c *gin.Context) string {
+	if url := c.GetHeader("X-Original-URL"); url != "" {
+		return url
+	}
+	if host := c.GetHeader("X-Forwarded-Host"); host != "" {
+		scheme := c.GetHeader("X-Forwarded-Proto")
+		if scheme == "" {
+			scheme = "https"
+		}
+		return scheme + "://" + host + c.GetHeader("X-Forwarded-Uri")
+	}
+	return auth.RequestURL(c.Request)
+}
@@ -0,0 +1,247 @@
+package forwardauth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/radiatus-ai/auth-service/internal/auth"
+	"github.com/radiatus-ai/auth-service/internal/model"
+	"github.com/radiatus-ai/auth-service/internal/organization"
+	"github.com/radiatus-ai/auth-service/internal/rbac"
+	"github.com/radiatus-ai/auth-service/internal/repository"
+)
+
+type mockAuthService struct {
+	auth.Service
+	claims map[string]*auth.Claims
+}
+
+func (m *mockAuthService) VerifyRequest(token, clientIP string) (*auth.Claims, error) {
+	claims, ok := m.claims[token]
+	if !ok {
+		return nil, auth.ErrInvalidToken
+	}
+	return claims, nil
+}
+
+func (m *mockAuthService) VerifyCSRFToken(sessionID, csrfToken string) bool {
+	return csrfToken == "csrf-"+sessionID
+}
+
+func (m *mockAuthService) GetUserByID(userID string) (*model.User, error) {
+	return &model.User{Email: "ada@example.com"}, nil
+}
+
+type mockOrganizationService struct {
+	organization.Service
+	org    model.Organization
+	access map[uuid.UUID]*organization.Access
+}
+
+func (m *mockOrganizationService) GetOrganizationBySlug(slug string) (*model.Organization, error) {
+	if slug != m.org.Slug {
+		return nil, repository.ErrOrganizationNotFound
+	}
+	return &m.org, nil
+}
+
+func (m *mockOrganizationService) EffectiveAccess(orgID, userID uuid.UUID) (*organization.Access, error) {
+	access, ok := m.access[userID]
+	if !ok || orgID != m.org.ID {
+		return nil, organization.ErrNotMember
+	}
+	return access, nil
+}
+
+type fixture struct {
+	router     *gin.Engine
+	auth       *mockAuthService
+	org        model.Organization
+	adminID    uuid.UUID
+	memberID   uuid.UUID
+	outsiderID uuid.UUID
+}
+
+func newFixture() *fixture {
+	gin.SetMode(gin.TestMode)
+	f := &fixture{
+		org:        model.Organization{ID: uuid.New(), Slug: "acme"},
+		adminID:    uuid.New(),
+		memberID:   uuid.New(),
+		outsiderID: uuid.New(),
+	}
+	f.auth = &mockAuthService{claims: map[string]*auth.Claims{}}
+	orgService := &mockOrganizationService{org: f.org, access: map[uuid.UUID]*organization.Access{
+		f.adminID:  newAccess(rbac.RoleAdmin),
+		f.memberID: newAccess(rbac.RoleMember),
+	}}
+
+	f.router = gin.New()
+	f.router.Any("/forward-auth", NewHandler(NewService(f.auth, orgService), "r7_token", "X-SSL-Client-Cert").ForwardAuth)
+	return f
+}
+
+func newAccess(role rbac.Role) *organization.Access {
+	return &organization.Access{
+		Role:        string(role),
+		Teams:       []model.Team{{Name: "platform", Role: string(rbac.RoleMember)}},
+		Permissions: role.Permissions(),
+	}
+}
+
+func (f *fixture) token(claims *auth.Claims) string {
+	token := uuid.NewString()
+	f.auth.claims[token] = claims
+	return token
+}
+
+func (f *fixture) do(method, target string, prepare func(*http.Request)) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, nil)
+	if prepare != nil {
+		prepare(req)
+	}
+	w := httptest.NewRecorder()
+	f.router.ServeHTTP(w, req)
+	return w
+}
+
+func bearer(token string) func(*http.Request) {
+	return func(req *http.Request) { req.Header.Set("Authorization", "Bearer "+token) }
+}
+
+func TestForwardAuth_BearerToken(t *testing.T) {
+	f := newFixture()
+	token := f.token(&auth.Claims{Subject: f.adminID.String(), SubjectType: auth.SubjectUser})
+
+	w := f.do(http.MethodGet, "/forward-auth?org=acme&permission=audit:read", bearer(token))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, f.adminID.String(), w.Header().Get(HeaderUserID))
+	assert.Equal(t, "ada@example.com", w.Header().Get(HeaderEmail))
+	assert.Equal(t, f.org.ID.String(), w.Header().Get(HeaderOrgID))
+	assert.Equal(t, "acme", w.Header().Get(HeaderOrgSlug))
+	assert.Equal(t, "admin,member", w.Header().Get(HeaderRoles))
+}
+
+func TestForwardAuth_Cookie(t *testing.T) {
+	f := newFixture()
+	token := f.token(&auth.Claims{Subject: f.memberID.String(), SubjectType: auth.SubjectUser})
+
+	w := f.do(http.MethodGet, "/forward-auth", func(req *http.Request) {
+		req.AddCookie(&http.Cookie{Name: "r7_token", Value: token})
+	})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, f.memberID.String(), w.Header().Get(HeaderUserID))
+	assert.Empty(t, w.Header().Get(HeaderOrgID))
+}
+
+func TestForwardAuth_CookieNeedsCSRFTokenForUnsafeMethods(t *testing.T) {
+	f := newFixture()
+	token := f.token(&auth.Claims{Subject: f.memberID.String(), SubjectType: auth.SubjectUser, SessionID: "session-1"})
+	cookie := func(method, csrfToken string) func(*http.Request) {
+		return func(req *http.Request) {
+			req.Header.Set("X-Forwarded-Method", method)
+			req.AddCookie(&http.Cookie{Name: "r7_token", Value: token})
+			if csrfToken != "" {
+				req.Header.Set(auth.CSRFHeader, csrfToken)
+			}
+		}
+	}
+
+	assert.Equal(t, http.StatusOK, f.do(http.MethodGet, "/forward-auth", cookie(http.MethodGet, "")).Code)
+	assert.Equal(t, http.StatusForbidden, f.do(http.MethodGet, "/forward-auth", cookie(http.MethodPost, "")).Code)
+	assert.Equal(t, http.StatusForbidden, f.do(http.MethodGet, "/forward-auth", cookie(http.MethodDelete, "csrf-other")).Code)
+	assert.Equal(t, http.StatusOK, f.do(http.MethodGet, "/forward-auth", cookie(http.MethodPost, "csrf-session-1")).Code)
+
+	// Bearer tokens are not sent by browsers on their own.
+	w := f.do(http.MethodGet, "/forward-auth", func(req *http.Request) {
+		req.Header.Set("X-Forwarded-Method", http.MethodPost)
+		bearer(token)(req)
+	})
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestForwardAuth_Statuses(t *testing.T) {
+	f := newFixture()
+	member := f.token(&auth.Claims{Subject: f.memberID.String(), SubjectType: auth.SubjectUser})
+	outsider := f.token(&auth.Claims{Subject: f.outsiderID.String(), SubjectType: auth.SubjectUser})
+	readOnly := f.token(&auth.Claims{
+		Subject:     f.adminID.String(),
+		SubjectType: auth.SubjectUser,
+		TokenID:     uuid.NewString(),
+		Scopes:      []string{auth.ScopeAPIRead},
+	})
+	audience := f.token(&auth.Claims{Subject: f.memberID.String(), SubjectType: auth.SubjectUser, Audience: "billing"})
+	delegated := f.token(&auth.Claims{
+		Subject:     f.memberID.String(),
+		SubjectType: auth.SubjectUser,
+		Actor:       &auth.Actor{Subject: "gateway", ClientID: "gateway"},
+	})
+
+	for name, tc := range map[string]struct {
+		target  string
+		prepare func(*http.Request)
+		status  int
+	}{
+		"no token":                  {"/forward-auth", nil, http.StatusUnauthorized},
+		"invalid token":             {"/forward-auth", bearer("nope"), http.StatusUnauthorized},
+		"missing permission":        {"/forward-auth?org=acme&permission=audit:read", bearer(member), http.StatusForbidden},
+		"not a member":              {"/forward-auth?org=acme", bearer(outsider), http.StatusForbidden},
+		"unknown org":               {"/forward-auth?org=nope", bearer(member), http.StatusForbidden},
+		"unknown permission":        {"/forward-auth?org=acme&permission=nope", bearer(member), http.StatusBadRequest},
+		"permission without org":    {"/forward-auth?permission=audit:read", bearer(member), http.StatusBadRequest},
+		"token for another service": {"/forward-auth", bearer(audience), http.StatusUnauthorized},
+		"delegated token":           {"/forward-auth", bearer(delegated), http.StatusUnauthorized},
+		"read-only token writing": {"/forward-auth?org=acme", func(req *http.Request) {
+			bearer(readOnly)(req)
+			req.Header.Set("X-Forwarded-Method", http.MethodPost)
+		}, http.StatusForbidden},
+	} {
+		t.Run(name, func(t *testing.T) {
+			w := f.do(http.MethodGet, tc.target, tc.prepare)
+
+			assert.Equal(t, tc.status, w.Code)
+			assert.Empty(t, w.Header().Get(HeaderUserID))
+		})
+	}
+}
+
+func TestForwardAuth_CertificateBoundToken(t *testing.T) {
+	f := newFixture()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	template := &x509.Certificate{SerialNumber: big.NewInt(1), NotAfter: time.Now().Add(time.Hour)}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.NoError(t, err)
+	token := f.token(&auth.Claims{
+		Subject:               f.memberID.String(),
+		SubjectType:           auth.SubjectUser,
+		CertificateThumbprint: auth.CertificateThumbprint(cert),
+	})
+	escapedPEM := url.PathEscape(string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})))
+
+	w := f.do(http.MethodGet, "/forward-auth", func(req *http.Request) {
+		bearer(token)(req)
+		req.Header.Set("X-SSL-Client-Cert", escapedPEM)
+	})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = f.do(http.MethodGet, "/forward-auth", bearer(token))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
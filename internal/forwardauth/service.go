@@ -0,0 +1,245 @@
+package forwardauth
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"sort"
+
+	"github.com/google/uuid"
+
+	"github.com/radiatus-ai/auth-service/internal/auth"
+	"github.com/radiatus-ai/auth-service/internal/organization"
+	"github.com/radiatus-ai/auth-service/internal/rbac"
+	"github.com/radiatus-ai/auth-service/internal/repository"
+)
+
+var (
+	ErrUnauthenticated   = errors.New("authentication required")
+	ErrForbidden         = errors.New("forbidden")
+	ErrInvalidPermission = errors.New("permission must be a known permission and requires an organization")
+)
+
+// Request is a request a reverse proxy asks about.
+type Request struct {
+	Token    string
+	ClientIP string
+	// FromCookie is set when Token came from the session cookie rather than
+	// the Authorization header. Browsers send the cookie on cross-site
+	// requests too, so unsafe methods then need CSRFToken.
+	FromCookie bool
+	CSRFToken  string
+	// Method is the method of the original request, which decides the scope
+	// a personal access token needs.
+	Method string
+	// Organization is the ID or slug of the organization the protected app
+	// belongs to. Optional unless Permission is set.
+	Organization string
+	// Permission, if set, must be held in Organization.
+	Permission rbac.Permission
+	// DPoPProof is the DPoP proof (RFC 9449) sent with the original request
+	// to URL, which tokens bound to a key must come with.
+	DPoPProof string
+	URL       string
+	// CertificateThumbprint is the thumbprint of the client certificate the
+	// original request was made with, which tokens bound to a certificate
+	// (RFC 8705) must come with.
+	CertificateThumbprint string
+	// Audience is the name of the service the route belongs to. Tokens
+	// exchanged for that audience are accepted; tokens for any other
+	// audience, or delegated ones, never are.
+	Audience string
+}
+
+// Identity is who made the request, passed upstream as headers.
+type Identity struct {
+	Subject          string
+	SubjectType      string
+	Email            string
+	ImpersonatorID   string
+	OrganizationID   string
+	OrganizationSlug string
+	Roles            []string
+}
+
+// Service decides requests for reverse proxies that cannot run the API
+// middleware themselves.
+type Service interface {
+	Authorize(req Request) (*Identity, error)
+}
+
+type service struct {
+	authService auth.Service
+	orgService  organization.Service
+}
+
+func NewService(authService auth.Service, orgService organization.Service) Service {
+	return &service{authService: authService, orgService: orgService}
+}
+
+// Authorize verifies the token the same way AuthMiddleware does and, when an
+// organization is named, resolves the subject's effective access there.
+// Verification failures return an error wrapping ErrUnauthenticated; a valid
+// subject without the required access gets one wrapping ErrForbidden. Account
+// status errors are passed through so the caller can tell them apart.
+func (s *service) Authorize(req Request) (*Identity, error) {
+	if req.Permission != "" && (!req.Permission.Valid() || req.Organization == "") {
+		return nil, ErrInvalidPermission
+	}
+	if req.Token == "" {
+		return nil, ErrUnauthenticated
+	}
+
+	claims, err := s.authService.VerifyRequest(req.Token, req.ClientIP)
+	if err != nil {
+		if auth.IsAccountStatusError(err) || errors.Is(err, auth.ErrAddressNotAllowed) {
+			return nil, err
+		}
+		return nil, ErrUnauthenticated
+	}
+	if (claims.Audience != "" || claims.IsDelegated()) && (req.Audience == "" || claims.Audience != req.Audience) {
+		// Exchanged tokens are only for the service they were issued to.
+		log.Printf("Rejected token of %s issued for another service", claims.Subject)
+		return nil, ErrUnauthenticated
+	}
+	if err := auth.CheckCertificateBinding(claims, req.CertificateThumbprint); err != nil {
+		log.Printf("Rejected token of %s: %v", claims.Subject, err)
+		return nil, ErrUnauthenticated
+	}
+	if claims.KeyThumbprint != "" {
+		jkt, err := s.authService.VerifyDPoPProof(req.DPoPProof, req.Method, req.URL, req.Token)
+		if err != nil || jkt != claims.KeyThumbprint {
+			log.Printf("Rejected DPoP-bound token of %s without a valid proof: %v", claims.Subject, err)
+			return nil, ErrUnauthenticated
+		}
+	}
+	if req.FromCookie && !isSafeMethod(req.Method) && !s.authService.VerifyCSRFToken(claims.SessionID, req.CSRFToken) {
+		log.Printf("Forward auth denied %s %s for %s: missing or invalid CSRF token", req.Method, req.URL, claims.Subject)
+		return nil, ErrForbidden
+	}
+
+	identity := &Identity{
+		Subject:        claims.Subject,
+		SubjectType:    claims.SubjectType,
+		ImpersonatorID: claims.Impersonator(),
+		Roles:          []string{},
+	}
+	if claims.SubjectType == auth.SubjectUser {
+		user, err := s.authService.GetUserByID(claims.Subject)
+		if err != nil {
+			return nil, err
+		}
+		identity.Email = user.Email
+	}
+
+	if claims.IsPersonalAccessToken() && !scopeAllows(claims, req.Method) {
+		return nil, ErrForbidden
+	}
+	if req.Organization == "" {
+		if claims.IsAPIKey() || claims.OrganizationID != "" {
+			// API keys and organization-restricted tokens only make sense
+			// for apps that belong to their organization.
+			return nil, ErrForbidden
+		}
+		return identity, nil
+	}
+
+	orgID, slug, err := s.resolveOrganization(req.Organization)
+	if err != nil {
+		return nil, err
+	}
+	if claims.OrganizationID != "" && claims.OrganizationID != orgID.String() {
+		return nil, ErrForbidden
+	}
+	subjectID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return nil, ErrUnauthenticated
+	}
+	access, err := s.orgService.EffectiveAccess(orgID, subjectID)
+	if errors.Is(err, organization.ErrNotMember) {
+		return nil, ErrForbidden
+	}
+	if err != nil {
+		return nil, err
+	}
+	if req.Permission != "" && !hasPermission(access, req.Permission) {
+		log.Printf("Forward auth denied %s: missing %s in organization %s", claims.Subject, req.Permission, orgID)
+		return nil, ErrForbidden
+	}
+
+	identity.OrganizationID = orgID.String()
+	identity.OrganizationSlug = slug
+	identity.Roles = accessRoles(access)
+	return identity, nil
+}
+
+func (s *service) resolveOrganization(ref string) (uuid.UUID, string, error) {
+	if id, err := uuid.Parse(ref); err == nil {
+		// The slug is only known for organizations looked up by slug; it is
+		// informational and not worth another query.
+		return id, "", nil
+	}
+	org, err := s.orgService.GetOrganizationBySlug(ref)
+	if errors.Is(err, repository.ErrOrganizationNotFound) {
+		return uuid.Nil, "", ErrForbidden
+	}
+	if err != nil {
+		return uuid.Nil, "", err
+	}
+	return org.ID, org.Slug, nil
+}
+
+// scopeAllows applies a personal access token's scopes to the original
+// request method, like AuthMiddleware does for API routes.
+func scopeAllows(claims *auth.Claims, method string) bool {
+	if isSafeMethod(method) {
+		return claims.HasScope(auth.ScopeAPIRead) || claims.HasScope(auth.ScopeAPIWrite)
+	}
+	return claims.HasScope(auth.ScopeAPIWrite)
+}
+
+func isSafeMethod(method string) bool {
+	switch method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	default:
+		return false
+	}
+}
+
+func hasPermission(access *organization.Access, p rbac.Permission) bool {
+	for _, held := range access.Permissions {
+		if held == p {
+			return true
+		}
+	}
+	return false
+}
+
+// accessRoles lists the distinct roles behind access: the direct role, team
+// roles and roles inherited from parent organizations.
+func accessRoles(access *organization.Access) []string {
+	seen := map[string]bool{}
+	add := func(role string) {
+		if role != "" {
+			seen[role] = true
+		}
+	}
+	add(access.Role)
+	for _, team := range access.Teams {
+		add(team.Role)
+	}
+	for _, grant := range access.Inherited {
+		add(grant.Role)
+		for _, team := range grant.Teams {
+			add(team.Role)
+		}
+	}
+
+	roles := make([]string, 0, len(seen))
+	for role := range seen {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+	return roles
+}
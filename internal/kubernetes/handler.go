@@ -0,0 +1,69 @@
+package kubernetes
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/radiatus-ai/auth-service/internal/auth"
+)
+
+type Handler struct {
+	service    Service
+	secret     string
+	clientName string
+}
+
+// NewHandler returns a Handler that only answers the API server: requests
+// must carry secret as a bearer token, or come with a verified client
+// certificate whose common name is clientName. Empty values disable either
+// way, and with both empty every request is refused.
+func NewHandler(service Service, secret, clientName string) *Handler {
+	return &Handler{service: service, secret: secret, clientName: clientName}
+}
+
+// TokenReview is the webhook the Kubernetes API server calls to authenticate
+// bearer tokens (--authentication-token-webhook-config-file). The outcome is
+// always reported in the returned TokenReview's status with a 200; other
+// statuses tell the API server the webhook itself failed.
+func (h *Handler) TokenReview(c *gin.Context) {
+	if !h.fromAPIServer(c) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "The API server must authenticate to the webhook"})
+		return
+	}
+
+	var review TokenReview
+	if err := c.ShouldBindJSON(&review); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if review.APIVersion != APIVersion || review.Kind != Kind {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Expected a " + APIVersion + " " + Kind})
+		return
+	}
+
+	c.JSON(http.StatusOK, TokenReview{
+		APIVersion: APIVersion,
+		Kind:       Kind,
+		Status:     h.service.Review(review.Spec),
+	})
+}
+
+// fromAPIServer reports whether the request comes from the API server.
+// Otherwise anyone could use the webhook to test tokens and read the groups
+// they map to.
+func (h *Handler) fromAPIServer(c *gin.Context) bool {
+	if h.secret != "" {
+		token, dpop, ok := auth.ParseAuthorization(c.GetHeader("Authorization"))
+		if ok && !dpop && subtle.ConstantTimeCompare([]byte(token), []byte(h.secret)) == 1 {
+			return true
+		}
+	}
+	if h.clientName != "" {
+		if cert := auth.ClientCertificate(c.Request); cert != nil && cert.Subject.CommonName == h.clientName {
+			return true
+		}
+	}
+	return false
+}
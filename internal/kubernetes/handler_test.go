@@ -0,0 +1,65 @@
+package kubernetes
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/radiatus-ai/auth-service/internal/auth"
+)
+
+func TestTokenReview_AuthenticatesAPIServer(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	f := newFixture()
+	token := f.token(&auth.Claims{Subject: f.auth.user.ID.String(), SubjectType: auth.SubjectUser})
+	body := `{"apiVersion":"` + APIVersion + `","kind":"` + Kind + `","spec":{"token":"` + token + `"}}`
+	clientCert := func(name string) func(*http.Request) {
+		return func(req *http.Request) {
+			cert := &x509.Certificate{Subject: pkix.Name{CommonName: name}}
+			req.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}
+		}
+	}
+	bearer := func(secret string) func(*http.Request) {
+		return func(req *http.Request) { req.Header.Set("Authorization", "Bearer "+secret) }
+	}
+
+	for name, tc := range map[string]struct {
+		secret, clientName string
+		prepare            func(*http.Request)
+		status             int
+	}{
+		"secret":                 {"webhook-secret", "", bearer("webhook-secret"), http.StatusOK},
+		"wrong secret":           {"webhook-secret", "", bearer("guess"), http.StatusUnauthorized},
+		"no credentials":         {"webhook-secret", "kube-apiserver", nil, http.StatusUnauthorized},
+		"client certificate":     {"", "kube-apiserver", clientCert("kube-apiserver"), http.StatusOK},
+		"another certificate":    {"", "kube-apiserver", clientCert("billing"), http.StatusUnauthorized},
+		"nothing configured":     {"", "", bearer(""), http.StatusUnauthorized},
+		"certificate not wanted": {"webhook-secret", "", clientCert("kube-apiserver"), http.StatusUnauthorized},
+	} {
+		t.Run(name, func(t *testing.T) {
+			router := gin.New()
+			router.POST("/kubernetes/token-review", NewHandler(f.svc, tc.secret, tc.clientName).TokenReview)
+			req := httptest.NewRequest(http.MethodPost, "/kubernetes/token-review", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			if tc.prepare != nil {
+				tc.prepare(req)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.status, w.Code)
+			if tc.status == http.StatusOK {
+				assert.Contains(t, w.Body.String(), `"authenticated":true`)
+			} else {
+				assert.NotContains(t, w.Body.String(), "ada@example.com")
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package kubernetes
+
+import (
+	"errors"
+	"log"
+
+	"github.com/radiatus-ai/auth-service/internal/auth"
+	"github.com/radiatus-ai/auth-service/internal/organization"
+	"github.com/radiatus-ai/auth-service/internal/repository"
+)
+
+const (
+	// ServiceAccountUsernamePrefix marks the usernames of service accounts so
+	// cluster RBAC cannot confuse them with people.
+	ServiceAccountUsernamePrefix = "serviceaccount:"
+	// ExtraSubjectType carries auth.Claims.SubjectType in UserInfo.Extra.
+	ExtraSubjectType = "radiatus.ai/subject-type"
+)
+
+var (
+	ErrAudienceMismatch   = errors.New("token is not valid for the requested audiences")
+	ErrUnsupportedSubject = errors.New("API keys and impersonated tokens cannot be used with Kubernetes")
+	// The API server forwards only the token, so the DPoP proof or client
+	// certificate a bound token needs cannot be checked.
+	ErrBoundToken = errors.New("DPoP- and certificate-bound tokens cannot be used with Kubernetes")
+	// Cluster RBAC only sees groups, which cannot express a read-only token.
+	ErrInsufficientScope = errors.New("personal access tokens need the api:write scope to be used with Kubernetes")
+)
+
+// Service answers Kubernetes TokenReview webhook requests, letting clusters
+// accept this service's tokens for kubectl access.
+type Service interface {
+	Review(spec TokenReviewSpec) TokenReviewStatus
+}
+
+type service struct {
+	authService auth.Service
+	orgService  organization.Service
+	orgRepo     repository.OrganizationRepository
+}
+
+func NewService(authService auth.Service, orgService organization.Service, orgRepo repository.OrganizationRepository) Service {
+	return &service{
+		authService: authService,
+		orgService:  orgService,
+		orgRepo:     orgRepo,
+	}
+}
+
+// Review verifies the token exactly like /api/verify-token and maps its
+// subject to a Kubernetes user: people are named by email and service
+// accounts by client ID, and both get a "<org-id>:<role>" group for each
+// organization they belong to, plus "<org-id>:team:<name>" for each of a
+// person's teams. Groups use the organization's ID because its slug can be
+// changed, and a deleted organization's slug reused, by its admins. A
+// personal access token limited to one organization only carries that
+// organization's groups, and one without the api:write scope is rejected.
+func (s *service) Review(spec TokenReviewSpec) TokenReviewStatus {
+	claims, err := s.authService.VerifyClaims(spec.Token)
+	if err != nil {
+		return TokenReviewStatus{Error: "invalid token: " + auth.ErrorCode(err)}
+	}
+	if claims.IsAPIKey() || claims.IsImpersonated() {
+		return TokenReviewStatus{Error: ErrUnsupportedSubject.Error()}
+	}
+	if claims.KeyThumbprint != "" || claims.CertificateThumbprint != "" {
+		return TokenReviewStatus{Error: ErrBoundToken.Error()}
+	}
+	if claims.IsPersonalAccessToken() && !claims.HasScope(auth.ScopeAPIWrite) {
+		return TokenReviewStatus{Error: ErrInsufficientScope.Error()}
+	}
+
+	audiences, err := reviewAudiences(claims, spec.Audiences)
+	if err != nil {
+		return TokenReviewStatus{Error: err.Error()}
+	}
+
+	var user *UserInfo
+	if claims.IsServiceAccount() {
+		user, err = s.serviceAccountInfo(claims)
+	} else {
+		user, err = s.userInfo(claims)
+	}
+	if err != nil {
+		log.Printf("Failed to review token for %s: %v", claims.Subject, err)
+		return TokenReviewStatus{Error: "failed to resolve user"}
+	}
+
+	log.Printf("TokenReview authenticated %s as %s", claims.Subject, user.Username)
+	return TokenReviewStatus{Authenticated: true, User: *user, Audiences: audiences}
+}
+
+func (s *service) userInfo(claims *auth.Claims) (*UserInfo, error) {
+	user, err := s.authService.GetUserByID(claims.Subject)
+	if err != nil {
+		return nil, err
+	}
+	orgs, err := s.orgRepo.GetUserOrganizations(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := []string{}
+	for _, org := range orgs {
+		if claims.OrganizationID != "" && org.ID.String() != claims.OrganizationID {
+			continue
+		}
+		access, err := s.orgService.EffectiveAccess(org.ID, user.ID)
+		if errors.Is(err, organization.ErrNotMember) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, org.ID.String()+":"+access.Role)
+		for _, team := range access.Teams {
+			groups = append(groups, org.ID.String()+":team:"+team.Name)
+		}
+	}
+
+	return &UserInfo{
+		Username: user.Email,
+		UID:      claims.Subject,
+		Groups:   groups,
+		Extra:    map[string][]string{ExtraSubjectType: {claims.SubjectType}},
+	}, nil
+}
+
+func (s *service) serviceAccountInfo(claims *auth.Claims) (*UserInfo, error) {
+	account, err := s.authService.GetServiceAccountByID(claims.Subject)
+	if err != nil {
+		return nil, err
+	}
+	// The organization must still exist for its service accounts to act.
+	org, err := s.orgRepo.GetByID(account.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &UserInfo{
+		Username: ServiceAccountUsernamePrefix + account.ClientID,
+		UID:      claims.Subject,
+		Groups:   []string{org.ID.String() + ":" + account.Role},
+		Extra:    map[string][]string{ExtraSubjectType: {claims.SubjectType}},
+	}, nil
+}
+
+// reviewAudiences implements the audience contract of TokenReview: a token
+// bound to audiences must share one with the request, and the response
+// lists the requested audiences it is valid for. Tokens without an audience,
+// such as login tokens, are valid for any. Tokens issued to another service
+// by token exchange, and delegated ones, are only valid when the request
+// names their audience.
+func reviewAudiences(claims *auth.Claims, requested []string) ([]string, error) {
+	if claims.Audience == "" && !claims.IsDelegated() {
+		return requested, nil
+	}
+	for _, audience := range requested {
+		if claims.Audience != "" && audience == claims.Audience {
+			return []string{audience}, nil
+		}
+	}
+	return nil, ErrAudienceMismatch
+}
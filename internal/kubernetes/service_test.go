@@ -0,0 +1,212 @@
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/radiatus-ai/auth-service/internal/auth"
+	"github.com/radiatus-ai/auth-service/internal/model"
+	"github.com/radiatus-ai/auth-service/internal/organization"
+	"github.com/radiatus-ai/auth-service/internal/repository"
+)
+
+type mockAuthService struct {
+	auth.Service
+	claims  map[string]*auth.Claims
+	user    *model.User
+	account *model.ServiceAccount
+}
+
+func (m *mockAuthService) VerifyClaims(token string) (*auth.Claims, error) {
+	claims, ok := m.claims[token]
+	if !ok {
+		return nil, auth.ErrInvalidToken
+	}
+	return claims, nil
+}
+
+func (m *mockAuthService) GetUserByID(userID string) (*model.User, error) {
+	return m.user, nil
+}
+
+func (m *mockAuthService) GetServiceAccountByID(id string) (*model.ServiceAccount, error) {
+	return m.account, nil
+}
+
+type mockOrganizationService struct {
+	organization.Service
+	access map[uuid.UUID]*organization.Access
+}
+
+func (m *mockOrganizationService) EffectiveAccess(orgID, userID uuid.UUID) (*organization.Access, error) {
+	access, ok := m.access[orgID]
+	if !ok {
+		return nil, organization.ErrNotMember
+	}
+	return access, nil
+}
+
+type mockOrganizationRepository struct {
+	repository.OrganizationRepository
+	orgs []model.Organization
+}
+
+func (m *mockOrganizationRepository) GetUserOrganizations(userID uuid.UUID) ([]model.Organization, error) {
+	return m.orgs, nil
+}
+
+func (m *mockOrganizationRepository) GetByID(id uuid.UUID) (*model.Organization, error) {
+	for i := range m.orgs {
+		if m.orgs[i].ID == id {
+			return &m.orgs[i], nil
+		}
+	}
+	return nil, repository.ErrOrganizationNotFound
+}
+
+type fixture struct {
+	svc        *service
+	auth       *mockAuthService
+	acme, labs model.Organization
+}
+
+func newFixture() *fixture {
+	f := &fixture{
+		acme: model.Organization{ID: uuid.New(), Slug: "acme"},
+		labs: model.Organization{ID: uuid.New(), Slug: "labs"},
+	}
+	user := &model.User{ID: uuid.New(), Email: "ada@example.com"}
+	f.auth = &mockAuthService{
+		claims: map[string]*auth.Claims{},
+		user:   user,
+		account: &model.ServiceAccount{
+			ID:             uuid.New(),
+			OrganizationID: f.acme.ID,
+			ClientID:       "sa_deployer",
+			Role:           "admin",
+		},
+	}
+	orgService := &mockOrganizationService{access: map[uuid.UUID]*organization.Access{
+		f.acme.ID: {Role: "admin", Teams: []model.Team{{Name: "platform"}}},
+		f.labs.ID: {Role: "member", Teams: []model.Team{}},
+	}}
+	f.svc = &service{
+		authService: f.auth,
+		orgService:  orgService,
+		orgRepo:     &mockOrganizationRepository{orgs: []model.Organization{f.acme, f.labs}},
+	}
+	return f
+}
+
+func (f *fixture) token(claims *auth.Claims) string {
+	token := uuid.NewString()
+	f.auth.claims[token] = claims
+	return token
+}
+
+func TestReview_User(t *testing.T) {
+	f := newFixture()
+	token := f.token(&auth.Claims{Subject: f.auth.user.ID.String(), SubjectType: auth.SubjectUser})
+
+	status := f.svc.Review(TokenReviewSpec{Token: token})
+
+	assert.True(t, status.Authenticated)
+	assert.Equal(t, "ada@example.com", status.User.Username)
+	assert.Equal(t, f.auth.user.ID.String(), status.User.UID)
+	acme, labs := f.acme.ID.String(), f.labs.ID.String()
+	assert.Equal(t, []string{acme + ":admin", acme + ":team:platform", labs + ":member"}, status.User.Groups)
+	assert.Equal(t, []string{auth.SubjectUser}, status.User.Extra[ExtraSubjectType])
+}
+
+func TestReview_OrganizationRestrictedToken(t *testing.T) {
+	f := newFixture()
+	token := f.token(&auth.Claims{
+		Subject:        f.auth.user.ID.String(),
+		SubjectType:    auth.SubjectUser,
+		TokenID:        uuid.NewString(),
+		OrganizationID: f.labs.ID.String(),
+	})
+
+	status := f.svc.Review(TokenReviewSpec{Token: token})
+
+	assert.True(t, status.Authenticated)
+	assert.Equal(t, []string{f.labs.ID.String() + ":member"}, status.User.Groups)
+}
+
+func TestReview_ServiceAccount(t *testing.T) {
+	f := newFixture()
+	token := f.token(&auth.Claims{Subject: f.auth.account.ID.String(), SubjectType: auth.SubjectServiceAccount})
+
+	status := f.svc.Review(TokenReviewSpec{Token: token})
+
+	assert.True(t, status.Authenticated)
+	assert.Equal(t, "serviceaccount:sa_deployer", status.User.Username)
+	assert.Equal(t, []string{f.acme.ID.String() + ":admin"}, status.User.Groups)
+}
+
+func TestReview_Audiences(t *testing.T) {
+	f := newFixture()
+	login := f.token(&auth.Claims{Subject: f.auth.user.ID.String()})
+	bound := f.token(&auth.Claims{Subject: f.auth.user.ID.String(), Audience: "billing"})
+
+	status := f.svc.Review(TokenReviewSpec{Token: login, Audiences: []string{"https://kubernetes.default.svc"}})
+	assert.True(t, status.Authenticated)
+	assert.Equal(t, []string{"https://kubernetes.default.svc"}, status.Audiences)
+
+	status = f.svc.Review(TokenReviewSpec{Token: bound, Audiences: []string{"https://kubernetes.default.svc"}})
+	assert.False(t, status.Authenticated)
+	assert.Equal(t, ErrAudienceMismatch.Error(), status.Error)
+
+	status = f.svc.Review(TokenReviewSpec{Token: bound, Audiences: []string{"https://kubernetes.default.svc", "billing"}})
+	assert.True(t, status.Authenticated)
+	assert.Equal(t, []string{"billing"}, status.Audiences)
+
+	delegated := f.token(&auth.Claims{
+		Subject:  f.auth.user.ID.String(),
+		Audience: "billing",
+		Actor:    &auth.Actor{Subject: "gateway", ClientID: "gateway"},
+	})
+	delegatedWithoutAudience := f.token(&auth.Claims{
+		Subject: f.auth.user.ID.String(),
+		Actor:   &auth.Actor{Subject: "gateway", ClientID: "gateway"},
+	})
+	for name, token := range map[string]string{"audience": bound, "delegated": delegated} {
+		status = f.svc.Review(TokenReviewSpec{Token: token})
+		assert.False(t, status.Authenticated, "%s token without spec.audiences", name)
+		assert.Equal(t, ErrAudienceMismatch.Error(), status.Error, name)
+	}
+	status = f.svc.Review(TokenReviewSpec{Token: delegatedWithoutAudience, Audiences: []string{"https://kubernetes.default.svc"}})
+	assert.False(t, status.Authenticated)
+}
+
+func TestReview_Rejections(t *testing.T) {
+	f := newFixture()
+	impersonated := f.token(&auth.Claims{
+		Subject: f.auth.user.ID.String(),
+		Actor:   &auth.Actor{Subject: uuid.NewString()},
+	})
+	apiKey := f.token(&auth.Claims{Subject: uuid.NewString(), SubjectType: auth.SubjectAPIKey})
+	readOnly := f.token(&auth.Claims{
+		Subject:     f.auth.user.ID.String(),
+		SubjectType: auth.SubjectUser,
+		TokenID:     uuid.NewString(),
+		Scopes:      []string{auth.ScopeAPIRead},
+	})
+
+	for name, token := range map[string]string{
+		"invalid":       "not-a-token",
+		"impersonated":  impersonated,
+		"api key":       apiKey,
+		"read-only PAT": readOnly,
+	} {
+		t.Run(name, func(t *testing.T) {
+			status := f.svc.Review(TokenReviewSpec{Token: token})
+
+			assert.False(t, status.Authenticated)
+			assert.NotEmpty(t, status.Error)
+			assert.Empty(t, status.User.Username)
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/radiatus-ai/auth-service/internal/auth"
+)
+
+// RequireSuperadmin only lets through users holding the superadmin platform
+// role, and never impersonated requests or personal access tokens. It must
+// run after AuthMiddleware.
+func RequireSuperadmin(authService auth.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("impersonator_id") != "" {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Admin routes cannot be used while impersonating"})
+			c.Abort()
+			return
+		}
+		if claims, ok := c.Get("claims"); ok && claims.(*auth.Claims).IsPersonalAccessToken() {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Admin routes cannot be used with a personal access token"})
+			c.Abort()
+			return
+		}
+
+		user, err := authService.GetUserByID(c.GetString("user_id"))
+		if err != nil || !user.IsSuperadmin() {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Superadmin access required"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/radiatus-ai/auth-service/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+type platformRoleAuthService struct {
+	mockAuthService
+	platformRole string
+}
+
+func (m *platformRoleAuthService) GetUserByID(userID string) (*model.User, error) {
+	return &model.User{PlatformRole: m.platformRole}, nil
+}
+
+func TestRequireSuperadmin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	for name, tc := range map[string]struct {
+		platformRole string
+		want         int
+	}{
+		"Superadmin":   {platformRole: model.PlatformRoleSuperadmin, want: http.StatusOK},
+		"Regular user": {platformRole: "", want: http.StatusForbidden},
+	} {
+		t.Run(name, func(t *testing.T) {
+			service := &platformRoleAuthService{platformRole: tc.platformRole}
+			w := httptest.NewRecorder()
+			c, r := gin.CreateTestContext(w)
+			r.Use(AuthMiddleware(service, nil), RequireSuperadmin(service))
+			r.GET("/admin/users", func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			c.Request, _ = http.NewRequest(http.MethodGet, "/admin/users", nil)
+			c.Request.Header.Set("Authorization", "Bearer valid_token")
+			r.ServeHTTP(w, c.Request)
+
+			assert.Equal(t, tc.want, w.Code)
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/radiatus-ai/auth-service/internal/audit"
+	"github.com/radiatus-ai/auth-service/internal/auth"
+)
+
+// APIKeyAudit records an audit event for every change made with an
+// organization API key, naming the key as the actor. Reads are not recorded;
+// the key's last-used time covers them. It must run after AuthMiddleware.
+func APIKeyAudit(auditLog audit.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		value, _ := c.Get("claims")
+		claims, ok := value.(*auth.Claims)
+		if !ok || !claims.IsAPIKey() || isSafeMethod(c.Request.Method) {
+			return
+		}
+		keyID, err := uuid.Parse(claims.Subject)
+		if err != nil {
+			return
+		}
+		orgID, err := uuid.Parse(claims.OrganizationID)
+		if err != nil {
+			return
+		}
+
+		event := audit.APIKeyEvent(keyID, orgID, audit.ActionAPIKeyRequest, "organization", c.Param("orgID"))
+		event.Metadata["method"] = c.Request.Method
+		event.Metadata["path"] = c.FullPath()
+		event.Metadata["status"] = c.Writer.Status()
+		event.Metadata["ip"] = c.ClientIP()
+		auditLog.Record(event)
+	}
+}
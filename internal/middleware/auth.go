@@ -1,37 +1,185 @@
 package middleware
 
 import (
+	"errors"
+	"log"
 	"net/http"
-	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/radiatus-ai/auth-service/internal/auth"
 )
 
-func AuthMiddleware(authService auth.Service) gin.HandlerFunc {
+// AuthMiddleware authenticates requests with a bearer token in the
+// Authorization header or, failing that, with a session cookie. sessions may
+// be nil to accept bearer tokens only.
+//
+// Tokens bound to a DPoP key must be presented with the DPoP scheme (or in
+// the session cookie) along with a proof signed by that key, and tokens bound
+// to a client certificate over a TLS connection authenticated with it.
+// Tokens issued to other services by token exchange are refused.
+//
+// Cookies are sent by the browser whatever page started the request, so
+// unsafe requests authenticated with a cookie must also carry the session's
+// CSRF token in the X-CSRF-Token header. Active cookie sessions are refreshed
+// once past half of their lifetime.
+func AuthMiddleware(authService auth.Service, sessions *auth.SessionCookies) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
+		var token string
+		fromCookie, dpopScheme := false, false
+		if authHeader := c.GetHeader("Authorization"); authHeader != "" {
+			var ok bool
+			token, dpopScheme, ok = auth.ParseAuthorization(authHeader)
+			if !ok {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authorization header format"})
+				c.Abort()
+				return
+			}
+		} else if sessions != nil {
+			token = sessions.Token(c)
+			fromCookie = token != ""
+		}
+		if token == "" {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header is required"})
 			c.Abort()
 			return
 		}
 
-		bearerToken := strings.Split(authHeader, " ")
-		if len(bearerToken) != 2 || strings.ToLower(bearerToken[0]) != "bearer" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authorization header format"})
+		claims, err := authService.VerifyRequest(token, c.ClientIP())
+		if err != nil {
+			status := http.StatusUnauthorized
+			if auth.IsAccountStatusError(err) || errors.Is(err, auth.ErrAddressNotAllowed) {
+				status = http.StatusForbidden
+			}
+			c.JSON(status, gin.H{"error": "Invalid or expired token", "code": auth.ErrorCode(err)})
 			c.Abort()
 			return
 		}
 
-		userID, err := authService.VerifyToken(bearerToken[1])
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+		if claims.Audience != "" || claims.IsDelegated() {
+			// Exchanged tokens are downscoped for one backend, which must
+			// not be able to replay them here with the user's full access.
+			log.Printf("Rejected token of %s issued for audience %q", claims.Subject, claims.Audience)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Token was issued for another service", "code": auth.CodeInvalidToken})
+			c.Abort()
+			return
+		}
+
+		if err := auth.CheckCertificateBinding(claims, auth.ClientCertificateThumbprint(c.Request)); err != nil {
+			log.Printf("Rejected certificate-bound token of %s: %v", claims.Subject, err)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Token is bound to a client certificate this request was not made with", "code": auth.CodeInvalidToken})
 			c.Abort()
 			return
 		}
 
-		c.Set("user_id", userID)
+		if claims.KeyThumbprint != "" || dpopScheme {
+			if msg := dpopDenial(c, authService, token, claims, dpopScheme || fromCookie); msg != "" {
+				c.Header("WWW-Authenticate", `DPoP error="invalid_dpop_proof", algs="ES256 RS256"`)
+				c.JSON(http.StatusUnauthorized, gin.H{"error": msg, "code": "invalid_dpop_proof"})
+				c.Abort()
+				return
+			}
+		}
+
+		if fromCookie {
+			if !isSafeMethod(c.Request.Method) &&
+				!authService.VerifyCSRFToken(claims.SessionID, c.GetHeader(auth.CSRFHeader)) {
+				c.JSON(http.StatusForbidden, gin.H{"error": "Missing or invalid CSRF token", "code": "invalid_csrf_token"})
+				c.Abort()
+				return
+			}
+			refreshSession(c, authService, sessions, claims)
+		}
+
+		if claims.IsPersonalAccessToken() {
+			if msg := personalAccessTokenDenial(c, claims); msg != "" {
+				c.JSON(http.StatusForbidden, gin.H{"error": msg, "code": "insufficient_scope"})
+				c.Abort()
+				return
+			}
+		}
+
+		if claims.IsAPIKey() && c.Param("orgID") == "" {
+			c.JSON(http.StatusForbidden, gin.H{"error": "API keys can only be used on organization routes"})
+			c.Abort()
+			return
+		}
+
+		// user_id is the subject whatever its type; subject_type tells people
+		// (auth.SubjectUser) from machines (auth.SubjectServiceAccount and
+		// auth.SubjectAPIKey).
+		c.Set("user_id", claims.Subject)
+		c.Set("subject_type", claims.SubjectType)
+		c.Set("claims", claims)
+		if claims.IsImpersonated() {
+			c.Set("impersonator_id", claims.Impersonator())
+			log.Printf("Impersonated request %s %s: user %s acting as user %s",
+				c.Request.Method, c.Request.URL.Path, claims.Impersonator(), claims.Subject)
+		}
 		c.Next()
 	}
 }
+
+// dpopDenial checks the DPoP proof of a request presenting a DPoP-bound
+// token, or using the DPoP scheme. It returns why the request is denied, or
+// "" if it is allowed.
+func dpopDenial(c *gin.Context, authService auth.Service, token string, claims *auth.Claims, proofExpected bool) string {
+	if claims.KeyThumbprint == "" {
+		return "Token is not bound to a DPoP key"
+	}
+	if !proofExpected {
+		return "DPoP-bound tokens must be presented with the DPoP scheme"
+	}
+	jkt, err := authService.VerifyDPoPProof(c.GetHeader(auth.DPoPHeader), c.Request.Method, auth.RequestURL(c.Request), token)
+	if err != nil {
+		log.Printf("Rejected DPoP proof for %s: %v", claims.Subject, err)
+		return "Invalid DPoP proof"
+	}
+	if jkt != claims.KeyThumbprint {
+		return "DPoP proof is not signed with the key the token is bound to"
+	}
+	return ""
+}
+
+// refreshSession extends a cookie session that is past half of its lifetime.
+// A failed refresh is not fatal: the current token is still valid.
+func refreshSession(c *gin.Context, authService auth.Service, sessions *auth.SessionCookies, claims *auth.Claims) {
+	token, expiresAt, err := authService.RefreshSession(claims)
+	if err != nil {
+		log.Printf("Failed to refresh session for user %s: %v", claims.Subject, err)
+		return
+	}
+	if token != "" {
+		sessions.Set(c, token, authService.CSRFToken(claims.SessionID), expiresAt)
+	}
+}
+
+// personalAccessTokenDenial enforces the limits a user put on a personal
+// access token: its scopes, checked against the HTTP method, and its
+// organization, checked against the route's orgID parameter. A token limited
+// to one organization cannot make changes outside of it. It returns why the
+// request is denied, or "" if it is allowed.
+func personalAccessTokenDenial(c *gin.Context, claims *auth.Claims) string {
+	write := !isSafeMethod(c.Request.Method)
+	if write && !claims.HasScope(auth.ScopeAPIWrite) {
+		return "Token does not have the api:write scope"
+	}
+	if !write && !claims.HasScope(auth.ScopeAPIRead) && !claims.HasScope(auth.ScopeAPIWrite) {
+		return "Token does not have the api:read scope"
+	}
+
+	if claims.OrganizationID == "" {
+		return ""
+	}
+	orgID := c.Param("orgID")
+	if orgID != "" && orgID != claims.OrganizationID {
+		return "Token is restricted to another organization"
+	}
+	if orgID == "" && write {
+		return "Token is restricted to a single organization"
+	}
+	return ""
+}
+
+func isSafeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
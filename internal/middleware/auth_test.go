@@ -1,14 +1,20 @@
 package middleware
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/radiatus-ai/auth-service/internal/auth"
 	"github.com/radiatus-ai/auth-service/internal/model"
+	"github.com/radiatus-ai/auth-service/internal/repository"
+	"github.com/radiatus-ai/auth-service/pkg/jwks"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -29,15 +35,93 @@ func (m *mockAuthService) VerifyToken(token string) (string, error) {
 	return "", auth.ErrInvalidToken
 }
 
+func (m *mockAuthService) VerifyClaims(token string) (*auth.Claims, error) {
+	if token == "bound_token" {
+		return &auth.Claims{Subject: "user_123", KeyThumbprint: "key_123"}, nil
+	}
+	if token == "cert_bound_token" {
+		return &auth.Claims{Subject: "user_123", CertificateThumbprint: auth.CertificateThumbprint(testClientCertificate)}, nil
+	}
+	userID, err := m.VerifyToken(token)
+	if err != nil {
+		return nil, err
+	}
+	return &auth.Claims{Subject: userID}, nil
+}
+
+func (m *mockAuthService) VerifyRequest(token, clientIP string) (*auth.Claims, error) {
+	return m.VerifyClaims(token)
+}
+
+func (m *mockAuthService) IssueImpersonationToken(adminID, userID uuid.UUID) (string, time.Time, error) {
+	return "", time.Time{}, nil
+}
+
+func (m *mockAuthService) IssueToken(claims *auth.Claims) (string, error) {
+	return "", nil
+}
+
+func (m *mockAuthService) JWKS() jwks.Set {
+	return jwks.Set{}
+}
+
+func (m *mockAuthService) CSRFToken(sessionID string) string {
+	return "csrf-" + sessionID
+}
+
+func (m *mockAuthService) VerifyCSRFToken(sessionID, csrfToken string) bool {
+	return sessionID != "" && csrfToken == m.CSRFToken(sessionID)
+}
+
+func (m *mockAuthService) RefreshSession(claims *auth.Claims) (string, time.Time, error) {
+	return "", time.Time{}, nil
+}
+
+func (m *mockAuthService) GetServiceAccountByID(accountID string) (*model.ServiceAccount, error) {
+	return nil, repository.ErrServiceAccountNotFound
+}
+
 func (m *mockAuthService) GetUserByID(userID string) (*model.User, error) {
 	id, _ := uuid.Parse(userID)
 	return &model.User{ID: id}, nil
 }
 
-func (m *mockAuthService) LoginGoogle(code string) (*auth.UserData, error) {
+func (m *mockAuthService) LoginGoogle(code string, client auth.ClientInfo) (*auth.UserData, error) {
 	return &auth.UserData{}, nil
 }
 
+func (m *mockAuthService) EndSession(token string) error {
+	return nil
+}
+
+func (m *mockAuthService) Reauthenticate(claims *auth.Claims, googleToken string) (string, time.Time, error) {
+	return "", time.Time{}, auth.ErrInvalidIdentityToken
+}
+
+func (m *mockAuthService) RefreshAccessToken(refreshToken, keyThumbprint string) (*auth.SessionTokens, error) {
+	return nil, auth.ErrInvalidToken
+}
+
+// VerifyDPoPProof accepts proofs of the form "proof:<thumbprint>".
+func (m *mockAuthService) VerifyDPoPProof(proof, method, url, accessToken string) (string, error) {
+	if jkt, ok := strings.CutPrefix(proof, "proof:"); ok {
+		return jkt, nil
+	}
+	return "", auth.ErrInvalidDPoPProof
+}
+
+func (m *mockAuthService) IssueReferenceToken(claims *auth.Claims, clientID string) (string, error) {
+	return "", nil
+}
+
+func (m *mockAuthService) RevokeReferenceToken(token, clientID string) error {
+	return nil
+}
+
+func (m *mockAuthService) PurgeExpiredReferenceTokens() (int64, error) {
+	return 0, nil
+}
+
 func TestAuthMiddleware(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	mockService := &mockAuthService{}
@@ -45,7 +129,7 @@ func TestAuthMiddleware(t *testing.T) {
 	t.Run("Valid token", func(t *testing.T) {
 		w := httptest.NewRecorder()
 		c, r := gin.CreateTestContext(w)
-		r.Use(AuthMiddleware(mockService))
+		r.Use(AuthMiddleware(mockService, nil))
 		r.GET("/test", func(c *gin.Context) {
 			userID, exists := c.Get("user_id")
 			assert.True(t, exists)
@@ -63,7 +147,7 @@ func TestAuthMiddleware(t *testing.T) {
 	t.Run("Invalid token", func(t *testing.T) {
 		w := httptest.NewRecorder()
 		c, r := gin.CreateTestContext(w)
-		r.Use(AuthMiddleware(mockService))
+		r.Use(AuthMiddleware(mockService, nil))
 		r.GET("/test", func(c *gin.Context) {
 			c.Status(http.StatusOK)
 		})
@@ -78,7 +162,7 @@ func TestAuthMiddleware(t *testing.T) {
 	t.Run("Missing Authorization header", func(t *testing.T) {
 		w := httptest.NewRecorder()
 		c, r := gin.CreateTestContext(w)
-		r.Use(AuthMiddleware(mockService))
+		r.Use(AuthMiddleware(mockService, nil))
 		r.GET("/test", func(c *gin.Context) {
 			c.Status(http.StatusOK)
 		})
@@ -89,3 +173,255 @@ func TestAuthMiddleware(t *testing.T) {
 		assert.Equal(t, http.StatusUnauthorized, w.Code)
 	})
 }
+
+func TestAuthMiddleware_DPoP(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	serve := func(authorization, proof string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, r := gin.CreateTestContext(w)
+		r.Use(AuthMiddleware(&mockAuthService{}, nil))
+		r.GET("/test", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+		c.Request, _ = http.NewRequest(http.MethodGet, "/test", nil)
+		c.Request.Header.Set("Authorization", authorization)
+		if proof != "" {
+			c.Request.Header.Set(auth.DPoPHeader, proof)
+		}
+		r.ServeHTTP(w, c.Request)
+		return w
+	}
+
+	assert.Equal(t, http.StatusOK, serve("DPoP bound_token", "proof:key_123").Code)
+
+	t.Run("bound tokens need a proof", func(t *testing.T) {
+		w := serve("DPoP bound_token", "")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Header().Get("WWW-Authenticate"), `error="invalid_dpop_proof"`)
+	})
+
+	t.Run("proof must be signed with the bound key", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, serve("DPoP bound_token", "proof:other_key").Code)
+	})
+
+	t.Run("bound tokens cannot be used as bearer tokens", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, serve("Bearer bound_token", "proof:key_123").Code)
+	})
+
+	t.Run("DPoP scheme needs a bound token", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, serve("DPoP valid_token", "proof:key_123").Code)
+	})
+}
+
+var testClientCertificate = &x509.Certificate{Raw: []byte("client certificate")}
+
+func TestAuthMiddleware_CertificateBoundToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	serve := func(cert *x509.Certificate) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, r := gin.CreateTestContext(w)
+		r.Use(AuthMiddleware(&mockAuthService{}, nil))
+		r.GET("/test", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+		c.Request, _ = http.NewRequest(http.MethodGet, "/test", nil)
+		c.Request.Header.Set("Authorization", "Bearer cert_bound_token")
+		if cert != nil {
+			c.Request.TLS = &tls.ConnectionState{
+				PeerCertificates: []*x509.Certificate{cert},
+				VerifiedChains:   [][]*x509.Certificate{{cert}},
+			}
+		}
+		r.ServeHTTP(w, c.Request)
+		return w
+	}
+
+	assert.Equal(t, http.StatusOK, serve(testClientCertificate).Code)
+	assert.Equal(t, http.StatusUnauthorized, serve(nil).Code)
+	assert.Equal(t, http.StatusUnauthorized, serve(&x509.Certificate{Raw: []byte("other certificate")}).Code)
+}
+
+type patAuthService struct {
+	mockAuthService
+	claims *auth.Claims
+}
+
+func (m *patAuthService) VerifyRequest(token, clientIP string) (*auth.Claims, error) {
+	return m.claims, nil
+}
+
+// allowlistAuthService accepts only requests from 10.0.0.1, like an API key
+// with an allowlist.
+type allowlistAuthService struct {
+	mockAuthService
+}
+
+func (m *allowlistAuthService) VerifyRequest(token, clientIP string) (*auth.Claims, error) {
+	if clientIP != "10.0.0.1" {
+		return nil, auth.ErrAddressNotAllowed
+	}
+	return &auth.Claims{Subject: "key_123", SubjectType: auth.SubjectAPIKey}, nil
+}
+
+func TestAuthMiddleware_IgnoresForwardedForFromUntrustedProxies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	serve := func(trustedProxies []string, remoteAddr string) int {
+		w := httptest.NewRecorder()
+		c, r := gin.CreateTestContext(w)
+		assert.NoError(t, r.SetTrustedProxies(trustedProxies))
+		r.Use(AuthMiddleware(&allowlistAuthService{}, nil))
+		r.GET("/organizations/:orgID", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+		c.Request, _ = http.NewRequest(http.MethodGet, "/organizations/org_1", nil)
+		c.Request.RemoteAddr = remoteAddr
+		c.Request.Header.Set("Authorization", "Bearer r7key_test")
+		c.Request.Header.Set("X-Forwarded-For", "10.0.0.1")
+		r.ServeHTTP(w, c.Request)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusForbidden, serve(nil, "203.0.113.5:4321"), "a client cannot claim an allowed address")
+	assert.Equal(t, http.StatusOK, serve([]string{"203.0.113.5"}, "203.0.113.5:4321"), "trusted proxies pass the client's address")
+}
+
+func TestAuthMiddleware_RejectsExchangedTokens(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	for name, tc := range map[string]struct {
+		claims *auth.Claims
+		want   int
+	}{
+		"login token":           {&auth.Claims{Subject: "user_123"}, http.StatusOK},
+		"service account token": {&auth.Claims{Subject: "sa_123", SubjectType: auth.SubjectServiceAccount, ClientID: "sa_ci"}, http.StatusOK},
+		"impersonation token":   {&auth.Claims{Subject: "user_123", Actor: &auth.Actor{Subject: "admin_1"}}, http.StatusOK},
+		"audience":              {&auth.Claims{Subject: "user_123", Audience: "billing"}, http.StatusUnauthorized},
+		"delegated":             {&auth.Claims{Subject: "user_123", ClientID: "gateway", Actor: &auth.Actor{Subject: "gateway", ClientID: "gateway"}}, http.StatusUnauthorized},
+	} {
+		t.Run(name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, r := gin.CreateTestContext(w)
+			r.Use(AuthMiddleware(&patAuthService{claims: tc.claims}, nil))
+			r.GET("/test", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			c.Request, _ = http.NewRequest(http.MethodGet, "/test", nil)
+			c.Request.Header.Set("Authorization", "Bearer exchanged_token")
+			r.ServeHTTP(w, c.Request)
+
+			assert.Equal(t, tc.want, w.Code)
+		})
+	}
+}
+
+func TestAuthMiddleware_PersonalAccessTokenRestrictions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	orgID, otherOrgID := uuid.New().String(), uuid.New().String()
+
+	for name, tc := range map[string]struct {
+		scopes []string
+		org    string
+		method string
+		path   string
+		want   int
+	}{
+		"read scope on GET":        {[]string{auth.ScopeAPIRead}, "", http.MethodGet, "/organizations/" + orgID, http.StatusOK},
+		"read scope on PATCH":      {[]string{auth.ScopeAPIRead}, "", http.MethodPatch, "/organizations/" + orgID, http.StatusForbidden},
+		"write scope on PATCH":     {[]string{auth.ScopeAPIWrite}, "", http.MethodPatch, "/organizations/" + orgID, http.StatusOK},
+		"write scope implies read": {[]string{auth.ScopeAPIWrite}, "", http.MethodGet, "/organizations/" + orgID, http.StatusOK},
+		"restricted to this org":   {[]string{auth.ScopeAPIWrite}, orgID, http.MethodPatch, "/organizations/" + orgID, http.StatusOK},
+		"restricted to other org":  {[]string{auth.ScopeAPIRead}, otherOrgID, http.MethodGet, "/organizations/" + orgID, http.StatusForbidden},
+		"restricted, no org route": {[]string{auth.ScopeAPIWrite}, orgID, http.MethodPost, "/organizations", http.StatusForbidden},
+	} {
+		t.Run(name, func(t *testing.T) {
+			service := &patAuthService{claims: &auth.Claims{
+				Subject:        uuid.New().String(),
+				TokenID:        uuid.New().String(),
+				Scopes:         tc.scopes,
+				OrganizationID: tc.org,
+			}}
+			w := httptest.NewRecorder()
+			c, r := gin.CreateTestContext(w)
+			r.Use(AuthMiddleware(service, nil))
+			ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+			r.GET("/organizations/:orgID", ok)
+			r.PATCH("/organizations/:orgID", ok)
+			r.POST("/organizations", ok)
+
+			c.Request, _ = http.NewRequest(tc.method, tc.path, nil)
+			c.Request.Header.Set("Authorization", "Bearer r7pat_test")
+			r.ServeHTTP(w, c.Request)
+
+			assert.Equal(t, tc.want, w.Code)
+		})
+	}
+}
+
+type sessionAuthService struct {
+	mockAuthService
+	refreshed string
+}
+
+func (m *sessionAuthService) VerifyRequest(token, clientIP string) (*auth.Claims, error) {
+	if token != "session_token" {
+		return nil, auth.ErrInvalidToken
+	}
+	return &auth.Claims{Subject: "user_123", SessionID: "sid_1"}, nil
+}
+
+func (m *sessionAuthService) RefreshSession(claims *auth.Claims) (string, time.Time, error) {
+	return m.refreshed, time.Now().Add(time.Hour), nil
+}
+
+func TestAuthMiddleware_SessionCookie(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	sessions := auth.NewSessionCookies(auth.SessionCookieConfig{Name: "r7_token"})
+
+	serve := func(service auth.Service, method string, header http.Header) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, r := gin.CreateTestContext(w)
+		r.Use(AuthMiddleware(service, sessions))
+		ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+		r.GET("/test", ok)
+		r.POST("/test", ok)
+
+		c.Request, _ = http.NewRequest(method, "/test", nil)
+		c.Request.Header = header
+		c.Request.AddCookie(&http.Cookie{Name: "r7_token", Value: "session_token"})
+		r.ServeHTTP(w, c.Request)
+		return w
+	}
+
+	service := &sessionAuthService{}
+	assert.Equal(t, http.StatusOK, serve(service, http.MethodGet, http.Header{}).Code)
+	assert.Empty(t, serve(service, http.MethodGet, http.Header{}).Header().Values("Set-Cookie"))
+
+	t.Run("unsafe methods need the CSRF token", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, serve(service, http.MethodPost, http.Header{}).Code)
+		header := http.Header{}
+		header.Set(auth.CSRFHeader, "csrf-other")
+		assert.Equal(t, http.StatusForbidden, serve(service, http.MethodPost, header).Code)
+		header.Set(auth.CSRFHeader, "csrf-sid_1")
+		assert.Equal(t, http.StatusOK, serve(service, http.MethodPost, header).Code)
+	})
+
+	t.Run("Authorization header takes precedence", func(t *testing.T) {
+		header := http.Header{"Authorization": {"Bearer bad"}}
+		assert.Equal(t, http.StatusUnauthorized, serve(service, http.MethodGet, header).Code)
+	})
+
+	t.Run("bearer tokens need no CSRF token", func(t *testing.T) {
+		header := http.Header{"Authorization": {"Bearer session_token"}}
+		assert.Equal(t, http.StatusOK, serve(service, http.MethodPost, header).Code)
+	})
+
+	t.Run("stale sessions are refreshed", func(t *testing.T) {
+		w := serve(&sessionAuthService{refreshed: "new_token"}, http.MethodGet, http.Header{})
+		assert.Equal(t, http.StatusOK, w.Code)
+		cookies := w.Result().Cookies()
+		assert.Len(t, cookies, 2)
+		assert.Equal(t, "new_token", cookies[0].Value)
+		assert.True(t, cookies[0].HttpOnly)
+		assert.Equal(t, "csrf-sid_1", cookies[1].Value)
+		assert.False(t, cookies[1].HttpOnly)
+	})
+}
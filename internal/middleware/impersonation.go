@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/radiatus-ai/auth-service/internal/audit"
+)
+
+// ImpersonationAudit records an audit event for every request made with an
+// impersonation token, naming the admin as the actor. It must run after
+// AuthMiddleware.
+func ImpersonationAudit(auditLog audit.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		adminID, err := uuid.Parse(c.GetString("impersonator_id"))
+		if err != nil {
+			return
+		}
+		event := audit.PlatformEvent(adminID, audit.ActionImpersonatedRequest, "user", c.GetString("user_id"))
+		event.Metadata["method"] = c.Request.Method
+		event.Metadata["path"] = c.FullPath()
+		event.Metadata["status"] = c.Writer.Status()
+		auditLog.Record(event)
+	}
+}
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/radiatus-ai/auth-service/internal/auth"
+)
+
+// RequireStepUp guards sensitive actions. The user must have authenticated
+// within maxAge, at the acr level or above; otherwise the request is refused
+// with a step-up challenge (RFC 9470) and the client should have the user
+// sign in again at /api/reauthenticate. Service accounts and API keys have no
+// sign-in to repeat and are let through. It must run after AuthMiddleware.
+func RequireStepUp(maxAge time.Duration, acr string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, _ := c.Get("claims")
+		claims, ok := value.(*auth.Claims)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+			c.Abort()
+			return
+		}
+		if claims.IsServiceAccount() || claims.IsAPIKey() {
+			c.Next()
+			return
+		}
+
+		if claims.AuthTime.IsZero() || time.Since(claims.AuthTime) > maxAge || !auth.ACRSatisfies(claims.ACR, acr) {
+			maxAgeSeconds := int(maxAge.Seconds())
+			c.Header("WWW-Authenticate", fmt.Sprintf(
+				`Bearer error="insufficient_user_authentication", error_description="A more recent or stronger authentication is required", max_age=%d, acr_values="%s"`,
+				maxAgeSeconds, acr))
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error":      "Please sign in again to continue",
+				"code":       "step_up_required",
+				"max_age":    maxAgeSeconds,
+				"acr_values": acr,
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/radiatus-ai/auth-service/internal/auth"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequireStepUp(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	recent, stale := time.Now().Add(-time.Minute), time.Now().Add(-time.Hour)
+
+	for name, tc := range map[string]struct {
+		claims *auth.Claims
+		acr    string
+		want   int
+	}{
+		"recent sign-in":          {&auth.Claims{SubjectType: auth.SubjectUser, AuthTime: recent, ACR: auth.ACRSingleFactor}, auth.ACRSingleFactor, http.StatusOK},
+		"stale sign-in":           {&auth.Claims{SubjectType: auth.SubjectUser, AuthTime: stale, ACR: auth.ACRSingleFactor}, auth.ACRSingleFactor, http.StatusUnauthorized},
+		"weak sign-in":            {&auth.Claims{SubjectType: auth.SubjectUser, AuthTime: recent, ACR: auth.ACRSingleFactor}, auth.ACRMultiFactor, http.StatusUnauthorized},
+		"strong sign-in":          {&auth.Claims{SubjectType: auth.SubjectUser, AuthTime: recent, ACR: auth.ACRMultiFactor}, auth.ACRSingleFactor, http.StatusOK},
+		"no sign-in":              {&auth.Claims{SubjectType: auth.SubjectUser, TokenID: "pat"}, auth.ACRSingleFactor, http.StatusUnauthorized},
+		"service account":         {&auth.Claims{SubjectType: auth.SubjectServiceAccount}, auth.ACRMultiFactor, http.StatusOK},
+		"api key":                 {&auth.Claims{SubjectType: auth.SubjectAPIKey}, auth.ACRMultiFactor, http.StatusOK},
+		"unknown acr is not weak": {&auth.Claims{SubjectType: auth.SubjectUser, AuthTime: recent, ACR: "bogus"}, auth.ACRSingleFactor, http.StatusUnauthorized},
+	} {
+		t.Run(name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			_, r := gin.CreateTestContext(w)
+			r.Use(func(c *gin.Context) { c.Set("claims", tc.claims) })
+			r.DELETE("/test", RequireStepUp(10*time.Minute, tc.acr), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/test", nil))
+
+			assert.Equal(t, tc.want, w.Code)
+			if tc.want == http.StatusUnauthorized {
+				assert.Contains(t, w.Header().Get("WWW-Authenticate"), `error="insufficient_user_authentication"`)
+				assert.Contains(t, w.Header().Get("WWW-Authenticate"), "max_age=600")
+				assert.Contains(t, w.Body.String(), `"code":"step_up_required"`)
+			}
+		})
+	}
+}
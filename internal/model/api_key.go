@@ -0,0 +1,43 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// APIKey lets a customer's own systems call the API on behalf of an
+// organization rather than a user. It holds individual permissions (Scopes)
+// instead of a role, and may be limited to a set of networks.
+type APIKey struct {
+	ID             uuid.UUID  `gorm:"type:uuid;primary_key;" json:"id"`
+	OrganizationID uuid.UUID  `gorm:"type:uuid;not null" json:"organization_id"`
+	Name           string     `gorm:"not null" json:"name"`
+	KeyHash        string     `gorm:"unique;not null" json:"-"`
+	KeyHint        string     `gorm:"not null" json:"key_hint"`
+	Scopes         StringList `gorm:"type:jsonb;not null" json:"scopes"`
+	AllowedCIDRs   StringList `gorm:"column:allowed_cidrs;type:jsonb;not null" json:"allowed_cidrs"`
+	ExpiresAt      *time.Time `json:"expires_at,omitempty"`
+	RotatedFromID  *uuid.UUID `gorm:"type:uuid" json:"rotated_from_id,omitempty"`
+	LastUsedAt     *time.Time `json:"last_used_at,omitempty"`
+	LastUsedIP     string     `gorm:"column:last_used_ip" json:"last_used_ip,omitempty"`
+	CreatedBy      *uuid.UUID `gorm:"type:uuid" json:"created_by,omitempty"`
+	CreatedAt      time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (APIKey) TableName() string {
+	return "organization_api_keys"
+}
+
+func (k *APIKey) BeforeCreate(tx *gorm.DB) error {
+	if k.ID == uuid.Nil {
+		k.ID = uuid.New()
+	}
+	return nil
+}
+
+// Expired reports whether the key can no longer be used at now.
+func (k *APIKey) Expired(now time.Time) bool {
+	return k.ExpiresAt != nil && !now.Before(*k.ExpiresAt)
+}
@@ -0,0 +1,27 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type AuditEvent struct {
+	ID             uuid.UUID  `gorm:"type:uuid;primary_key;" json:"id"`
+	OrganizationID *uuid.UUID `gorm:"type:uuid" json:"organization_id,omitempty"`
+	ActorType      string     `gorm:"not null" json:"actor_type"`
+	ActorID        string     `gorm:"not null" json:"actor_id"`
+	Action         string     `gorm:"not null" json:"action"`
+	TargetType     string     `json:"target_type,omitempty"`
+	TargetID       string     `json:"target_id,omitempty"`
+	Metadata       JSONMap    `gorm:"type:jsonb;not null;default:'{}'" json:"metadata,omitempty"`
+	CreatedAt      time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (e *AuditEvent) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	GrantTypeTokenExchange = "urn:ietf:params:oauth:grant-type:token-exchange"
+)
+
+// OAuthClient is an application allowed to call the /token endpoint, such as
+// the API gateway. Clients with RequireDPoP set must bind every token they get
+// to a DPoP key (RFC 9449). Clients with a TLSSubjectDN can authenticate with
+// a client certificate with that subject instead of their secret (RFC 8705).
+// TokenFormat selects whether the client is issued JWTs or reference tokens.
+type OAuthClient struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primary_key;" json:"id"`
+	ClientID   string     `gorm:"unique;not null" json:"client_id"`
+	SecretHash string     `gorm:"not null" json:"-"`
+	Name       string     `gorm:"not null" json:"name"`
+	GrantTypes StringList `gorm:"type:jsonb;not null" json:"grant_types"`
+	// Token exchange policy: the audiences the client may request tokens
+	// for, the scopes it may request, and the lifetime of issued tokens.
+	ExchangeAudiences StringList `gorm:"type:jsonb;not null" json:"exchange_audiences"`
+	ExchangeScopes    StringList `gorm:"type:jsonb;not null" json:"exchange_scopes"`
+	ExchangeTokenTTL  int        `gorm:"column:exchange_token_ttl_seconds;not null" json:"exchange_token_ttl_seconds"`
+	RequireDPoP       bool       `gorm:"column:require_dpop;not null" json:"require_dpop"`
+	TLSSubjectDN      string     `gorm:"column:tls_client_auth_subject_dn;not null" json:"tls_client_auth_subject_dn,omitempty"`
+	TokenFormat       string     `gorm:"column:access_token_format;not null;default:jwt" json:"access_token_format"`
+	Disabled          bool       `gorm:"not null" json:"disabled"`
+	CreatedAt         time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt         time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (OAuthClient) TableName() string {
+	return "oauth_clients"
+}
+
+func (c *OAuthClient) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return nil
+}
+
+func (c *OAuthClient) AllowsGrant(grantType string) bool {
+	return c.GrantTypes.Contains(grantType)
+}
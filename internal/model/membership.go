@@ -0,0 +1,20 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Membership is a user's direct membership of an organization, backed by the
+// user_organizations join table.
+type Membership struct {
+	UserID         uuid.UUID `gorm:"type:uuid;primary_key;" json:"user_id"`
+	OrganizationID uuid.UUID `gorm:"type:uuid;primary_key;" json:"organization_id"`
+	Role           string    `gorm:"not null;default:member" json:"role"`
+	CreatedAt      time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (Membership) TableName() string {
+	return "user_organizations"
+}
@@ -8,16 +8,46 @@ import (
 )
 
 type Organization struct {
-	ID        uuid.UUID `gorm:"type:uuid;primary_key;" json:"id"`
-	Name      string    `gorm:"not null" json:"name"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	Users     []User    `gorm:"many2many:user_organizations;" json:"users,omitempty"`
+	ID           uuid.UUID  `gorm:"type:uuid;primary_key;" json:"id"`
+	ParentID     *uuid.UUID `gorm:"type:uuid" json:"parent_id,omitempty"`
+	Name         string     `gorm:"not null" json:"name"`
+	Slug         string     `gorm:"unique;not null" json:"slug"`
+	LogoURL      string     `json:"logo_url,omitempty"`
+	PrimaryColor string     `json:"primary_color,omitempty"`
+	Settings     Settings   `gorm:"type:jsonb;not null;default:'{}'" json:"-"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	// DeletedAt marks a soft-deleted organization, which stays restorable
+	// until the purge job removes it for good.
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+	Users     []User         `gorm:"many2many:user_organizations;" json:"users,omitempty"`
 }
 
 func (o *Organization) BeforeCreate(tx *gorm.DB) error {
 	if o.ID == uuid.Nil {
 		o.ID = uuid.New()
 	}
+	if o.Settings == nil {
+		o.Settings = Settings{}
+	}
+	return nil
+}
+
+// Settings is the organization's free-form settings document.
+type Settings = JSONMap
+
+type OrganizationDomain struct {
+	ID                uuid.UUID  `gorm:"type:uuid;primary_key;" json:"id"`
+	OrganizationID    uuid.UUID  `gorm:"type:uuid;not null" json:"organization_id"`
+	Domain            string     `gorm:"not null" json:"domain"`
+	VerificationToken string     `gorm:"not null" json:"verification_token"`
+	VerifiedAt        *time.Time `json:"verified_at,omitempty"`
+	CreatedAt         time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (d *OrganizationDomain) BeforeCreate(tx *gorm.DB) error {
+	if d.ID == uuid.Nil {
+		d.ID = uuid.New()
+	}
 	return nil
 }
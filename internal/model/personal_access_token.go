@@ -0,0 +1,36 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PersonalAccessToken is a long-lived credential a user creates for scripts.
+// Only a hash of the token is stored; TokenHint keeps its first characters so
+// users can tell their tokens apart.
+type PersonalAccessToken struct {
+	ID             uuid.UUID  `gorm:"type:uuid;primary_key;" json:"id"`
+	UserID         uuid.UUID  `gorm:"type:uuid;not null" json:"user_id"`
+	OrganizationID *uuid.UUID `gorm:"type:uuid" json:"organization_id,omitempty"`
+	Name           string     `gorm:"not null" json:"name"`
+	TokenHash      string     `gorm:"unique;not null" json:"-"`
+	TokenHint      string     `gorm:"not null" json:"token_hint"`
+	Scopes         StringList `gorm:"type:jsonb;not null" json:"scopes"`
+	ExpiresAt      *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt     *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt      time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (t *PersonalAccessToken) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
+
+// Expired reports whether the token can no longer be used at now.
+func (t *PersonalAccessToken) Expired(now time.Time) bool {
+	return t.ExpiresAt != nil && !now.Before(*t.ExpiresAt)
+}
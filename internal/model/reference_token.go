@@ -0,0 +1,44 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Access token formats a client or service account can be issued. JWTs can
+// be verified by anyone with the signing keys; reference tokens are opaque
+// handles that only this service can resolve.
+const (
+	TokenFormatJWT       = "jwt"
+	TokenFormatReference = "reference"
+)
+
+func ValidTokenFormat(format string) bool {
+	return format == TokenFormatJWT || format == TokenFormatReference
+}
+
+// ReferenceToken holds the claims of an opaque access token. Only a hash of
+// the token is stored.
+type ReferenceToken struct {
+	ID        uuid.UUID `gorm:"type:uuid;primary_key;" json:"id"`
+	TokenHash string    `gorm:"unique;not null" json:"-"`
+	Subject   string    `gorm:"not null" json:"subject"`
+	ClientID  string    `gorm:"not null" json:"client_id"`
+	Claims    JSONMap   `gorm:"type:jsonb;not null" json:"-"`
+	ExpiresAt time.Time `gorm:"not null" json:"expires_at"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (t *ReferenceToken) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
+
+// Expired reports whether the token can no longer be used at now.
+func (t *ReferenceToken) Expired(now time.Time) bool {
+	return !now.Before(t.ExpiresAt)
+}
@@ -0,0 +1,114 @@
+package model
+
+import (
+	"path"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	GrantTypeClientCredentials = "client_credentials"
+	// GrantTypeJWTBearer (RFC 7523) lets a workload present a token from a
+	// trusted external issuer instead of a secret.
+	GrantTypeJWTBearer = "urn:ietf:params:oauth:grant-type:jwt-bearer"
+
+	// ServiceAccountClientIDPrefix starts every service account's client
+	// ID, telling them apart from OAuth clients.
+	ServiceAccountClientIDPrefix = "sa_"
+)
+
+// ServiceAccount is a machine identity owned by an organization, used by
+// backend jobs and CI pipelines instead of a person's token. Its role applies
+// to the owning organization exactly like a member's role. Accounts with
+// RequireDPoP set must bind every token they get to a DPoP key (RFC 9449), and
+// accounts with a TLSSubjectDN can authenticate with a client certificate
+// with that subject instead of a secret (RFC 8705). TokenFormat selects
+// whether the account is issued JWTs or reference tokens.
+type ServiceAccount struct {
+	ID             uuid.UUID  `gorm:"type:uuid;primary_key;" json:"id"`
+	OrganizationID uuid.UUID  `gorm:"type:uuid;not null" json:"organization_id"`
+	ClientID       string     `gorm:"unique;not null" json:"client_id"`
+	Name           string     `gorm:"not null" json:"name"`
+	Description    string     `json:"description,omitempty"`
+	Role           string     `gorm:"not null" json:"role"`
+	Disabled       bool       `gorm:"not null" json:"disabled"`
+	RequireDPoP    bool       `gorm:"column:require_dpop;not null" json:"require_dpop"`
+	TLSSubjectDN   string     `gorm:"column:tls_client_auth_subject_dn;not null" json:"tls_client_auth_subject_dn,omitempty"`
+	TokenFormat    string     `gorm:"column:access_token_format;not null;default:jwt" json:"access_token_format"`
+	CreatedBy      *uuid.UUID `gorm:"type:uuid" json:"created_by,omitempty"`
+	CreatedAt      time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt      time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (a *ServiceAccount) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
+
+// ServiceAccountSecret is one of the secrets a service account can
+// authenticate with. Only its hash is stored.
+type ServiceAccountSecret struct {
+	ID               uuid.UUID  `gorm:"type:uuid;primary_key;" json:"id"`
+	ServiceAccountID uuid.UUID  `gorm:"type:uuid;not null" json:"service_account_id"`
+	SecretHash       string     `gorm:"not null" json:"-"`
+	Description      string     `json:"description,omitempty"`
+	ExpiresAt        *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt       *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt        time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (s *ServiceAccountSecret) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
+
+// Expired reports whether the secret can no longer be used at t.
+func (s *ServiceAccountSecret) Expired(t time.Time) bool {
+	return s.ExpiresAt != nil && !t.Before(*s.ExpiresAt)
+}
+
+// TrustPolicy lets a workload exchange a token from an external issuer, such
+// as a Kubernetes service account token or a GCP identity token, for a token
+// of the service account. The issuer must match the external token's iss
+// exactly; SubjectPattern and AudiencePattern are path.Match patterns for its
+// sub and aud claims.
+type TrustPolicy struct {
+	ID               uuid.UUID `gorm:"type:uuid;primary_key;" json:"id"`
+	ServiceAccountID uuid.UUID `gorm:"type:uuid;not null" json:"service_account_id"`
+	Issuer           string    `gorm:"not null" json:"issuer"`
+	SubjectPattern   string    `gorm:"not null" json:"subject_pattern"`
+	AudiencePattern  string    `gorm:"not null" json:"audience_pattern"`
+	Description      string    `json:"description,omitempty"`
+	CreatedAt        time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (TrustPolicy) TableName() string {
+	return "service_account_trust_policies"
+}
+
+func (p *TrustPolicy) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	return nil
+}
+
+// Matches reports whether a token with the given subject and audiences, from
+// the policy's issuer, satisfies the policy.
+func (p *TrustPolicy) Matches(subject string, audiences []string) bool {
+	if ok, _ := path.Match(p.SubjectPattern, subject); !ok {
+		return false
+	}
+	for _, audience := range audiences {
+		if ok, _ := path.Match(p.AudiencePattern, audience); ok {
+			return true
+		}
+	}
+	return false
+}
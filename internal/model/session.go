@@ -0,0 +1,80 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Auth methods record how a session was started.
+const (
+	AuthMethodGoogle = "google"
+)
+
+// GrantTypeRefreshToken redeems a session's refresh token for a new access
+// token.
+const GrantTypeRefreshToken = "refresh_token"
+
+// Session is a user's sign-in on one device. Tokens issued at login carry the
+// session's ID, so deleting the session signs that device out.
+type Session struct {
+	ID         uuid.UUID `gorm:"type:uuid;primary_key;" json:"id"`
+	UserID     uuid.UUID `gorm:"type:uuid;not null" json:"user_id"`
+	AuthMethod string    `gorm:"not null" json:"auth_method"`
+	UserAgent  string    `json:"user_agent,omitempty"`
+	IPAddress  string    `gorm:"column:ip_address" json:"ip_address,omitempty"`
+	// AuthTime is when the user last authenticated in the session, at the
+	// ACR level with the AMR methods.
+	AuthTime   time.Time  `gorm:"not null" json:"auth_time"`
+	ACR        string     `gorm:"column:acr;not null" json:"acr"`
+	AMR        StringList `gorm:"column:amr;type:jsonb;not null" json:"amr"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	LastSeenAt time.Time  `gorm:"not null" json:"last_seen_at"`
+	// ExpiresAt is when the session ends unless it is renewed: when both
+	// its current access token and its refresh token have expired.
+	ExpiresAt time.Time `gorm:"not null" json:"expires_at"`
+	// RefreshTokenHash is the hash of the session's current refresh token,
+	// which can be redeemed until RefreshExpiresAt.
+	RefreshTokenHash *string    `gorm:"unique" json:"-"`
+	RefreshExpiresAt *time.Time `json:"-"`
+	// IdleTimeoutSeconds and AbsoluteExpiresAt are the limits of the
+	// session policy in force when the session was last renewed.
+	IdleTimeoutSeconds int        `gorm:"not null;default:0" json:"-"`
+	AbsoluteExpiresAt  *time.Time `json:"absolute_expires_at,omitempty"`
+	// KeyThumbprint is the DPoP key the session's tokens and refresh token
+	// are bound to, if any.
+	KeyThumbprint string `gorm:"column:dpop_jkt;not null;default:''" json:"-"`
+	// Current marks the session the listing was requested from.
+	Current bool `gorm:"-" json:"current"`
+}
+
+func (Session) TableName() string {
+	return "user_sessions"
+}
+
+func (s *Session) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
+
+// Expired reports whether the session has ended at now, either because it
+// was not renewed in time or because it has been idle for too long.
+func (s *Session) Expired(now time.Time) bool {
+	if !now.Before(s.ExpiresAt) {
+		return true
+	}
+	idleTimeout := time.Duration(s.IdleTimeoutSeconds) * time.Second
+	return idleTimeout > 0 && now.Sub(s.LastSeenAt) > idleTimeout
+}
+
+// Cap returns t, or the end of the session's absolute lifetime if that comes
+// first. Nothing issued for the session may outlive it.
+func (s *Session) Cap(t time.Time) time.Time {
+	if s.AbsoluteExpiresAt != nil && s.AbsoluteExpiresAt.Before(t) {
+		return *s.AbsoluteExpiresAt
+	}
+	return t
+}
@@ -0,0 +1,34 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type Team struct {
+	ID             uuid.UUID  `gorm:"type:uuid;primary_key;" json:"id"`
+	OrganizationID uuid.UUID  `gorm:"type:uuid;not null" json:"organization_id"`
+	ParentTeamID   *uuid.UUID `gorm:"type:uuid" json:"parent_team_id,omitempty"`
+	Name           string     `gorm:"not null" json:"name"`
+	Description    string     `json:"description,omitempty"`
+	// Role granted to every member of the team, and of its child teams, on
+	// the owning organization. Empty means the team grants no role.
+	Role      string    `json:"role,omitempty"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (t *Team) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
+
+type TeamMember struct {
+	TeamID    uuid.UUID `gorm:"type:uuid;primary_key;" json:"team_id"`
+	UserID    uuid.UUID `gorm:"type:uuid;primary_key;" json:"user_id"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
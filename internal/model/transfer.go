@@ -0,0 +1,35 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	TransferPending   = "pending"
+	TransferAccepted  = "accepted"
+	TransferCancelled = "cancelled"
+	TransferDeclined  = "declined"
+)
+
+// OwnershipTransfer is an owner's offer to hand an organization over to
+// another member. It takes effect only once the recipient accepts it.
+type OwnershipTransfer struct {
+	ID             uuid.UUID  `gorm:"type:uuid;primary_key;" json:"id"`
+	OrganizationID uuid.UUID  `gorm:"type:uuid;not null" json:"organization_id"`
+	FromUserID     uuid.UUID  `gorm:"type:uuid;not null" json:"from_user_id"`
+	ToUserID       uuid.UUID  `gorm:"type:uuid;not null" json:"to_user_id"`
+	Status         string     `gorm:"not null" json:"status"`
+	ExpiresAt      time.Time  `gorm:"not null" json:"expires_at"`
+	CreatedAt      time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	ResolvedAt     *time.Time `json:"resolved_at,omitempty"`
+}
+
+func (t *OwnershipTransfer) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
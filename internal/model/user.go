@@ -7,19 +7,54 @@ import (
 	"gorm.io/gorm"
 )
 
+// A user can only sign in and use tokens while active. Suspension is a
+// temporary block by support, deactivation is for departed users, and locking
+// is a security hold, for example after a suspected compromise.
+const (
+	UserStatusActive      = "active"
+	UserStatusSuspended   = "suspended"
+	UserStatusDeactivated = "deactivated"
+	UserStatusLocked      = "locked"
+)
+
+func ValidUserStatus(status string) bool {
+	switch status {
+	case UserStatusActive, UserStatusSuspended, UserStatusDeactivated, UserStatusLocked:
+		return true
+	}
+	return false
+}
+
+// PlatformRoleSuperadmin grants access to the /admin API across all organizations.
+const PlatformRoleSuperadmin = "superadmin"
+
 type User struct {
 	ID       uuid.UUID `gorm:"type:uuid;primary_key;" json:"id"`
 	Email    string    `gorm:"unique;not null" json:"email"`
 	GoogleID string    `gorm:"unique" json:"google_id,omitempty"`
 	// Password      string         `json:"-"` // Excluded from JSON output
-	CreatedAt     time.Time      `gorm:"autoCreateTime" json:"created_at"`
-	UpdatedAt     time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
-	Organizations []Organization `gorm:"many2many:user_organizations;" json:"organizations,omitempty"`
+	Status          string     `gorm:"not null" json:"status"`
+	StatusReason    string     `json:"status_reason,omitempty"`
+	StatusChangedAt *time.Time `json:"status_changed_at,omitempty"`
+	PlatformRole    string     `json:"platform_role,omitempty"`
+	// TokensValidAfter revokes every token issued before it, or in the same
+	// second.
+	TokensValidAfter *time.Time     `json:"-"`
+	CreatedAt        time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt        time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+	Organizations    []Organization `gorm:"many2many:user_organizations;" json:"organizations,omitempty"`
 }
 
 func (u *User) BeforeCreate(tx *gorm.DB) error {
 	if u.ID == uuid.Nil {
 		u.ID = uuid.New()
 	}
+	if u.Status == "" {
+		u.Status = UserStatusActive
+	}
 	return nil
 }
+
+func (u *User) IsSuperadmin() bool {
+	return u.PlatformRole == PlatformRoleSuperadmin
+}
@@ -0,0 +1,33 @@
+package oauth
+
+import "github.com/radiatus-ai/auth-service/internal/auth"
+
+// Binding is what the tokens of a request are bound to: the DPoP key the
+// client proved it holds (RFC 9449) and the client certificate it connected
+// with (RFC 8705). Either may be empty.
+type Binding struct {
+	KeyThumbprint         string
+	CertificateThumbprint string
+}
+
+func (b Binding) apply(claims *auth.Claims) {
+	claims.KeyThumbprint = b.KeyThumbprint
+	claims.CertificateThumbprint = b.CertificateThumbprint
+}
+
+// holds reports whether the request carries whatever the claims are bound
+// to, so that a bound token is only used by the holder of its key or
+// certificate.
+func (b Binding) holds(claims *auth.Claims) bool {
+	return (claims.KeyThumbprint == "" || claims.KeyThumbprint == b.KeyThumbprint) &&
+		(claims.CertificateThumbprint == "" || claims.CertificateThumbprint == b.CertificateThumbprint)
+}
+
+// tokenType is the token_type of the tokens. Certificate-bound tokens are
+// still presented as bearer tokens.
+func (b Binding) tokenType() string {
+	if b.KeyThumbprint != "" {
+		return "DPoP"
+	}
+	return "Bearer"
+}
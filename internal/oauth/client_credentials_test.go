@@ -0,0 +1,121 @@
+package oauth
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/radiatus-ai/auth-service/internal/auth"
+	"github.com/radiatus-ai/auth-service/internal/credential"
+	"github.com/radiatus-ai/auth-service/internal/model"
+	"github.com/radiatus-ai/auth-service/internal/repository"
+)
+
+type stubServiceAccountRepository struct {
+	repository.ServiceAccountRepository
+	account  *model.ServiceAccount
+	secrets  []model.ServiceAccountSecret
+	policies []model.TrustPolicy
+	touched  []uuid.UUID
+}
+
+func (s *stubServiceAccountRepository) GetByClientID(clientID string) (*model.ServiceAccount, error) {
+	if s.account == nil || s.account.ClientID != clientID {
+		return nil, repository.ErrServiceAccountNotFound
+	}
+	return s.account, nil
+}
+
+func (s *stubServiceAccountRepository) ListSecrets(accountID uuid.UUID) ([]model.ServiceAccountSecret, error) {
+	return s.secrets, nil
+}
+
+func (s *stubServiceAccountRepository) TouchSecret(secretID uuid.UUID, at time.Time) error {
+	s.touched = append(s.touched, secretID)
+	return nil
+}
+
+func (s *stubServiceAccountRepository) ListTrustPolicies(accountID uuid.UUID) ([]model.TrustPolicy, error) {
+	return s.policies, nil
+}
+
+func newServiceAccountTestService(secrets ...model.ServiceAccountSecret) (*service, *stubServiceAccountRepository, *stubAuthService) {
+	repo := &stubServiceAccountRepository{
+		account: &model.ServiceAccount{ID: uuid.New(), ClientID: "sa_ci"},
+		secrets: secrets,
+	}
+	authService := &stubAuthService{}
+	return &service{authService: authService, serviceAccountRepo: repo}, repo, authService
+}
+
+func secretFor(plaintext string) model.ServiceAccountSecret {
+	return model.ServiceAccountSecret{ID: uuid.New(), SecretHash: credential.Hash(plaintext)}
+}
+
+func TestAuthenticateServiceAccount_AnyLiveSecret(t *testing.T) {
+	old, current := secretFor("old-secret"), secretFor("new-secret")
+	svc, repo, _ := newServiceAccountTestService(old, current)
+
+	for _, secret := range []string{"old-secret", "new-secret"} {
+		account, err := svc.AuthenticateServiceAccount("sa_ci", secret)
+		assert.NoError(t, err)
+		assert.Equal(t, repo.account.ID, account.ID)
+	}
+	assert.Equal(t, []uuid.UUID{old.ID, current.ID}, repo.touched)
+}
+
+func TestAuthenticateServiceAccount_Rejections(t *testing.T) {
+	expired := secretFor("expired-secret")
+	past := time.Now().Add(-time.Minute)
+	expired.ExpiresAt = &past
+
+	for name, tc := range map[string]struct {
+		clientID, secret string
+		disabled         bool
+	}{
+		"wrong secret":   {"sa_ci", "nope", false},
+		"unknown client": {"sa_other", "live-secret", false},
+		"expired secret": {"sa_ci", "expired-secret", false},
+		"disabled":       {"sa_ci", "live-secret", true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			svc, repo, _ := newServiceAccountTestService(secretFor("live-secret"), expired)
+			repo.account.Disabled = tc.disabled
+
+			_, err := svc.AuthenticateServiceAccount(tc.clientID, tc.secret)
+
+			assert.True(t, errors.Is(err, ErrInvalidClient))
+		})
+	}
+}
+
+func TestClientCredentials_IssuesServiceAccountToken(t *testing.T) {
+	svc, repo, authService := newServiceAccountTestService()
+
+	resp, err := svc.ClientCredentials(repo.account, "", Binding{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Bearer", resp.TokenType)
+	assert.Equal(t, int(serviceAccountTokenTTL.Seconds()), resp.ExpiresIn)
+	assert.Equal(t, auth.SubjectServiceAccount, authService.issued.SubjectType)
+	assert.Equal(t, repo.account.ID.String(), authService.issued.Subject)
+	assert.Equal(t, "sa_ci", authService.issued.ClientID)
+}
+
+func TestClientCredentials_DPoP(t *testing.T) {
+	svc, repo, authService := newServiceAccountTestService()
+	repo.account.RequireDPoP = true
+
+	_, err := svc.ClientCredentials(repo.account, "", Binding{})
+	var oauthErr *Error
+	assert.True(t, errors.As(err, &oauthErr))
+	assert.Equal(t, "invalid_dpop_proof", oauthErr.Code)
+
+	resp, err := svc.ClientCredentials(repo.account, "", Binding{KeyThumbprint: "key-1"})
+	assert.NoError(t, err)
+	assert.Equal(t, "DPoP", resp.TokenType)
+	assert.Equal(t, "key-1", authService.issued.KeyThumbprint)
+}
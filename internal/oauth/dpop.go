@@ -0,0 +1,27 @@
+package oauth
+
+import (
+	"log"
+	"net/http"
+)
+
+// VerifyDPoPProof checks the DPoP proof (RFC 9449) sent to the token endpoint
+// at url and returns the thumbprint of the key the issued tokens are to be
+// bound to.
+func (s *service) VerifyDPoPProof(proof, url string) (string, error) {
+	jkt, err := s.authService.VerifyDPoPProof(proof, http.MethodPost, url, "")
+	if err != nil {
+		log.Printf("Rejected DPoP proof at the token endpoint: %v", err)
+		return "", invalidDPoPProof("DPoP proof is invalid")
+	}
+	return jkt, nil
+}
+
+// requireDPoP rejects a token request without a DPoP proof from a client or
+// service account that must bind its tokens to a key.
+func requireDPoP(required bool, keyThumbprint string) error {
+	if required && keyThumbprint == "" {
+		return invalidDPoPProof("a DPoP proof is required")
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package oauth
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Error is an OAuth 2.0 error response (RFC 6749 section 5.2).
+type Error struct {
+	Code        string
+	Description string
+	Status      int
+}
+
+func (e *Error) Error() string {
+	if e.Description == "" {
+		return e.Code
+	}
+	return e.Code + ": " + e.Description
+}
+
+func invalidRequest(description string) *Error {
+	return &Error{Code: "invalid_request", Description: description, Status: http.StatusBadRequest}
+}
+
+func invalidGrant(description string) *Error {
+	return &Error{Code: "invalid_grant", Description: description, Status: http.StatusBadRequest}
+}
+
+func invalidScope(description string) *Error {
+	return &Error{Code: "invalid_scope", Description: description, Status: http.StatusBadRequest}
+}
+
+func invalidDPoPProof(description string) *Error {
+	return &Error{Code: "invalid_dpop_proof", Description: description, Status: http.StatusBadRequest}
+}
+
+func invalidTarget(description string) *Error {
+	return &Error{Code: "invalid_target", Description: description, Status: http.StatusBadRequest}
+}
+
+var (
+	ErrInvalidClient        = &Error{Code: "invalid_client", Description: "client authentication failed", Status: http.StatusUnauthorized}
+	ErrUnauthorizedClient   = &Error{Code: "unauthorized_client", Description: "client is not allowed to use this grant type", Status: http.StatusBadRequest}
+	ErrUnsupportedGrantType = &Error{Code: "unsupported_grant_type", Status: http.StatusBadRequest}
+
+	ErrInvalidClientPolicy = errors.New("client names, grant types and token formats must be valid and the token TTL must be between 1 and 3600 seconds")
+	// Add other oauth-related errors here
+)
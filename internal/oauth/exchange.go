@@ -0,0 +1,150 @@
+package oauth
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	"github.com/radiatus-ai/auth-service/internal/auth"
+	"github.com/radiatus-ai/auth-service/internal/model"
+)
+
+// Token type identifiers from RFC 8693 section 3.
+const (
+	TokenTypeAccessToken = "urn:ietf:params:oauth:token-type:access_token"
+	TokenTypeJWT         = "urn:ietf:params:oauth:token-type:jwt"
+)
+
+// ExchangeRequest is a token exchange request (RFC 8693 section 2.1).
+type ExchangeRequest struct {
+	SubjectToken     string
+	SubjectTokenType string
+	Audience         string
+	Scope            string
+	// Binding is what the new token is bound to.
+	Binding Binding
+}
+
+// TokenResponse is a successful /token response.
+type TokenResponse struct {
+	AccessToken     string `json:"access_token"`
+	IssuedTokenType string `json:"issued_token_type,omitempty"`
+	TokenType       string `json:"token_type"`
+	ExpiresIn       int    `json:"expires_in"`
+	Scope           string `json:"scope,omitempty"`
+	RefreshToken    string `json:"refresh_token,omitempty"`
+}
+
+// ExchangeToken trades the subject token for a token aimed at a single
+// audience. The new token keeps the subject, names the client in its act
+// claim, and can only narrow what the subject token allowed: its scopes are a
+// subset of both the client's policy and the subject token's scopes, and it
+// never outlives the subject token or the session it belongs to. A subject
+// token bound to a key or certificate is only exchanged by a client presenting
+// it, and the new token stays bound to it.
+func (s *service) ExchangeToken(client *model.OAuthClient, req *ExchangeRequest) (*TokenResponse, error) {
+	if !client.AllowsGrant(model.GrantTypeTokenExchange) {
+		return nil, ErrUnauthorizedClient
+	}
+	if req.SubjectToken == "" {
+		return nil, invalidRequest("subject_token is required")
+	}
+	if req.SubjectTokenType != TokenTypeAccessToken && req.SubjectTokenType != TokenTypeJWT {
+		return nil, invalidRequest("unsupported subject_token_type")
+	}
+	if req.Audience == "" {
+		return nil, invalidRequest("audience is required")
+	}
+	if err := requireDPoP(client.RequireDPoP, req.Binding.KeyThumbprint); err != nil {
+		return nil, err
+	}
+	if !client.ExchangeAudiences.Contains(req.Audience) {
+		return nil, invalidTarget("client may not request tokens for this audience")
+	}
+
+	subject, err := s.authService.VerifyClaims(req.SubjectToken)
+	if err != nil {
+		log.Printf("Token exchange by client %s rejected subject token: %v", client.ClientID, err)
+		return nil, invalidGrant("subject token is invalid")
+	}
+	if subject.IsAPIKey() {
+		return nil, invalidGrant("API keys cannot be exchanged")
+	}
+	if subject.Audience != "" {
+		// Tokens already aimed at a service are not re-exchanged; the
+		// gateway must exchange the user's original token.
+		return nil, invalidGrant("subject token is already restricted to an audience")
+	}
+
+	if !req.Binding.holds(subject) {
+		// A bound subject token is only exchanged by the holder of its key
+		// or certificate, whose binding then carries over to the new token.
+		return nil, invalidGrant("subject token is bound to a key or certificate the client did not present")
+	}
+
+	scopes, err := exchangeScopes(client, subject, req.Scope)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(time.Duration(client.ExchangeTokenTTL) * time.Second)
+	if !subject.ExpiresAt.IsZero() && subject.ExpiresAt.Before(expiresAt) {
+		expiresAt = subject.ExpiresAt
+	}
+
+	claims := &auth.Claims{
+		Subject:     subject.Subject,
+		SubjectType: subject.SubjectType,
+		Email:       subject.Email,
+		SessionID:   subject.SessionID,
+		IssuedAt:    now,
+		ExpiresAt:   expiresAt,
+		Audience:    req.Audience,
+		Scopes:      scopes,
+		ClientID:    client.ClientID,
+		Actor:       &auth.Actor{Subject: client.ClientID, ClientID: client.ClientID, Actor: subject.Actor},
+	}
+	req.Binding.apply(claims)
+	token, err := s.issueToken(claims, client.TokenFormat, client.ClientID)
+	if err != nil {
+		log.Printf("Failed to issue exchanged token: %v", err)
+		return nil, err
+	}
+
+	log.Printf("Client %s exchanged a token for user %s with audience %s", client.ClientID, subject.Subject, req.Audience)
+	return &TokenResponse{
+		AccessToken:     token,
+		IssuedTokenType: TokenTypeAccessToken,
+		TokenType:       req.Binding.tokenType(),
+		ExpiresIn:       int(time.Until(expiresAt).Seconds()),
+		Scope:           strings.Join(scopes, " "),
+	}, nil
+}
+
+// exchangeScopes resolves the scopes of an exchanged token. Without a
+// requested scope the token gets everything both the client and the subject
+// token allow.
+func exchangeScopes(client *model.OAuthClient, subject *auth.Claims, requested string) ([]string, error) {
+	var allowed []string
+	for _, scope := range client.ExchangeScopes {
+		if subject.HasScope(scope) {
+			allowed = append(allowed, scope)
+		}
+	}
+
+	if requested == "" {
+		if len(allowed) == 0 {
+			return nil, invalidScope("no scopes are available to this client")
+		}
+		return allowed, nil
+	}
+
+	scopes := strings.Fields(requested)
+	for _, scope := range scopes {
+		if !model.StringList(allowed).Contains(scope) {
+			return nil, invalidScope("scope " + scope + " is not allowed")
+		}
+	}
+	return scopes, nil
+}
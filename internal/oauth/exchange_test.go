@@ -0,0 +1,225 @@
+package oauth
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/radiatus-ai/auth-service/internal/auth"
+	"github.com/radiatus-ai/auth-service/internal/model"
+)
+
+type stubAuthService struct {
+	auth.Service
+	subject *auth.Claims
+	issued  *auth.Claims
+	// issuedTo is the client a reference token was issued to.
+	issuedTo string
+}
+
+func (s *stubAuthService) VerifyClaims(token string) (*auth.Claims, error) {
+	if s.subject == nil {
+		return nil, auth.ErrInvalidToken
+	}
+	return s.subject, nil
+}
+
+func (s *stubAuthService) IssueToken(claims *auth.Claims) (string, error) {
+	s.issued = claims
+	return "exchanged-token", nil
+}
+
+func (s *stubAuthService) IssueReferenceToken(claims *auth.Claims, clientID string) (string, error) {
+	s.issued, s.issuedTo = claims, clientID
+	return auth.ReferenceTokenPrefix + "handle", nil
+}
+
+func (s *stubAuthService) RevokeReferenceToken(token, clientID string) error {
+	if clientID != s.issuedTo {
+		return auth.ErrTokenNotOwned
+	}
+	return nil
+}
+
+func newExchangeTestService(subject *auth.Claims) (*service, *stubAuthService) {
+	authService := &stubAuthService{subject: subject}
+	return &service{authService: authService}, authService
+}
+
+func gatewayClient() *model.OAuthClient {
+	return &model.OAuthClient{
+		ClientID:          "gateway",
+		GrantTypes:        model.StringList{model.GrantTypeTokenExchange},
+		ExchangeAudiences: model.StringList{"billing"},
+		ExchangeScopes:    model.StringList{"invoices:read", "invoices:write"},
+		ExchangeTokenTTL:  300,
+	}
+}
+
+func userClaims() *auth.Claims {
+	return &auth.Claims{
+		Subject:   "user-1",
+		IssuedAt:  time.Now(),
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+}
+
+func exchangeRequest(audience, scope string) *ExchangeRequest {
+	return &ExchangeRequest{
+		SubjectToken:     "user-token",
+		SubjectTokenType: TokenTypeAccessToken,
+		Audience:         audience,
+		Scope:            scope,
+	}
+}
+
+func TestExchangeToken_IssuesDownscopedToken(t *testing.T) {
+	svc, authService := newExchangeTestService(userClaims())
+
+	resp, err := svc.ExchangeToken(gatewayClient(), exchangeRequest("billing", "invoices:read"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "exchanged-token", resp.AccessToken)
+	assert.Equal(t, TokenTypeAccessToken, resp.IssuedTokenType)
+	assert.Equal(t, "invoices:read", resp.Scope)
+	assert.InDelta(t, 300, resp.ExpiresIn, 1)
+
+	issued := authService.issued
+	assert.Equal(t, "user-1", issued.Subject)
+	assert.Equal(t, "billing", issued.Audience)
+	assert.Equal(t, []string{"invoices:read"}, issued.Scopes)
+	assert.Equal(t, "gateway", issued.Actor.ClientID)
+}
+
+func TestExchangeToken_KeepsSession(t *testing.T) {
+	subject := userClaims()
+	subject.SessionID = "session-1"
+	svc, authService := newExchangeTestService(subject)
+
+	_, err := svc.ExchangeToken(gatewayClient(), exchangeRequest("billing", ""))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "session-1", authService.issued.SessionID)
+}
+
+func TestExchangeToken_DefaultsToAllowedScopes(t *testing.T) {
+	subject := userClaims()
+	subject.Scopes = []string{"invoices:read"}
+	svc, _ := newExchangeTestService(subject)
+
+	resp, err := svc.ExchangeToken(gatewayClient(), exchangeRequest("billing", ""))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "invoices:read", resp.Scope)
+}
+
+func TestExchangeToken_NeverOutlivesSubjectToken(t *testing.T) {
+	subject := userClaims()
+	subject.ExpiresAt = time.Now().Add(time.Minute)
+	svc, authService := newExchangeTestService(subject)
+
+	_, err := svc.ExchangeToken(gatewayClient(), exchangeRequest("billing", ""))
+
+	assert.NoError(t, err)
+	assert.Equal(t, subject.ExpiresAt, authService.issued.ExpiresAt)
+}
+
+func TestExchangeToken_NestsExistingActor(t *testing.T) {
+	subject := userClaims()
+	subject.Actor = &auth.Actor{Subject: "admin-1"}
+	svc, authService := newExchangeTestService(subject)
+
+	_, err := svc.ExchangeToken(gatewayClient(), exchangeRequest("billing", ""))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "admin-1", authService.issued.Actor.Actor.Subject)
+	assert.Equal(t, "admin-1", authService.issued.Impersonator())
+}
+
+func TestExchangeToken_Rejections(t *testing.T) {
+	audienceBound := userClaims()
+	audienceBound.Audience = "billing"
+	keyBound := userClaims()
+	keyBound.KeyThumbprint = "user-key"
+	certificateBound := userClaims()
+	certificateBound.CertificateThumbprint = "user-cert"
+	otherKey := exchangeRequest("billing", "")
+	otherKey.Binding.KeyThumbprint = "gateway-key"
+	noExchange := gatewayClient()
+	noExchange.GrantTypes = model.StringList{}
+
+	for name, tc := range map[string]struct {
+		client  *model.OAuthClient
+		subject *auth.Claims
+		req     *ExchangeRequest
+		code    string
+	}{
+		"grant not allowed":     {noExchange, userClaims(), exchangeRequest("billing", ""), "unauthorized_client"},
+		"audience not allowed":  {gatewayClient(), userClaims(), exchangeRequest("payroll", ""), "invalid_target"},
+		"missing audience":      {gatewayClient(), userClaims(), exchangeRequest("", ""), "invalid_request"},
+		"scope not allowed":     {gatewayClient(), userClaims(), exchangeRequest("billing", "invoices:delete"), "invalid_scope"},
+		"invalid subject token": {gatewayClient(), nil, exchangeRequest("billing", ""), "invalid_grant"},
+		"already exchanged":     {gatewayClient(), audienceBound, exchangeRequest("billing", ""), "invalid_grant"},
+		"unproven key binding":  {gatewayClient(), keyBound, exchangeRequest("billing", ""), "invalid_grant"},
+		"another key":           {gatewayClient(), keyBound, otherKey, "invalid_grant"},
+		"unproven certificate":  {gatewayClient(), certificateBound, exchangeRequest("billing", ""), "invalid_grant"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			svc, _ := newExchangeTestService(tc.subject)
+
+			_, err := svc.ExchangeToken(tc.client, tc.req)
+
+			var oauthErr *Error
+			assert.True(t, errors.As(err, &oauthErr))
+			assert.Equal(t, tc.code, oauthErr.Code)
+		})
+	}
+}
+
+func TestExchangeToken_CannotWidenSubjectScopes(t *testing.T) {
+	subject := userClaims()
+	subject.Scopes = []string{"invoices:read"}
+	svc, _ := newExchangeTestService(subject)
+
+	_, err := svc.ExchangeToken(gatewayClient(), exchangeRequest("billing", "invoices:write"))
+
+	var oauthErr *Error
+	assert.True(t, errors.As(err, &oauthErr))
+	assert.Equal(t, "invalid_scope", oauthErr.Code)
+}
+
+func TestExchangeToken_DPoP(t *testing.T) {
+	svc, authService := newExchangeTestService(userClaims())
+	client := gatewayClient()
+	client.RequireDPoP = true
+
+	_, err := svc.ExchangeToken(client, exchangeRequest("billing", ""))
+	var oauthErr *Error
+	assert.True(t, errors.As(err, &oauthErr))
+	assert.Equal(t, "invalid_dpop_proof", oauthErr.Code)
+
+	req := exchangeRequest("billing", "")
+	req.Binding.KeyThumbprint = "key-1"
+	resp, err := svc.ExchangeToken(client, req)
+	assert.NoError(t, err)
+	assert.Equal(t, "DPoP", resp.TokenType)
+	assert.Equal(t, "key-1", authService.issued.KeyThumbprint)
+}
+
+func TestExchangeToken_BoundSubjectTokenKeepsBinding(t *testing.T) {
+	subject := userClaims()
+	subject.KeyThumbprint = "key-1"
+	subject.CertificateThumbprint = "cert-1"
+	svc, authService := newExchangeTestService(subject)
+
+	req := exchangeRequest("billing", "")
+	req.Binding = Binding{KeyThumbprint: "key-1", CertificateThumbprint: "cert-1"}
+	resp, err := svc.ExchangeToken(gatewayClient(), req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "DPoP", resp.TokenType)
+	assert.Equal(t, "key-1", authService.issued.KeyThumbprint)
+	assert.Equal(t, "cert-1", authService.issued.CertificateThumbprint)
+}
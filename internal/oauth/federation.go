@@ -0,0 +1,115 @@
+package oauth
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+
+	"github.com/radiatus-ai/auth-service/internal/model"
+	"github.com/radiatus-ai/auth-service/internal/repository"
+)
+
+// federatedSigningMethods are the algorithms accepted on external tokens.
+// Kubernetes and the cloud providers sign with RS256; ES256 covers the rest.
+var federatedSigningMethods = []string{"RS256", "ES256"}
+
+// FederatedToken implements the JWT bearer grant (RFC 7523) for workload
+// identity federation: a workload presents a token from an external issuer,
+// such as a Kubernetes service account token, and gets a token for the
+// service account whose trust policies accept it. The assertion is verified
+// against the JWKS the issuer publishes. Every failure is reported as the
+// same invalid_grant so callers cannot probe which policies exist.
+func (s *service) FederatedToken(clientID, assertion string, binding Binding) (*TokenResponse, error) {
+	if clientID == "" || assertion == "" {
+		return nil, invalidRequest("client_id and assertion are required")
+	}
+	errUntrusted := invalidGrant("assertion is not trusted for this service account")
+
+	account, err := s.serviceAccountRepo.GetByClientID(clientID)
+	if errors.Is(err, repository.ErrServiceAccountNotFound) {
+		return nil, errUntrusted
+	}
+	if err != nil {
+		return nil, err
+	}
+	if account.Disabled {
+		log.Printf("Rejected federated token for disabled service account %s", clientID)
+		return nil, errUntrusted
+	}
+
+	unverified := jwt.MapClaims{}
+	if _, _, err := new(jwt.Parser).ParseUnverified(assertion, unverified); err != nil {
+		return nil, invalidGrant("assertion is not a valid JWT")
+	}
+	issuer, _ := unverified["iss"].(string)
+
+	policies, err := s.serviceAccountRepo.ListTrustPolicies(account.ID)
+	if err != nil {
+		return nil, err
+	}
+	var candidates []model.TrustPolicy
+	for _, policy := range policies {
+		if policy.Issuer == issuer {
+			candidates = append(candidates, policy)
+		}
+	}
+	if len(candidates) == 0 {
+		log.Printf("No trust policy of service account %s accepts issuer %q", clientID, issuer)
+		return nil, errUntrusted
+	}
+
+	claims, err := s.verifyAssertion(issuer, assertion)
+	if err != nil {
+		log.Printf("Rejected federated token from %s for service account %s: %v", issuer, clientID, err)
+		return nil, errUntrusted
+	}
+	subject, _ := claims["sub"].(string)
+	audiences := audienceList(claims["aud"])
+	for _, policy := range candidates {
+		if !policy.Matches(subject, audiences) {
+			continue
+		}
+		log.Printf("Trust policy %s accepted %s from %s for service account %s", policy.ID, subject, issuer, account.ID)
+		return s.ClientCredentials(account, "", binding)
+	}
+
+	log.Printf("No trust policy of service account %s accepts subject %q from %s", clientID, subject, issuer)
+	return nil, errUntrusted
+}
+
+// verifyAssertion checks the assertion's signature with the issuer's
+// published key and requires it to carry an expiry.
+func (s *service) verifyAssertion(issuer, assertion string) (jwt.MapClaims, error) {
+	parser := &jwt.Parser{ValidMethods: federatedSigningMethods}
+	claims := jwt.MapClaims{}
+	token, err := parser.ParseWithClaims(assertion, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return s.keys.Key(issuer, kid)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid || !claims.VerifyExpiresAt(time.Now().Unix(), true) {
+		return nil, errors.New("assertion has no valid expiry")
+	}
+	return claims, nil
+}
+
+// audienceList reads an aud claim, which may be a string or an array.
+func audienceList(claim interface{}) []string {
+	switch aud := claim.(type) {
+	case string:
+		return []string{aud}
+	case []interface{}:
+		audiences := make([]string, 0, len(aud))
+		for _, value := range aud {
+			if s, ok := value.(string); ok {
+				audiences = append(audiences, s)
+			}
+		}
+		return audiences
+	}
+	return nil
+}
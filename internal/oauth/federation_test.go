@@ -0,0 +1,175 @@
+package oauth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/radiatus-ai/auth-service/internal/auth"
+	"github.com/radiatus-ai/auth-service/internal/model"
+	"github.com/radiatus-ai/auth-service/pkg/jwks"
+)
+
+// stubIssuer stands in for a Kubernetes cluster or cloud provider issuing
+// workload tokens, serving OpenID discovery and its JWKS over TLS.
+type stubIssuer struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+}
+
+func newStubIssuer(t *testing.T) *stubIssuer {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	issuer := &stubIssuer{key: key}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":   issuer.server.URL,
+			"jwks_uri": issuer.server.URL + "/openid/v1/jwks",
+		})
+	})
+	mux.HandleFunc("/openid/v1/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "cluster-key",
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	issuer.server = httptest.NewTLSServer(mux)
+	t.Cleanup(issuer.server.Close)
+	return issuer
+}
+
+func (i *stubIssuer) sign(t *testing.T, key *rsa.PrivateKey, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = "cluster-key"
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("Failed to sign assertion: %v", err)
+	}
+	return signed
+}
+
+func (i *stubIssuer) workloadClaims(subject string) jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss": i.server.URL,
+		"sub": subject,
+		"aud": []string{"auth-service"},
+		"iat": time.Now().Unix(),
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+func newFederationTestService(t *testing.T) (*service, *stubServiceAccountRepository, *stubAuthService, *stubIssuer) {
+	issuer := newStubIssuer(t)
+	svc, repo, authService := newServiceAccountTestService()
+	svc.keys = jwks.New(issuer.server.Client(), time.Hour)
+	repo.policies = []model.TrustPolicy{{
+		ServiceAccountID: repo.account.ID,
+		Issuer:           issuer.server.URL,
+		SubjectPattern:   "system:serviceaccount:payments:*",
+		AudiencePattern:  "auth-service",
+	}}
+	return svc, repo, authService, issuer
+}
+
+func TestFederatedToken_IssuesServiceAccountToken(t *testing.T) {
+	svc, repo, authService, issuer := newFederationTestService(t)
+	assertion := issuer.sign(t, issuer.key, issuer.workloadClaims("system:serviceaccount:payments:worker"))
+
+	resp, err := svc.FederatedToken("sa_ci", assertion, Binding{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Bearer", resp.TokenType)
+	assert.Equal(t, auth.SubjectServiceAccount, authService.issued.SubjectType)
+	assert.Equal(t, repo.account.ID.String(), authService.issued.Subject)
+}
+
+func TestFederatedToken_StringAudience(t *testing.T) {
+	svc, _, _, issuer := newFederationTestService(t)
+	claims := issuer.workloadClaims("system:serviceaccount:payments:worker")
+	claims["aud"] = "auth-service"
+
+	_, err := svc.FederatedToken("sa_ci", issuer.sign(t, issuer.key, claims), Binding{})
+
+	assert.NoError(t, err)
+}
+
+func TestFederatedToken_Rejections(t *testing.T) {
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	for name, tc := range map[string]struct {
+		clientID string
+		modify   func(claims jwt.MapClaims)
+		key      *rsa.PrivateKey
+		disabled bool
+	}{
+		"subject outside pattern": {modify: func(c jwt.MapClaims) { c["sub"] = "system:serviceaccount:default:worker" }},
+		"wrong audience":          {modify: func(c jwt.MapClaims) { c["aud"] = []string{"kubernetes"} }},
+		"untrusted issuer":        {modify: func(c jwt.MapClaims) { c["iss"] = "https://evil.example.com" }},
+		"expired":                 {modify: func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Minute).Unix() }},
+		"no expiry":               {modify: func(c jwt.MapClaims) { delete(c, "exp") }},
+		"forged signature":        {key: otherKey},
+		"unknown account":         {clientID: "sa_other"},
+		"disabled account":        {disabled: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			svc, repo, authService, issuer := newFederationTestService(t)
+			repo.account.Disabled = tc.disabled
+			claims := issuer.workloadClaims("system:serviceaccount:payments:worker")
+			if tc.modify != nil {
+				tc.modify(claims)
+			}
+			key := issuer.key
+			if tc.key != nil {
+				key = tc.key
+			}
+			clientID := "sa_ci"
+			if tc.clientID != "" {
+				clientID = tc.clientID
+			}
+
+			_, err := svc.FederatedToken(clientID, issuer.sign(t, key, claims), Binding{})
+
+			var oauthErr *Error
+			if assert.True(t, errors.As(err, &oauthErr)) {
+				assert.Equal(t, "invalid_grant", oauthErr.Code)
+			}
+			assert.Nil(t, authService.issued)
+		})
+	}
+}
+
+func TestFederatedToken_RejectsHMACAssertion(t *testing.T) {
+	svc, _, authService, issuer := newFederationTestService(t)
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, issuer.workloadClaims("system:serviceaccount:payments:worker"))
+	token.Header["kid"] = "cluster-key"
+	assertion, err := token.SignedString([]byte("guessable"))
+	assert.NoError(t, err)
+
+	_, err = svc.FederatedToken("sa_ci", assertion, Binding{})
+
+	assert.Error(t, err)
+	assert.Nil(t, authService.issued)
+}
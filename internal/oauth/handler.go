@@ -0,0 +1,277 @@
+package oauth
+
+import (
+	"crypto/x509"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/radiatus-ai/auth-service/internal/auth"
+	"github.com/radiatus-ai/auth-service/internal/model"
+	"github.com/radiatus-ai/auth-service/internal/repository"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// Token is the OAuth 2.0 token endpoint. Unlike the rest of the API it takes
+// form-encoded requests and returns errors in the RFC 6749 format. Requests
+// with a DPoP proof (RFC 9449) get tokens bound to the proof's key, and
+// requests over a connection authenticated with a client certificate get
+// tokens bound to the certificate (RFC 8705), which clients may also
+// authenticate with instead of a secret.
+func (h *Handler) Token(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+	c.Header("Pragma", "no-cache")
+
+	var binding Binding
+	if proof := c.GetHeader(auth.DPoPHeader); proof != "" {
+		jkt, err := h.service.VerifyDPoPProof(proof, auth.RequestURL(c.Request))
+		if err != nil {
+			respondTokenError(c, err)
+			return
+		}
+		binding.KeyThumbprint = jkt
+	}
+	cert := auth.ClientCertificate(c.Request)
+	if cert != nil {
+		binding.CertificateThumbprint = auth.CertificateThumbprint(cert)
+	}
+
+	clientID, secret, basic := c.Request.BasicAuth()
+	if !basic {
+		clientID, secret = c.PostForm("client_id"), c.PostForm("client_secret")
+	}
+
+	var resp *TokenResponse
+	var err error
+	switch c.PostForm("grant_type") {
+	case model.GrantTypeTokenExchange:
+		var client *model.OAuthClient
+		if client, err = h.authenticateClient(clientID, secret, cert); err == nil {
+			resp, err = h.service.ExchangeToken(client, &ExchangeRequest{
+				SubjectToken:     c.PostForm("subject_token"),
+				SubjectTokenType: c.PostForm("subject_token_type"),
+				Audience:         c.PostForm("audience"),
+				Scope:            c.PostForm("scope"),
+				Binding:          binding,
+			})
+		}
+	case model.GrantTypeClientCredentials:
+		var account *model.ServiceAccount
+		if account, err = h.authenticateServiceAccount(clientID, secret, cert); err == nil {
+			resp, err = h.service.ClientCredentials(account, c.PostForm("scope"), binding)
+		}
+	case model.GrantTypeJWTBearer:
+		resp, err = h.service.FederatedToken(c.PostForm("client_id"), c.PostForm("assertion"), binding)
+	case model.GrantTypeRefreshToken:
+		resp, err = h.service.RefreshToken(c.PostForm("refresh_token"), binding)
+	case "":
+		err = invalidRequest("grant_type is required")
+	default:
+		err = ErrUnsupportedGrantType
+	}
+	if err != nil {
+		if basic && errors.Is(err, ErrInvalidClient) {
+			c.Header("WWW-Authenticate", `Basic realm="token"`)
+		}
+		respondTokenError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// Revoke is the OAuth 2.0 revocation endpoint (RFC 7009). Both clients and
+// service accounts can revoke the reference tokens they were issued,
+// authenticating the same way as at the token endpoint.
+func (h *Handler) Revoke(c *gin.Context) {
+	clientID, basic, err := h.authenticateCaller(c)
+	if err == nil {
+		err = h.service.RevokeToken(clientID, c.PostForm("token"))
+	}
+	if err != nil {
+		if basic && errors.Is(err, ErrInvalidClient) {
+			c.Header("WWW-Authenticate", `Basic realm="token"`)
+		}
+		respondTokenError(c, err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// RequireClient rejects requests not made by a client or service account,
+// authenticating the same way as at the token endpoint, and sets "client_id"
+// on the context. It guards the introspection endpoint, which RFC 7662
+// requires callers to authenticate to.
+func (h *Handler) RequireClient(c *gin.Context) {
+	clientID, _, err := h.authenticateCaller(c)
+	if err != nil {
+		if errors.Is(err, ErrInvalidClient) {
+			c.Header("WWW-Authenticate", `Basic realm="token"`)
+		}
+		respondTokenError(c, err)
+		c.Abort()
+		return
+	}
+	c.Set("client_id", clientID)
+	c.Next()
+}
+
+// authenticateCaller authenticates the client or service account making the
+// request, with HTTP Basic auth, form parameters or its client certificate.
+// basic reports whether the caller used Basic auth.
+func (h *Handler) authenticateCaller(c *gin.Context) (clientID string, basic bool, err error) {
+	clientID, secret, basic := c.Request.BasicAuth()
+	if !basic {
+		clientID, secret = c.PostForm("client_id"), c.PostForm("client_secret")
+	}
+	cert := auth.ClientCertificate(c.Request)
+
+	if strings.HasPrefix(clientID, model.ServiceAccountClientIDPrefix) {
+		_, err = h.authenticateServiceAccount(clientID, secret, cert)
+	} else {
+		_, err = h.authenticateClient(clientID, secret, cert)
+	}
+	return clientID, basic, err
+}
+
+// authenticateClient authenticates a client with its secret or, when it sent
+// none, with its client certificate.
+func (h *Handler) authenticateClient(clientID, secret string, cert *x509.Certificate) (*model.OAuthClient, error) {
+	if secret == "" && cert != nil {
+		return h.service.AuthenticateClientCertificate(clientID, cert)
+	}
+	return h.service.AuthenticateClient(clientID, secret)
+}
+
+func (h *Handler) authenticateServiceAccount(clientID, secret string, cert *x509.Certificate) (*model.ServiceAccount, error) {
+	if secret == "" && cert != nil {
+		return h.service.AuthenticateServiceAccountCertificate(clientID, cert)
+	}
+	return h.service.AuthenticateServiceAccount(clientID, secret)
+}
+
+func (h *Handler) ListClients(c *gin.Context) {
+	clients, err := h.service.ListClients()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list clients"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"clients": clients})
+}
+
+func (h *Handler) CreateClient(c *gin.Context) {
+	actorID, ok := actor(c)
+	if !ok {
+		return
+	}
+	var params ClientParams
+	if err := c.ShouldBindJSON(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	client, secret, err := h.service.CreateClient(actorID, params)
+	if err != nil {
+		respondError(c, err, "Failed to create client")
+		return
+	}
+
+	// The secret is only ever shown here; only its hash is stored.
+	c.JSON(http.StatusCreated, gin.H{"client": client, "client_secret": secret})
+}
+
+func (h *Handler) UpdateClient(c *gin.Context) {
+	actorID, ok := actor(c)
+	if !ok {
+		return
+	}
+	id, ok := clientParam(c)
+	if !ok {
+		return
+	}
+	var params ClientParams
+	if err := c.ShouldBindJSON(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	client, err := h.service.UpdateClient(actorID, id, params)
+	if err != nil {
+		respondError(c, err, "Failed to update client")
+		return
+	}
+
+	c.JSON(http.StatusOK, client)
+}
+
+func (h *Handler) DisableClient(c *gin.Context) {
+	actorID, ok := actor(c)
+	if !ok {
+		return
+	}
+	id, ok := clientParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DisableClient(actorID, id); err != nil {
+		respondError(c, err, "Failed to disable client")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func actor(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user"})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func clientParam(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("clientID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid clientID"})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func respondTokenError(c *gin.Context, err error) {
+	var oauthErr *Error
+	if !errors.As(err, &oauthErr) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server_error"})
+		return
+	}
+	body := gin.H{"error": oauthErr.Code}
+	if oauthErr.Description != "" {
+		body["error_description"] = oauthErr.Description
+	}
+	c.JSON(oauthErr.Status, body)
+}
+
+func respondError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, repository.ErrClientNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrInvalidClientPolicy):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
@@ -0,0 +1,48 @@
+package oauth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequireClient(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc, _, _ := newServiceAccountTestService(secretFor("live-secret"))
+	router := gin.New()
+	router.POST("/introspect", NewHandler(svc).RequireClient, func(c *gin.Context) {
+		c.String(http.StatusOK, c.GetString("client_id"))
+	})
+
+	tests := []struct {
+		name   string
+		id     string
+		secret string
+		status int
+	}{
+		{"valid credentials", "sa_ci", "live-secret", http.StatusOK},
+		{"wrong secret", "sa_ci", "wrong-secret", http.StatusUnauthorized},
+		{"unknown client", "sa_other", "live-secret", http.StatusUnauthorized},
+		{"no credentials", "", "", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/introspect", nil)
+			if tt.id != "" {
+				req.SetBasicAuth(tt.id, tt.secret)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			if tt.status == http.StatusOK {
+				assert.Equal(t, "sa_ci", w.Body.String())
+			} else {
+				assert.NotContains(t, w.Body.String(), "sa_ci")
+			}
+		})
+	}
+}
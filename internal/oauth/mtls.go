@@ -0,0 +1,58 @@
+package oauth
+
+import (
+	"crypto/x509"
+	"errors"
+	"log"
+
+	"github.com/radiatus-ai/auth-service/internal/model"
+	"github.com/radiatus-ai/auth-service/internal/repository"
+)
+
+// AuthenticateClientCertificate authenticates a client with the certificate
+// it connected with instead of a secret (RFC 8705 section 2.1). The server
+// has already verified the certificate against its client CA bundle; its
+// subject must be the one registered for the client.
+func (s *service) AuthenticateClientCertificate(clientID string, cert *x509.Certificate) (*model.OAuthClient, error) {
+	if clientID == "" || cert == nil {
+		return nil, ErrInvalidClient
+	}
+	client, err := s.clientRepo.GetByClientID(clientID)
+	if errors.Is(err, repository.ErrClientNotFound) {
+		return nil, ErrInvalidClient
+	}
+	if err != nil {
+		return nil, err
+	}
+	if client.Disabled || !subjectMatches(client.TLSSubjectDN, cert) {
+		log.Printf("Certificate authentication failed for client %s with subject %q", clientID, cert.Subject)
+		return nil, ErrInvalidClient
+	}
+	return client, nil
+}
+
+// AuthenticateServiceAccountCertificate authenticates a service account with
+// the certificate it connected with, like AuthenticateClientCertificate.
+func (s *service) AuthenticateServiceAccountCertificate(clientID string, cert *x509.Certificate) (*model.ServiceAccount, error) {
+	if clientID == "" || cert == nil {
+		return nil, ErrInvalidClient
+	}
+	account, err := s.serviceAccountRepo.GetByClientID(clientID)
+	if errors.Is(err, repository.ErrServiceAccountNotFound) {
+		return nil, ErrInvalidClient
+	}
+	if err != nil {
+		return nil, err
+	}
+	if account.Disabled || !subjectMatches(account.TLSSubjectDN, cert) {
+		log.Printf("Certificate authentication failed for service account %s with subject %q", clientID, cert.Subject)
+		return nil, ErrInvalidClient
+	}
+	return account, nil
+}
+
+// subjectMatches compares the subject of cert, in RFC 4514 form, with the
+// one registered. Clients without one cannot use certificates.
+func subjectMatches(subjectDN string, cert *x509.Certificate) bool {
+	return subjectDN != "" && cert.Subject.String() == subjectDN
+}
@@ -0,0 +1,53 @@
+package oauth
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthenticateServiceAccountCertificate(t *testing.T) {
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: "ci", Organization: []string{"Radiatus"}}}
+
+	for name, tc := range map[string]struct {
+		clientID  string
+		subjectDN string
+		disabled  bool
+		wantErr   bool
+	}{
+		"matching subject": {clientID: "sa_ci", subjectDN: "CN=ci,O=Radiatus"},
+		"other subject":    {clientID: "sa_ci", subjectDN: "CN=deploy,O=Radiatus", wantErr: true},
+		"no subject":       {clientID: "sa_ci", wantErr: true},
+		"disabled account": {clientID: "sa_ci", subjectDN: "CN=ci,O=Radiatus", disabled: true, wantErr: true},
+		"unknown account":  {clientID: "sa_other", subjectDN: "CN=ci,O=Radiatus", wantErr: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			svc, repo, _ := newServiceAccountTestService()
+			repo.account.TLSSubjectDN = tc.subjectDN
+			repo.account.Disabled = tc.disabled
+
+			account, err := svc.AuthenticateServiceAccountCertificate(tc.clientID, cert)
+
+			if tc.wantErr {
+				assert.True(t, errors.Is(err, ErrInvalidClient))
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, repo.account, account)
+		})
+	}
+}
+
+func TestClientCredentials_CertificateBound(t *testing.T) {
+	svc, repo, authService := newServiceAccountTestService()
+
+	resp, err := svc.ClientCredentials(repo.account, "", Binding{CertificateThumbprint: "cert-1"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Bearer", resp.TokenType)
+	assert.Equal(t, "cert-1", authService.issued.CertificateThumbprint)
+	assert.Empty(t, authService.issued.KeyThumbprint)
+}
@@ -0,0 +1,34 @@
+package oauth
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/radiatus-ai/auth-service/internal/auth"
+)
+
+// RefreshToken redeems a refresh token issued at login for a new access token
+// and refresh token (RFC 6749 section 6). Refresh tokens belong to a user's
+// session rather than to a client, so no client authentication is required;
+// those of a session bound to a DPoP key need a proof signed by it instead.
+// Sessions are never bound to client certificates.
+func (s *service) RefreshToken(refreshToken string, binding Binding) (*TokenResponse, error) {
+	if refreshToken == "" {
+		return nil, invalidRequest("refresh_token is required")
+	}
+	tokens, err := s.authService.RefreshAccessToken(refreshToken, binding.KeyThumbprint)
+	if errors.Is(err, auth.ErrInvalidDPoPProof) {
+		return nil, invalidDPoPProof("refresh token is bound to another DPoP key")
+	}
+	if err != nil {
+		log.Printf("Rejected refresh token: %v", err)
+		return nil, invalidGrant("refresh token is invalid or expired")
+	}
+	return &TokenResponse{
+		AccessToken:  tokens.AccessToken,
+		TokenType:    Binding{KeyThumbprint: tokens.KeyThumbprint}.tokenType(),
+		ExpiresIn:    int(time.Until(tokens.ExpiresAt).Seconds()),
+		RefreshToken: tokens.RefreshToken,
+	}, nil
+}
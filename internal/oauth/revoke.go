@@ -0,0 +1,43 @@
+package oauth
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/radiatus-ai/auth-service/internal/auth"
+	"github.com/radiatus-ai/auth-service/internal/model"
+)
+
+var errUnsupportedTokenType = &Error{Code: "unsupported_token_type", Description: "only reference tokens can be revoked", Status: http.StatusBadRequest}
+
+// issueToken issues claims as a JWT or, for clients configured for them, as
+// a reference token recorded against clientID.
+func (s *service) issueToken(claims *auth.Claims, format, clientID string) (string, error) {
+	if format == model.TokenFormatReference {
+		return s.authService.IssueReferenceToken(claims, clientID)
+	}
+	return s.authService.IssueToken(claims)
+}
+
+// RevokeToken revokes a reference token issued to clientID (RFC 7009).
+// JWTs cannot be revoked one at a time, so they are refused. Tokens that do
+// not exist are not an error, as the spec requires.
+func (s *service) RevokeToken(clientID, token string) error {
+	if token == "" {
+		return invalidRequest("token is required")
+	}
+	if !strings.HasPrefix(token, auth.ReferenceTokenPrefix) {
+		return errUnsupportedTokenType
+	}
+	err := s.authService.RevokeReferenceToken(token, clientID)
+	if errors.Is(err, auth.ErrTokenNotOwned) {
+		return &Error{Code: "unauthorized_client", Description: "token was issued to another client", Status: http.StatusBadRequest}
+	}
+	if err != nil {
+		log.Printf("Failed to revoke token for client %s: %v", clientID, err)
+		return err
+	}
+	return nil
+}
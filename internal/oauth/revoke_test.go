@@ -0,0 +1,63 @@
+package oauth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/radiatus-ai/auth-service/internal/auth"
+	"github.com/radiatus-ai/auth-service/internal/model"
+)
+
+func TestExchangeToken_ReferenceFormat(t *testing.T) {
+	svc, authService := newExchangeTestService(userClaims())
+	client := gatewayClient()
+	client.TokenFormat = model.TokenFormatReference
+
+	resp, err := svc.ExchangeToken(client, exchangeRequest("billing", "invoices:read"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, auth.ReferenceTokenPrefix+"handle", resp.AccessToken)
+	assert.Equal(t, "gateway", authService.issuedTo)
+	assert.Equal(t, "billing", authService.issued.Audience)
+}
+
+func TestClientCredentials_ReferenceFormat(t *testing.T) {
+	svc, repo, authService := newServiceAccountTestService()
+	repo.account.TokenFormat = model.TokenFormatReference
+
+	resp, err := svc.ClientCredentials(repo.account, "", Binding{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, auth.ReferenceTokenPrefix+"handle", resp.AccessToken)
+	assert.Equal(t, "sa_ci", authService.issuedTo)
+}
+
+func TestRevokeToken(t *testing.T) {
+	svc, authService := newExchangeTestService(nil)
+	authService.issuedTo = "gateway"
+
+	assert.NoError(t, svc.RevokeToken("gateway", auth.ReferenceTokenPrefix+"handle"))
+
+	err := svc.RevokeToken("other", auth.ReferenceTokenPrefix+"handle")
+	if assert.IsType(t, &Error{}, err) {
+		assert.Equal(t, "unauthorized_client", err.(*Error).Code)
+	}
+	assert.Equal(t, errUnsupportedTokenType, svc.RevokeToken("gateway", "eyJhbGciOi.jwt.token"))
+	err = svc.RevokeToken("gateway", "")
+	if assert.IsType(t, &Error{}, err) {
+		assert.Equal(t, "invalid_request", err.(*Error).Code)
+	}
+}
+
+func TestValidateClientParams_TokenFormat(t *testing.T) {
+	params := ClientParams{Name: "gateway"}
+	assert.NoError(t, validateClientParams(&params))
+	assert.Equal(t, model.TokenFormatJWT, params.TokenFormat)
+
+	params = ClientParams{Name: "gateway", TokenFormat: model.TokenFormatReference}
+	assert.NoError(t, validateClientParams(&params))
+
+	params = ClientParams{Name: "gateway", TokenFormat: "paseto"}
+	assert.ErrorIs(t, validateClientParams(&params), ErrInvalidClientPolicy)
+}
@@ -0,0 +1,210 @@
+package oauth
+
+import (
+	"crypto/x509"
+	"errors"
+	"log"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/radiatus-ai/auth-service/internal/audit"
+	"github.com/radiatus-ai/auth-service/internal/auth"
+	"github.com/radiatus-ai/auth-service/internal/credential"
+	"github.com/radiatus-ai/auth-service/internal/model"
+	"github.com/radiatus-ai/auth-service/internal/repository"
+	"github.com/radiatus-ai/auth-service/pkg/jwks"
+)
+
+const (
+	defaultExchangeTTL = 300
+	maxExchangeTTL     = 3600
+)
+
+var supportedGrantTypes = []string{model.GrantTypeTokenExchange}
+
+// Service implements the /token endpoint and the management of the clients
+// allowed to call it. Service accounts, which are managed by their
+// organizations, authenticate to the same endpoint.
+type Service interface {
+	AuthenticateClient(clientID, secret string) (*model.OAuthClient, error)
+	AuthenticateClientCertificate(clientID string, cert *x509.Certificate) (*model.OAuthClient, error)
+	ExchangeToken(client *model.OAuthClient, req *ExchangeRequest) (*TokenResponse, error)
+	AuthenticateServiceAccount(clientID, secret string) (*model.ServiceAccount, error)
+	AuthenticateServiceAccountCertificate(clientID string, cert *x509.Certificate) (*model.ServiceAccount, error)
+	ClientCredentials(account *model.ServiceAccount, scope string, binding Binding) (*TokenResponse, error)
+	FederatedToken(clientID, assertion string, binding Binding) (*TokenResponse, error)
+	RefreshToken(refreshToken string, binding Binding) (*TokenResponse, error)
+	VerifyDPoPProof(proof, url string) (string, error)
+	RevokeToken(clientID, token string) error
+
+	CreateClient(actorID uuid.UUID, params ClientParams) (*model.OAuthClient, string, error)
+	ListClients() ([]model.OAuthClient, error)
+	UpdateClient(actorID, id uuid.UUID, params ClientParams) (*model.OAuthClient, error)
+	DisableClient(actorID, id uuid.UUID) error
+}
+
+type service struct {
+	authService        auth.Service
+	clientRepo         repository.ClientRepository
+	serviceAccountRepo repository.ServiceAccountRepository
+	keys               *jwks.Client
+	audit              audit.Logger
+}
+
+// NewService returns the token endpoint service. keys fetches the signing
+// keys of the external issuers named in service account trust policies.
+func NewService(authService auth.Service, clientRepo repository.ClientRepository, serviceAccountRepo repository.ServiceAccountRepository, keys *jwks.Client, auditLog audit.Logger) Service {
+	return &service{
+		authService:        authService,
+		clientRepo:         clientRepo,
+		serviceAccountRepo: serviceAccountRepo,
+		keys:               keys,
+		audit:              auditLog,
+	}
+}
+
+// ClientParams are the fields an admin sets on a client.
+type ClientParams struct {
+	Name              string   `json:"name"`
+	GrantTypes        []string `json:"grant_types"`
+	ExchangeAudiences []string `json:"exchange_audiences"`
+	ExchangeScopes    []string `json:"exchange_scopes"`
+	ExchangeTokenTTL  int      `json:"exchange_token_ttl_seconds"`
+	RequireDPoP       bool     `json:"require_dpop"`
+	TLSSubjectDN      string   `json:"tls_client_auth_subject_dn"`
+	TokenFormat       string   `json:"access_token_format"`
+}
+
+// AuthenticateClient checks a client's credentials. Unknown clients, wrong
+// secrets and disabled clients are indistinguishable to the caller.
+func (s *service) AuthenticateClient(clientID, secret string) (*model.OAuthClient, error) {
+	if clientID == "" || secret == "" {
+		return nil, ErrInvalidClient
+	}
+	client, err := s.clientRepo.GetByClientID(clientID)
+	if errors.Is(err, repository.ErrClientNotFound) {
+		return nil, ErrInvalidClient
+	}
+	if err != nil {
+		return nil, err
+	}
+	if client.Disabled || !credential.Matches(secret, client.SecretHash) {
+		log.Printf("Client authentication failed for %s", clientID)
+		return nil, ErrInvalidClient
+	}
+	return client, nil
+}
+
+func (s *service) CreateClient(actorID uuid.UUID, params ClientParams) (*model.OAuthClient, string, error) {
+	if err := validateClientParams(&params); err != nil {
+		return nil, "", err
+	}
+
+	clientID, err := credential.Generate(16)
+	if err != nil {
+		return nil, "", err
+	}
+	secret, err := credential.Generate(32)
+	if err != nil {
+		return nil, "", err
+	}
+
+	client := &model.OAuthClient{
+		ClientID:   clientID,
+		SecretHash: credential.Hash(secret),
+	}
+	applyClientParams(client, params)
+	if err := s.clientRepo.Create(client); err != nil {
+		log.Printf("Failed to create client: %v", err)
+		return nil, "", err
+	}
+
+	s.audit.Record(audit.PlatformEvent(actorID, audit.ActionClientCreated, "oauth_client", client.ID.String()))
+	return client, secret, nil
+}
+
+func (s *service) ListClients() ([]model.OAuthClient, error) {
+	return s.clientRepo.List()
+}
+
+func (s *service) UpdateClient(actorID, id uuid.UUID, params ClientParams) (*model.OAuthClient, error) {
+	if err := validateClientParams(&params); err != nil {
+		return nil, err
+	}
+	client, err := s.clientRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	applyClientParams(client, params)
+	if err := s.clientRepo.Update(client); err != nil {
+		log.Printf("Failed to update client %s: %v", id, err)
+		return nil, err
+	}
+
+	s.audit.Record(audit.PlatformEvent(actorID, audit.ActionClientUpdated, "oauth_client", client.ID.String()))
+	return client, nil
+}
+
+func (s *service) DisableClient(actorID, id uuid.UUID) error {
+	client, err := s.clientRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if client.Disabled {
+		return nil
+	}
+
+	client.Disabled = true
+	if err := s.clientRepo.Update(client); err != nil {
+		log.Printf("Failed to disable client %s: %v", id, err)
+		return err
+	}
+
+	s.audit.Record(audit.PlatformEvent(actorID, audit.ActionClientDisabled, "oauth_client", client.ID.String()))
+	return nil
+}
+
+func validateClientParams(params *ClientParams) error {
+	params.Name = strings.TrimSpace(params.Name)
+	if params.Name == "" {
+		return ErrInvalidClientPolicy
+	}
+	for _, grantType := range params.GrantTypes {
+		if !model.StringList(supportedGrantTypes).Contains(grantType) {
+			return ErrInvalidClientPolicy
+		}
+	}
+	if params.ExchangeTokenTTL == 0 {
+		params.ExchangeTokenTTL = defaultExchangeTTL
+	}
+	if params.ExchangeTokenTTL < 0 || params.ExchangeTokenTTL > maxExchangeTTL {
+		return ErrInvalidClientPolicy
+	}
+	if params.TokenFormat == "" {
+		params.TokenFormat = model.TokenFormatJWT
+	}
+	if !model.ValidTokenFormat(params.TokenFormat) {
+		return ErrInvalidClientPolicy
+	}
+	return nil
+}
+
+func applyClientParams(client *model.OAuthClient, params ClientParams) {
+	client.Name = params.Name
+	client.GrantTypes = nonNil(params.GrantTypes)
+	client.ExchangeAudiences = nonNil(params.ExchangeAudiences)
+	client.ExchangeScopes = nonNil(params.ExchangeScopes)
+	client.ExchangeTokenTTL = params.ExchangeTokenTTL
+	client.RequireDPoP = params.RequireDPoP
+	client.TLSSubjectDN = strings.TrimSpace(params.TLSSubjectDN)
+	client.TokenFormat = params.TokenFormat
+}
+
+func nonNil(values []string) model.StringList {
+	if values == nil {
+		return model.StringList{}
+	}
+	return model.StringList(values)
+}
@@ -0,0 +1,115 @@
+package organization
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/radiatus-ai/auth-service/internal/auth"
+	"github.com/radiatus-ai/auth-service/internal/credential"
+	"github.com/radiatus-ai/auth-service/internal/model"
+	"github.com/radiatus-ai/auth-service/internal/rbac"
+)
+
+func TestCreateAPIKey(t *testing.T) {
+	svc, orgRepo, _ := newTestService()
+	orgID, adminID := orgRepo.addOrg(nil), uuid.New()
+	orgRepo.addMember(orgID, adminID, rbac.RoleAdmin)
+
+	key, plaintext, err := svc.CreateAPIKey(adminID, orgID, APIKeyInput{
+		Name:         "billing sync",
+		Scopes:       []string{string(rbac.PermOrgRead), string(rbac.PermMembersRead)},
+		AllowedCIDRs: []string{"10.0.0.0/8", "192.0.2.7"},
+	})
+
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(plaintext, auth.APIKeyPrefix))
+	assert.Equal(t, credential.Hash(plaintext), key.KeyHash)
+	assert.Equal(t, model.StringList{"10.0.0.0/8", "192.0.2.7/32"}, key.AllowedCIDRs)
+}
+
+func TestCreateAPIKey_Validation(t *testing.T) {
+	for name, tc := range map[string]struct {
+		input APIKeyInput
+		want  error
+	}{
+		"no scopes":        {APIKeyInput{Name: "k"}, ErrInvalidAPIKey},
+		"unknown scope":    {APIKeyInput{Name: "k", Scopes: []string{"everything"}}, ErrInvalidAPIKey},
+		"bad network":      {APIKeyInput{Name: "k", Scopes: []string{string(rbac.PermOrgRead)}, AllowedCIDRs: []string{"10.0.0.0/99"}}, ErrInvalidAPIKey},
+		"scope not held":   {APIKeyInput{Name: "k", Scopes: []string{string(rbac.PermOrgDelete)}}, ErrForbidden},
+		"missing key name": {APIKeyInput{Scopes: []string{string(rbac.PermOrgRead)}}, ErrInvalidAPIKey},
+	} {
+		t.Run(name, func(t *testing.T) {
+			svc, orgRepo, _ := newTestService()
+			orgID, adminID := orgRepo.addOrg(nil), uuid.New()
+			orgRepo.addMember(orgID, adminID, rbac.RoleAdmin)
+
+			_, _, err := svc.CreateAPIKey(adminID, orgID, tc.input)
+
+			assert.ErrorIs(t, err, tc.want)
+		})
+	}
+}
+
+func TestRotateAPIKey_KeepsOldKeyForGracePeriod(t *testing.T) {
+	svc, orgRepo, _ := newTestService()
+	orgID, adminID := orgRepo.addOrg(nil), uuid.New()
+	orgRepo.addMember(orgID, adminID, rbac.RoleAdmin)
+	old, _, err := svc.CreateAPIKey(adminID, orgID, APIKeyInput{Name: "sync", Scopes: []string{string(rbac.PermOrgRead)}})
+	assert.NoError(t, err)
+
+	key, plaintext, err := svc.RotateAPIKey(adminID, orgID, old.ID, time.Hour)
+
+	assert.NoError(t, err)
+	assert.NotEqual(t, old.ID, key.ID)
+	assert.Equal(t, &old.ID, key.RotatedFromID)
+	assert.Equal(t, old.Scopes, key.Scopes)
+	assert.Equal(t, credential.Hash(plaintext), key.KeyHash)
+
+	stored, _ := svc.apiKeyRepo.GetByID(old.ID)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), *stored.ExpiresAt, time.Second)
+	assert.False(t, stored.Expired(time.Now()))
+
+	_, _, err = svc.RotateAPIKey(adminID, orgID, old.ID, 30*24*time.Hour)
+	assert.ErrorIs(t, err, ErrInvalidAPIKey)
+}
+
+func TestRotateAPIKey_RequiresKeyScopes(t *testing.T) {
+	svc, orgRepo, _ := newTestService()
+	orgID, ownerID, adminID := orgRepo.addOrg(nil), uuid.New(), uuid.New()
+	orgRepo.addMember(orgID, ownerID, rbac.RoleOwner)
+	orgRepo.addMember(orgID, adminID, rbac.RoleAdmin)
+	old, _, err := svc.CreateAPIKey(ownerID, orgID, APIKeyInput{Name: "teardown", Scopes: []string{string(rbac.PermOrgDelete)}})
+	assert.NoError(t, err)
+
+	_, _, err = svc.RotateAPIKey(adminID, orgID, old.ID, time.Hour)
+	assert.ErrorIs(t, err, ErrForbidden)
+
+	manager, _, err := svc.CreateAPIKey(ownerID, orgID, APIKeyInput{Name: "keys", Scopes: []string{string(rbac.PermAPIKeysManage)}})
+	assert.NoError(t, err)
+	_, _, err = svc.RotateAPIKey(manager.ID, orgID, old.ID, time.Hour)
+	assert.ErrorIs(t, err, ErrForbidden)
+
+	_, _, err = svc.RotateAPIKey(ownerID, orgID, old.ID, time.Hour)
+	assert.NoError(t, err)
+}
+
+func TestEffectiveAccess_APIKeyScopes(t *testing.T) {
+	svc, orgRepo, _ := newTestService()
+	orgID, adminID := orgRepo.addOrg(nil), uuid.New()
+	orgRepo.addMember(orgID, adminID, rbac.RoleAdmin)
+	key, _, err := svc.CreateAPIKey(adminID, orgID, APIKeyInput{Name: "sync", Scopes: []string{string(rbac.PermMembersRead)}})
+	assert.NoError(t, err)
+
+	access, err := svc.EffectiveAccess(orgID, key.ID)
+
+	assert.NoError(t, err)
+	assert.True(t, access.Has(rbac.PermMembersRead))
+	assert.False(t, access.Has(rbac.PermOrgRead))
+
+	_, err = svc.EffectiveAccess(orgRepo.addOrg(nil), key.ID)
+	assert.ErrorIs(t, err, ErrNotMember)
+}
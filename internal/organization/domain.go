@@ -0,0 +1,133 @@
+package organization
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/radiatus-ai/auth-service/internal/model"
+	"github.com/radiatus-ai/auth-service/internal/rbac"
+	"github.com/radiatus-ai/auth-service/internal/repository"
+)
+
+const (
+	domainChallengePrefix = "_radiatus-challenge."
+	domainChallengeValue  = "radiatus-domain-verification="
+)
+
+var domainPattern = regexp.MustCompile(`^([a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?\.)+[a-z]{2,63}$`)
+
+// DomainChallenge is the DNS TXT record an organization must publish to prove
+// it controls a domain.
+type DomainChallenge struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+func Challenge(domain *model.OrganizationDomain) DomainChallenge {
+	return DomainChallenge{
+		Name:  domainChallengePrefix + domain.Domain,
+		Value: domainChallengeValue + domain.VerificationToken,
+	}
+}
+
+func (s *service) ListDomains(actorID, orgID uuid.UUID) ([]model.OrganizationDomain, error) {
+	if err := s.authorize(actorID, orgID, rbac.PermOrgRead); err != nil {
+		return nil, err
+	}
+	return s.domainRepo.ListByOrganization(orgID)
+}
+
+func (s *service) AddDomain(actorID, orgID uuid.UUID, name string) (*model.OrganizationDomain, error) {
+	if err := s.authorize(actorID, orgID, rbac.PermOrgUpdate); err != nil {
+		return nil, err
+	}
+
+	name = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(name)), ".")
+	if !domainPattern.MatchString(name) {
+		return nil, ErrInvalidDomain
+	}
+
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return nil, err
+	}
+
+	domain := &model.OrganizationDomain{
+		OrganizationID:    orgID,
+		Domain:            name,
+		VerificationToken: hex.EncodeToString(token),
+	}
+	if err := s.domainRepo.Create(domain); err != nil {
+		log.Printf("Failed to add domain: %v", err)
+		return nil, err
+	}
+	return domain, nil
+}
+
+// VerifyDomain looks up the challenge TXT record and marks the domain verified
+// when it carries the expected token.
+func (s *service) VerifyDomain(actorID, orgID, domainID uuid.UUID) (*model.OrganizationDomain, error) {
+	if err := s.authorize(actorID, orgID, rbac.PermOrgUpdate); err != nil {
+		return nil, err
+	}
+
+	domain, err := s.getOrgDomain(orgID, domainID)
+	if err != nil {
+		return nil, err
+	}
+	if domain.VerifiedAt != nil {
+		return domain, nil
+	}
+
+	if existing, err := s.domainRepo.GetVerified(domain.Domain); err == nil && existing.OrganizationID != orgID {
+		return nil, ErrDomainTaken
+	} else if err != nil && !errors.Is(err, repository.ErrDomainNotFound) {
+		return nil, err
+	}
+
+	challenge := Challenge(domain)
+	records, err := s.lookupTXT(challenge.Name)
+	if err != nil {
+		log.Printf("Failed to look up TXT records for %s: %v", challenge.Name, err)
+		return nil, ErrDomainNotVerified
+	}
+	for _, record := range records {
+		if strings.TrimSpace(record) == challenge.Value {
+			now := time.Now()
+			if err := s.domainRepo.MarkVerified(domain.ID, now); err != nil {
+				return nil, err
+			}
+			domain.VerifiedAt = &now
+			return domain, nil
+		}
+	}
+	return nil, ErrDomainNotVerified
+}
+
+func (s *service) DeleteDomain(actorID, orgID, domainID uuid.UUID) error {
+	if err := s.authorize(actorID, orgID, rbac.PermOrgUpdate); err != nil {
+		return err
+	}
+	if _, err := s.getOrgDomain(orgID, domainID); err != nil {
+		return err
+	}
+	return s.domainRepo.Delete(domainID)
+}
+
+func (s *service) getOrgDomain(orgID, domainID uuid.UUID) (*model.OrganizationDomain, error) {
+	domain, err := s.domainRepo.GetByID(domainID)
+	if err != nil {
+		return nil, err
+	}
+	if domain.OrganizationID != orgID {
+		return nil, repository.ErrDomainNotFound
+	}
+	return domain, nil
+}
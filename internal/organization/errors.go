@@ -0,0 +1,33 @@
+package organization
+
+import "errors"
+
+var (
+	ErrForbidden                 = errors.New("forbidden")
+	ErrNotMember                 = errors.New("user is not a member of the organization")
+	ErrInvalidRole               = errors.New("invalid role")
+	ErrInvalidParentTeam         = errors.New("parent team must belong to the same organization")
+	ErrTeamCycle                 = errors.New("team hierarchy would contain a cycle")
+	ErrTeamHasChildren           = errors.New("team has child teams")
+	ErrInvalidParentOrg          = errors.New("invalid parent organization")
+	ErrOrganizationCycle         = errors.New("organization hierarchy would contain a cycle")
+	ErrHasChildOrgs              = errors.New("organization has child organizations")
+	ErrInvalidSlug               = errors.New("slug must be lowercase letters, digits and hyphens")
+	ErrInvalidBranding           = errors.New("invalid branding")
+	ErrInvalidSettings           = errors.New("invalid settings")
+	ErrInvalidDomain             = errors.New("invalid domain")
+	ErrDomainTaken               = errors.New("domain is verified by another organization")
+	ErrDomainNotVerified         = errors.New("domain verification record not found")
+	ErrInvalidTransfer           = errors.New("ownership can only be transferred to another member")
+	ErrTransferExpired           = errors.New("ownership transfer is no longer valid")
+	ErrLastOwner                 = errors.New("the last owner cannot leave the organization")
+	ErrParentDeleted             = errors.New("parent organization is deleted")
+	ErrInvalidServiceAccount     = errors.New("invalid service account")
+	ErrInvalidServiceAccountRole = errors.New("service accounts can only be granted the admin or member role")
+	ErrTooManySecrets            = errors.New("service account has too many secrets")
+	ErrInvalidAPIKey             = errors.New("API keys need a name, known scopes and valid networks")
+	ErrAPIKeyExpired             = errors.New("API key has expired")
+	ErrInvalidTrustPolicy        = errors.New("trust policies need an https issuer and subject and audience patterns narrower than *")
+	ErrTooManyTrustPolicies      = errors.New("service account has too many trust policies")
+	// Add other organization-related errors here
+)
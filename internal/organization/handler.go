@@ -0,0 +1,975 @@
+package organization
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/radiatus-ai/auth-service/internal/model"
+	"github.com/radiatus-ai/auth-service/internal/repository"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+func (h *Handler) ListOrganizations(c *gin.Context) {
+	actorID, ok := actor(c)
+	if !ok {
+		return
+	}
+
+	orgs, err := h.service.ListOrganizations(actorID)
+	if err != nil {
+		respondError(c, err, "Failed to list organizations")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"organizations": orgs})
+}
+
+func (h *Handler) CreateOrganization(c *gin.Context) {
+	actorID, ok := actor(c)
+	if !ok {
+		return
+	}
+
+	var req OrganizationInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Name == nil || *req.Name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Organization name is required"})
+		return
+	}
+
+	org, err := h.service.CreateOrganization(actorID, req)
+	if err != nil {
+		respondError(c, err, "Failed to create organization")
+		return
+	}
+
+	c.JSON(http.StatusCreated, org)
+}
+
+func (h *Handler) GetOrganization(c *gin.Context) {
+	actorID, orgID, ok := actorAndOrg(c)
+	if !ok {
+		return
+	}
+
+	org, err := h.service.GetOrganization(actorID, orgID)
+	if err != nil {
+		respondError(c, err, "Failed to retrieve organization")
+		return
+	}
+
+	c.JSON(http.StatusOK, org)
+}
+
+func (h *Handler) UpdateOrganization(c *gin.Context) {
+	actorID, orgID, ok := actorAndOrg(c)
+	if !ok {
+		return
+	}
+
+	var req OrganizationInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Name != nil && *req.Name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Organization name cannot be empty"})
+		return
+	}
+
+	org, err := h.service.UpdateOrganization(actorID, orgID, req)
+	if err != nil {
+		respondError(c, err, "Failed to update organization")
+		return
+	}
+
+	c.JSON(http.StatusOK, org)
+}
+
+func (h *Handler) DeleteOrganization(c *gin.Context) {
+	actorID, orgID, ok := actorAndOrg(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteOrganization(actorID, orgID); err != nil {
+		respondError(c, err, "Failed to delete organization")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) ListDeletedOrganizations(c *gin.Context) {
+	actorID, ok := actor(c)
+	if !ok {
+		return
+	}
+
+	orgs, err := h.service.ListDeletedOrganizations(actorID)
+	if err != nil {
+		respondError(c, err, "Failed to list deleted organizations")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"organizations": orgs})
+}
+
+func (h *Handler) RestoreOrganization(c *gin.Context) {
+	actorID, orgID, ok := actorAndOrg(c)
+	if !ok {
+		return
+	}
+
+	org, err := h.service.RestoreOrganization(actorID, orgID)
+	if err != nil {
+		respondError(c, err, "Failed to restore organization")
+		return
+	}
+
+	c.JSON(http.StatusOK, org)
+}
+
+func (h *Handler) GetOrganizationTree(c *gin.Context) {
+	actorID, orgID, ok := actorAndOrg(c)
+	if !ok {
+		return
+	}
+
+	tree, err := h.service.GetOrganizationTree(actorID, orgID)
+	if err != nil {
+		respondError(c, err, "Failed to retrieve organization tree")
+		return
+	}
+
+	c.JSON(http.StatusOK, tree)
+}
+
+func (h *Handler) GetOrganizationBySlug(c *gin.Context) {
+	org, err := h.service.GetOrganizationBySlug(c.Param("slug"))
+	if err != nil {
+		respondError(c, err, "Failed to retrieve organization")
+		return
+	}
+
+	c.JSON(http.StatusOK, org)
+}
+
+func (h *Handler) GetSettings(c *gin.Context) {
+	actorID, orgID, ok := actorAndOrg(c)
+	if !ok {
+		return
+	}
+
+	settings, err := h.service.GetSettings(actorID, orgID)
+	if err != nil {
+		respondError(c, err, "Failed to retrieve settings")
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+func (h *Handler) UpdateSettings(c *gin.Context) {
+	actorID, orgID, ok := actorAndOrg(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Settings model.Settings `json:"settings" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	settings, err := h.service.UpdateSettings(actorID, orgID, req.Settings)
+	if err != nil {
+		respondError(c, err, "Failed to update settings")
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+func (h *Handler) ListDomains(c *gin.Context) {
+	actorID, orgID, ok := actorAndOrg(c)
+	if !ok {
+		return
+	}
+
+	domains, err := h.service.ListDomains(actorID, orgID)
+	if err != nil {
+		respondError(c, err, "Failed to list domains")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"domains": domains})
+}
+
+func (h *Handler) AddDomain(c *gin.Context) {
+	actorID, orgID, ok := actorAndOrg(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Domain string `json:"domain" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	domain, err := h.service.AddDomain(actorID, orgID, req.Domain)
+	if err != nil {
+		respondError(c, err, "Failed to add domain")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"domain": domain, "challenge": Challenge(domain)})
+}
+
+func (h *Handler) VerifyDomain(c *gin.Context) {
+	actorID, orgID, ok := actorAndOrg(c)
+	if !ok {
+		return
+	}
+	domainID, ok := uuidParam(c, "domainID")
+	if !ok {
+		return
+	}
+
+	domain, err := h.service.VerifyDomain(actorID, orgID, domainID)
+	if err != nil {
+		respondError(c, err, "Failed to verify domain")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"domain": domain})
+}
+
+func (h *Handler) DeleteDomain(c *gin.Context) {
+	actorID, orgID, ok := actorAndOrg(c)
+	if !ok {
+		return
+	}
+	domainID, ok := uuidParam(c, "domainID")
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteDomain(actorID, orgID, domainID); err != nil {
+		respondError(c, err, "Failed to delete domain")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) InitiateTransfer(c *gin.Context) {
+	actorID, orgID, ok := actorAndOrg(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		UserID string `json:"user_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	toUserID, err := uuid.Parse(req.UserID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user_id"})
+		return
+	}
+
+	transfer, err := h.service.InitiateTransfer(actorID, orgID, toUserID)
+	if err != nil {
+		respondError(c, err, "Failed to initiate ownership transfer")
+		return
+	}
+
+	c.JSON(http.StatusCreated, transfer)
+}
+
+func (h *Handler) GetPendingTransfer(c *gin.Context) {
+	actorID, orgID, ok := actorAndOrg(c)
+	if !ok {
+		return
+	}
+
+	transfer, err := h.service.GetPendingTransfer(actorID, orgID)
+	if err != nil {
+		respondError(c, err, "Failed to retrieve ownership transfer")
+		return
+	}
+
+	c.JSON(http.StatusOK, transfer)
+}
+
+func (h *Handler) AcceptTransfer(c *gin.Context) {
+	actorID, orgID, ok := actorAndOrg(c)
+	if !ok {
+		return
+	}
+
+	transfer, err := h.service.AcceptTransfer(actorID, orgID)
+	if err != nil {
+		respondError(c, err, "Failed to accept ownership transfer")
+		return
+	}
+
+	c.JSON(http.StatusOK, transfer)
+}
+
+func (h *Handler) CancelTransfer(c *gin.Context) {
+	actorID, orgID, ok := actorAndOrg(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.CancelTransfer(actorID, orgID); err != nil {
+		respondError(c, err, "Failed to cancel ownership transfer")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) LeaveOrganization(c *gin.Context) {
+	actorID, orgID, ok := actorAndOrg(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.LeaveOrganization(actorID, orgID); err != nil {
+		respondError(c, err, "Failed to leave organization")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) ListAuditEvents(c *gin.Context) {
+	actorID, orgID, ok := actorAndOrg(c)
+	if !ok {
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit < 1 || limit > 1000 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 1000"})
+		return
+	}
+
+	events, err := h.service.ListAuditEvents(actorID, orgID, limit)
+	if err != nil {
+		respondError(c, err, "Failed to list audit events")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"events": events})
+}
+
+func (h *Handler) ListTeams(c *gin.Context) {
+	actorID, orgID, ok := actorAndOrg(c)
+	if !ok {
+		return
+	}
+
+	teams, err := h.service.ListTeams(actorID, orgID)
+	if err != nil {
+		respondError(c, err, "Failed to list teams")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"teams": teams})
+}
+
+func (h *Handler) CreateTeam(c *gin.Context) {
+	actorID, orgID, ok := actorAndOrg(c)
+	if !ok {
+		return
+	}
+
+	var req TeamInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Name == nil || *req.Name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Team name is required"})
+		return
+	}
+
+	team, err := h.service.CreateTeam(actorID, orgID, req)
+	if err != nil {
+		respondError(c, err, "Failed to create team")
+		return
+	}
+
+	c.JSON(http.StatusCreated, team)
+}
+
+func (h *Handler) GetTeam(c *gin.Context) {
+	actorID, orgID, ok := actorAndOrg(c)
+	if !ok {
+		return
+	}
+	teamID, ok := uuidParam(c, "teamID")
+	if !ok {
+		return
+	}
+
+	team, err := h.service.GetTeam(actorID, orgID, teamID)
+	if err != nil {
+		respondError(c, err, "Failed to retrieve team")
+		return
+	}
+
+	c.JSON(http.StatusOK, team)
+}
+
+func (h *Handler) UpdateTeam(c *gin.Context) {
+	actorID, orgID, ok := actorAndOrg(c)
+	if !ok {
+		return
+	}
+	teamID, ok := uuidParam(c, "teamID")
+	if !ok {
+		return
+	}
+
+	var req TeamInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Name != nil && *req.Name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Team name cannot be empty"})
+		return
+	}
+
+	team, err := h.service.UpdateTeam(actorID, orgID, teamID, req)
+	if err != nil {
+		respondError(c, err, "Failed to update team")
+		return
+	}
+
+	c.JSON(http.StatusOK, team)
+}
+
+func (h *Handler) DeleteTeam(c *gin.Context) {
+	actorID, orgID, ok := actorAndOrg(c)
+	if !ok {
+		return
+	}
+	teamID, ok := uuidParam(c, "teamID")
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteTeam(actorID, orgID, teamID); err != nil {
+		respondError(c, err, "Failed to delete team")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) ListTeamMembers(c *gin.Context) {
+	actorID, orgID, ok := actorAndOrg(c)
+	if !ok {
+		return
+	}
+	teamID, ok := uuidParam(c, "teamID")
+	if !ok {
+		return
+	}
+
+	users, err := h.service.ListTeamMembers(actorID, orgID, teamID)
+	if err != nil {
+		respondError(c, err, "Failed to list team members")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"members": users})
+}
+
+func (h *Handler) AddTeamMember(c *gin.Context) {
+	actorID, orgID, ok := actorAndOrg(c)
+	if !ok {
+		return
+	}
+	teamID, ok := uuidParam(c, "teamID")
+	if !ok {
+		return
+	}
+	userID, ok := uuidParam(c, "userID")
+	if !ok {
+		return
+	}
+
+	if err := h.service.AddTeamMember(actorID, orgID, teamID, userID); err != nil {
+		respondError(c, err, "Failed to add team member")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) RemoveTeamMember(c *gin.Context) {
+	actorID, orgID, ok := actorAndOrg(c)
+	if !ok {
+		return
+	}
+	teamID, ok := uuidParam(c, "teamID")
+	if !ok {
+		return
+	}
+	userID, ok := uuidParam(c, "userID")
+	if !ok {
+		return
+	}
+
+	if err := h.service.RemoveTeamMember(actorID, orgID, teamID, userID); err != nil {
+		respondError(c, err, "Failed to remove team member")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) GetMemberAccess(c *gin.Context) {
+	actorID, orgID, ok := actorAndOrg(c)
+	if !ok {
+		return
+	}
+	userID, ok := uuidParam(c, "userID")
+	if !ok {
+		return
+	}
+
+	access, err := h.service.GetAccess(actorID, orgID, userID)
+	if err != nil {
+		respondError(c, err, "Failed to resolve permissions")
+		return
+	}
+
+	c.JSON(http.StatusOK, access)
+}
+
+func (h *Handler) ListServiceAccounts(c *gin.Context) {
+	actorID, orgID, ok := actorAndOrg(c)
+	if !ok {
+		return
+	}
+
+	accounts, err := h.service.ListServiceAccounts(actorID, orgID)
+	if err != nil {
+		respondError(c, err, "Failed to list service accounts")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"service_accounts": accounts})
+}
+
+func (h *Handler) CreateServiceAccount(c *gin.Context) {
+	actorID, orgID, ok := actorAndOrg(c)
+	if !ok {
+		return
+	}
+
+	var req ServiceAccountInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Name == nil || *req.Name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Service account name is required"})
+		return
+	}
+
+	account, secret, err := h.service.CreateServiceAccount(actorID, orgID, req)
+	if err != nil {
+		respondError(c, err, "Failed to create service account")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"service_account": account, "secret": secret})
+}
+
+func (h *Handler) GetServiceAccount(c *gin.Context) {
+	actorID, orgID, ok := actorAndOrg(c)
+	if !ok {
+		return
+	}
+	accountID, ok := uuidParam(c, "accountID")
+	if !ok {
+		return
+	}
+
+	account, err := h.service.GetServiceAccount(actorID, orgID, accountID)
+	if err != nil {
+		respondError(c, err, "Failed to retrieve service account")
+		return
+	}
+
+	c.JSON(http.StatusOK, account)
+}
+
+func (h *Handler) UpdateServiceAccount(c *gin.Context) {
+	actorID, orgID, ok := actorAndOrg(c)
+	if !ok {
+		return
+	}
+	accountID, ok := uuidParam(c, "accountID")
+	if !ok {
+		return
+	}
+
+	var req ServiceAccountInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	account, err := h.service.UpdateServiceAccount(actorID, orgID, accountID, req)
+	if err != nil {
+		respondError(c, err, "Failed to update service account")
+		return
+	}
+
+	c.JSON(http.StatusOK, account)
+}
+
+func (h *Handler) DeleteServiceAccount(c *gin.Context) {
+	actorID, orgID, ok := actorAndOrg(c)
+	if !ok {
+		return
+	}
+	accountID, ok := uuidParam(c, "accountID")
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteServiceAccount(actorID, orgID, accountID); err != nil {
+		respondError(c, err, "Failed to delete service account")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) ListServiceAccountSecrets(c *gin.Context) {
+	actorID, orgID, ok := actorAndOrg(c)
+	if !ok {
+		return
+	}
+	accountID, ok := uuidParam(c, "accountID")
+	if !ok {
+		return
+	}
+
+	secrets, err := h.service.ListServiceAccountSecrets(actorID, orgID, accountID)
+	if err != nil {
+		respondError(c, err, "Failed to list secrets")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"secrets": secrets})
+}
+
+func (h *Handler) CreateServiceAccountSecret(c *gin.Context) {
+	actorID, orgID, ok := actorAndOrg(c)
+	if !ok {
+		return
+	}
+	accountID, ok := uuidParam(c, "accountID")
+	if !ok {
+		return
+	}
+
+	var req SecretInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	secret, err := h.service.CreateServiceAccountSecret(actorID, orgID, accountID, req)
+	if err != nil {
+		respondError(c, err, "Failed to create secret")
+		return
+	}
+
+	c.JSON(http.StatusCreated, secret)
+}
+
+func (h *Handler) DeleteServiceAccountSecret(c *gin.Context) {
+	actorID, orgID, ok := actorAndOrg(c)
+	if !ok {
+		return
+	}
+	accountID, ok := uuidParam(c, "accountID")
+	if !ok {
+		return
+	}
+	secretID, ok := uuidParam(c, "secretID")
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteServiceAccountSecret(actorID, orgID, accountID, secretID); err != nil {
+		respondError(c, err, "Failed to delete secret")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) ListTrustPolicies(c *gin.Context) {
+	actorID, orgID, ok := actorAndOrg(c)
+	if !ok {
+		return
+	}
+	accountID, ok := uuidParam(c, "accountID")
+	if !ok {
+		return
+	}
+
+	policies, err := h.service.ListTrustPolicies(actorID, orgID, accountID)
+	if err != nil {
+		respondError(c, err, "Failed to list trust policies")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"trust_policies": policies})
+}
+
+func (h *Handler) CreateTrustPolicy(c *gin.Context) {
+	actorID, orgID, ok := actorAndOrg(c)
+	if !ok {
+		return
+	}
+	accountID, ok := uuidParam(c, "accountID")
+	if !ok {
+		return
+	}
+
+	var req TrustPolicyInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	policy, err := h.service.CreateTrustPolicy(actorID, orgID, accountID, req)
+	if err != nil {
+		respondError(c, err, "Failed to create trust policy")
+		return
+	}
+
+	c.JSON(http.StatusCreated, policy)
+}
+
+func (h *Handler) DeleteTrustPolicy(c *gin.Context) {
+	actorID, orgID, ok := actorAndOrg(c)
+	if !ok {
+		return
+	}
+	accountID, ok := uuidParam(c, "accountID")
+	if !ok {
+		return
+	}
+	policyID, ok := uuidParam(c, "policyID")
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteTrustPolicy(actorID, orgID, accountID, policyID); err != nil {
+		respondError(c, err, "Failed to delete trust policy")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) ListAPIKeys(c *gin.Context) {
+	actorID, orgID, ok := actorAndOrg(c)
+	if !ok {
+		return
+	}
+
+	keys, err := h.service.ListAPIKeys(actorID, orgID)
+	if err != nil {
+		respondError(c, err, "Failed to list API keys")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"api_keys": keys})
+}
+
+func (h *Handler) CreateAPIKey(c *gin.Context) {
+	actorID, orgID, ok := actorAndOrg(c)
+	if !ok {
+		return
+	}
+
+	var req APIKeyInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	key, plaintext, err := h.service.CreateAPIKey(actorID, orgID, req)
+	if err != nil {
+		respondError(c, err, "Failed to create API key")
+		return
+	}
+
+	// The plaintext is only ever shown here; only its hash is stored.
+	c.JSON(http.StatusCreated, gin.H{"api_key": key, "key": plaintext})
+}
+
+func (h *Handler) RotateAPIKey(c *gin.Context) {
+	actorID, orgID, ok := actorAndOrg(c)
+	if !ok {
+		return
+	}
+	keyID, ok := uuidParam(c, "keyID")
+	if !ok {
+		return
+	}
+
+	var req struct {
+		GracePeriodSeconds int `json:"grace_period_seconds"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	key, plaintext, err := h.service.RotateAPIKey(actorID, orgID, keyID, time.Duration(req.GracePeriodSeconds)*time.Second)
+	if err != nil {
+		respondError(c, err, "Failed to rotate API key")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"api_key": key, "key": plaintext})
+}
+
+func (h *Handler) RevokeAPIKey(c *gin.Context) {
+	actorID, orgID, ok := actorAndOrg(c)
+	if !ok {
+		return
+	}
+	keyID, ok := uuidParam(c, "keyID")
+	if !ok {
+		return
+	}
+
+	if err := h.service.RevokeAPIKey(actorID, orgID, keyID); err != nil {
+		respondError(c, err, "Failed to revoke API key")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func actor(c *gin.Context) (uuid.UUID, bool) {
+	actorID, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user"})
+		return uuid.Nil, false
+	}
+	return actorID, true
+}
+
+func actorAndOrg(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	actorID, ok := actor(c)
+	if !ok {
+		return uuid.Nil, uuid.Nil, false
+	}
+	orgID, ok := uuidParam(c, "orgID")
+	if !ok {
+		return uuid.Nil, uuid.Nil, false
+	}
+	return actorID, orgID, true
+}
+
+func uuidParam(c *gin.Context, name string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param(name))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + name})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func respondError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+	case errors.Is(err, repository.ErrOrganizationNotFound),
+		errors.Is(err, repository.ErrTeamNotFound),
+		errors.Is(err, repository.ErrDomainNotFound),
+		errors.Is(err, repository.ErrTransferNotFound),
+		errors.Is(err, repository.ErrServiceAccountNotFound),
+		errors.Is(err, repository.ErrSecretNotFound),
+		errors.Is(err, repository.ErrTrustPolicyNotFound),
+		errors.Is(err, repository.ErrAPIKeyNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrNotMember),
+		errors.Is(err, ErrInvalidRole),
+		errors.Is(err, ErrInvalidParentTeam),
+		errors.Is(err, ErrTeamCycle),
+		errors.Is(err, ErrInvalidParentOrg),
+		errors.Is(err, ErrOrganizationCycle),
+		errors.Is(err, ErrInvalidSlug),
+		errors.Is(err, ErrInvalidBranding),
+		errors.Is(err, ErrInvalidSettings),
+		errors.Is(err, ErrInvalidDomain),
+		errors.Is(err, ErrInvalidTransfer),
+		errors.Is(err, ErrInvalidServiceAccount),
+		errors.Is(err, ErrInvalidServiceAccountRole),
+		errors.Is(err, ErrInvalidAPIKey),
+		errors.Is(err, ErrInvalidTrustPolicy):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrTeamHasChildren),
+		errors.Is(err, ErrHasChildOrgs),
+		errors.Is(err, repository.ErrSlugTaken),
+		errors.Is(err, ErrDomainTaken),
+		errors.Is(err, ErrLastOwner),
+		errors.Is(err, ErrParentDeleted),
+		errors.Is(err, ErrTooManySecrets),
+		errors.Is(err, ErrTooManyTrustPolicies):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrTransferExpired),
+		errors.Is(err, ErrAPIKeyExpired):
+		c.JSON(http.StatusGone, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrDomainNotVerified):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
@@ -0,0 +1,842 @@
+package organization
+
+import (
+	"errors"
+	"log"
+	"net"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/radiatus-ai/auth-service/internal/audit"
+	"github.com/radiatus-ai/auth-service/internal/auth"
+	"github.com/radiatus-ai/auth-service/internal/model"
+	"github.com/radiatus-ai/auth-service/internal/rbac"
+	"github.com/radiatus-ai/auth-service/internal/repository"
+)
+
+type Service interface {
+	ListOrganizations(actorID uuid.UUID) ([]model.Organization, error)
+	CreateOrganization(actorID uuid.UUID, input OrganizationInput) (*model.Organization, error)
+	GetOrganization(actorID, orgID uuid.UUID) (*model.Organization, error)
+	UpdateOrganization(actorID, orgID uuid.UUID, input OrganizationInput) (*model.Organization, error)
+	DeleteOrganization(actorID, orgID uuid.UUID) error
+	ListDeletedOrganizations(actorID uuid.UUID) ([]model.Organization, error)
+	RestoreOrganization(actorID, orgID uuid.UUID) (*model.Organization, error)
+	PurgeDeletedOrganizations() (int, error)
+	GetOrganizationTree(actorID, orgID uuid.UUID) (*OrganizationNode, error)
+	GetOrganizationBySlug(slug string) (*model.Organization, error)
+	GetSettings(actorID, orgID uuid.UUID) (*Settings, error)
+	UpdateSettings(actorID, orgID uuid.UUID, settings model.Settings) (*Settings, error)
+	ListDomains(actorID, orgID uuid.UUID) ([]model.OrganizationDomain, error)
+	AddDomain(actorID, orgID uuid.UUID, domain string) (*model.OrganizationDomain, error)
+	VerifyDomain(actorID, orgID, domainID uuid.UUID) (*model.OrganizationDomain, error)
+	DeleteDomain(actorID, orgID, domainID uuid.UUID) error
+	InitiateTransfer(actorID, orgID, toUserID uuid.UUID) (*model.OwnershipTransfer, error)
+	GetPendingTransfer(actorID, orgID uuid.UUID) (*model.OwnershipTransfer, error)
+	AcceptTransfer(actorID, orgID uuid.UUID) (*model.OwnershipTransfer, error)
+	CancelTransfer(actorID, orgID uuid.UUID) error
+	LeaveOrganization(actorID, orgID uuid.UUID) error
+	ListAuditEvents(actorID, orgID uuid.UUID, limit int) ([]model.AuditEvent, error)
+	ListTeams(actorID, orgID uuid.UUID) ([]model.Team, error)
+	CreateTeam(actorID, orgID uuid.UUID, input TeamInput) (*model.Team, error)
+	GetTeam(actorID, orgID, teamID uuid.UUID) (*model.Team, error)
+	UpdateTeam(actorID, orgID, teamID uuid.UUID, input TeamInput) (*model.Team, error)
+	DeleteTeam(actorID, orgID, teamID uuid.UUID) error
+	ListTeamMembers(actorID, orgID, teamID uuid.UUID) ([]model.User, error)
+	AddTeamMember(actorID, orgID, teamID, userID uuid.UUID) error
+	RemoveTeamMember(actorID, orgID, teamID, userID uuid.UUID) error
+	GetAccess(actorID, orgID, userID uuid.UUID) (*Access, error)
+	EffectiveAccess(orgID, userID uuid.UUID) (*Access, error)
+	ListServiceAccounts(actorID, orgID uuid.UUID) ([]model.ServiceAccount, error)
+	CreateServiceAccount(actorID, orgID uuid.UUID, input ServiceAccountInput) (*model.ServiceAccount, *NewSecret, error)
+	GetServiceAccount(actorID, orgID, accountID uuid.UUID) (*model.ServiceAccount, error)
+	UpdateServiceAccount(actorID, orgID, accountID uuid.UUID, input ServiceAccountInput) (*model.ServiceAccount, error)
+	DeleteServiceAccount(actorID, orgID, accountID uuid.UUID) error
+	ListServiceAccountSecrets(actorID, orgID, accountID uuid.UUID) ([]model.ServiceAccountSecret, error)
+	CreateServiceAccountSecret(actorID, orgID, accountID uuid.UUID, input SecretInput) (*NewSecret, error)
+	DeleteServiceAccountSecret(actorID, orgID, accountID, secretID uuid.UUID) error
+	ListTrustPolicies(actorID, orgID, accountID uuid.UUID) ([]model.TrustPolicy, error)
+	CreateTrustPolicy(actorID, orgID, accountID uuid.UUID, input TrustPolicyInput) (*model.TrustPolicy, error)
+	DeleteTrustPolicy(actorID, orgID, accountID, policyID uuid.UUID) error
+	ListAPIKeys(actorID, orgID uuid.UUID) ([]model.APIKey, error)
+	CreateAPIKey(actorID, orgID uuid.UUID, input APIKeyInput) (*model.APIKey, string, error)
+	RotateAPIKey(actorID, orgID, keyID uuid.UUID, grace time.Duration) (*model.APIKey, string, error)
+	RevokeAPIKey(actorID, orgID, keyID uuid.UUID) error
+}
+
+type service struct {
+	orgRepo            repository.OrganizationRepository
+	teamRepo           repository.TeamRepository
+	domainRepo         repository.DomainRepository
+	transferRepo       repository.TransferRepository
+	serviceAccountRepo repository.ServiceAccountRepository
+	apiKeyRepo         repository.APIKeyRepository
+	audit              audit.Logger
+	retention          time.Duration
+	sessionPolicy      auth.SessionPolicy
+	lookupTXT          func(name string) ([]string, error)
+}
+
+// NewService creates the organization service. Deleted organizations remain
+// restorable for the retention period before they are purged.
+func NewService(orgRepo repository.OrganizationRepository, teamRepo repository.TeamRepository, domainRepo repository.DomainRepository, transferRepo repository.TransferRepository, serviceAccountRepo repository.ServiceAccountRepository, apiKeyRepo repository.APIKeyRepository, auditLog audit.Logger, retention time.Duration, sessionPolicy auth.SessionPolicy) Service {
+	return &service{
+		orgRepo:            orgRepo,
+		teamRepo:           teamRepo,
+		domainRepo:         domainRepo,
+		transferRepo:       transferRepo,
+		serviceAccountRepo: serviceAccountRepo,
+		apiKeyRepo:         apiKeyRepo,
+		audit:              auditLog,
+		retention:          retention,
+		sessionPolicy:      sessionPolicy,
+		lookupTXT:          net.LookupTXT,
+	}
+}
+
+// maxOrgDepth bounds walks up the organization hierarchy so that corrupt
+// data can never loop forever.
+const maxOrgDepth = 32
+
+// OrganizationInput carries the writable fields of an organization. Nil fields
+// are left unchanged on update; an empty ParentID detaches the organization
+// from its parent.
+type OrganizationInput struct {
+	Name         *string `json:"name"`
+	Slug         *string `json:"slug"`
+	ParentID     *string `json:"parent_id"`
+	LogoURL      *string `json:"logo_url"`
+	PrimaryColor *string `json:"primary_color"`
+}
+
+// OrganizationNode is an organization together with its descendants.
+type OrganizationNode struct {
+	model.Organization
+	Children []*OrganizationNode `json:"children"`
+}
+
+// TeamInput carries the writable fields of a team. Nil fields are left
+// unchanged on update; an empty ParentTeamID moves the team to the top level.
+type TeamInput struct {
+	Name         *string `json:"name"`
+	Description  *string `json:"description"`
+	ParentTeamID *string `json:"parent_team_id"`
+	Role         *string `json:"role"`
+}
+
+// Access describes what a user may do in an organization: the role granted by
+// their direct membership, every team they belong to (directly or through a
+// child team), the roles inherited from ancestor organizations, and the union
+// of permissions granted by all of those roles.
+type Access struct {
+	OrganizationID uuid.UUID         `json:"organization_id"`
+	UserID         uuid.UUID         `json:"user_id"`
+	Role           string            `json:"role,omitempty"`
+	Teams          []model.Team      `json:"teams"`
+	Inherited      []Grant           `json:"inherited,omitempty"`
+	Permissions    []rbac.Permission `json:"permissions"`
+
+	permissions rbac.PermissionSet
+}
+
+// Grant is the role and teams a user holds in a single organization.
+type Grant struct {
+	OrganizationID uuid.UUID    `json:"organization_id"`
+	Role           string       `json:"role"`
+	Teams          []model.Team `json:"teams"`
+}
+
+func (a *Access) Has(p rbac.Permission) bool {
+	return a.permissions.Has(p)
+}
+
+// HasRole reports whether the access includes every permission of role, so
+// that granting role would not give anyone more than the holder has.
+func (a *Access) HasRole(role rbac.Role) bool {
+	for _, p := range role.Permissions() {
+		if !a.Has(p) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *service) ListOrganizations(actorID uuid.UUID) ([]model.Organization, error) {
+	return s.orgRepo.GetUserOrganizations(actorID)
+}
+
+func (s *service) CreateOrganization(actorID uuid.UUID, input OrganizationInput) (*model.Organization, error) {
+	org := &model.Organization{}
+	if err := s.applyOrganizationInput(org, input); err != nil {
+		return nil, err
+	}
+	if input.ParentID != nil && *input.ParentID != "" {
+		parentID, err := uuid.Parse(*input.ParentID)
+		if err != nil {
+			return nil, ErrInvalidParentOrg
+		}
+		if err := s.authorize(actorID, parentID, rbac.PermOrgChildren); err != nil {
+			return nil, err
+		}
+		org.ParentID = &parentID
+	}
+
+	if err := s.orgRepo.Create(org); err != nil {
+		log.Printf("Failed to create organization: %v", err)
+		return nil, err
+	}
+	if err := s.orgRepo.AddMember(org.ID, actorID, string(rbac.RoleOwner)); err != nil {
+		log.Printf("Failed to add owner to organization: %v", err)
+		return nil, err
+	}
+	return org, nil
+}
+
+func (s *service) GetOrganization(actorID, orgID uuid.UUID) (*model.Organization, error) {
+	if err := s.authorize(actorID, orgID, rbac.PermOrgRead); err != nil {
+		return nil, err
+	}
+	return s.orgRepo.GetByID(orgID)
+}
+
+func (s *service) UpdateOrganization(actorID, orgID uuid.UUID, input OrganizationInput) (*model.Organization, error) {
+	if err := s.authorize(actorID, orgID, rbac.PermOrgUpdate); err != nil {
+		return nil, err
+	}
+
+	org, err := s.orgRepo.GetByID(orgID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyOrganizationInput(org, input); err != nil {
+		return nil, err
+	}
+	if input.ParentID != nil {
+		if err := s.moveOrganization(actorID, org, *input.ParentID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.orgRepo.Update(org); err != nil {
+		log.Printf("Failed to update organization: %v", err)
+		return nil, err
+	}
+	return org, nil
+}
+
+func (s *service) DeleteOrganization(actorID, orgID uuid.UUID) error {
+	if err := s.authorize(actorID, orgID, rbac.PermOrgDelete); err != nil {
+		return err
+	}
+
+	children, err := s.orgRepo.CountChildren(orgID)
+	if err != nil {
+		return err
+	}
+	if children > 0 {
+		return ErrHasChildOrgs
+	}
+	if err := s.orgRepo.Delete(orgID); err != nil {
+		log.Printf("Failed to delete organization: %v", err)
+		return err
+	}
+
+	s.audit.Record(audit.UserEvent(actorID, orgID, audit.ActionOrgDeleted, "organization", orgID.String()))
+	return nil
+}
+
+func (s *service) ListDeletedOrganizations(actorID uuid.UUID) ([]model.Organization, error) {
+	return s.orgRepo.GetUserDeletedOrganizations(actorID)
+}
+
+// RestoreOrganization undoes a soft delete within the retention period. Only
+// direct owners and admins of the organization may restore it, and a child
+// cannot be restored while its parent is itself deleted.
+func (s *service) RestoreOrganization(actorID, orgID uuid.UUID) (*model.Organization, error) {
+	org, err := s.orgRepo.GetDeletedByID(orgID)
+	if err != nil {
+		return nil, err
+	}
+	if time.Since(org.DeletedAt.Time) > s.retention {
+		return nil, repository.ErrOrganizationNotFound
+	}
+
+	membership, err := s.orgRepo.GetMembership(orgID, actorID)
+	if err != nil {
+		if errors.Is(err, repository.ErrMembershipNotFound) {
+			return nil, ErrForbidden
+		}
+		return nil, err
+	}
+	if membership.Role != string(rbac.RoleOwner) && membership.Role != string(rbac.RoleAdmin) {
+		return nil, ErrForbidden
+	}
+
+	if org.ParentID != nil {
+		if _, err := s.orgRepo.GetByID(*org.ParentID); err != nil {
+			if errors.Is(err, repository.ErrOrganizationNotFound) {
+				return nil, ErrParentDeleted
+			}
+			return nil, err
+		}
+	}
+
+	if err := s.orgRepo.Restore(orgID); err != nil {
+		log.Printf("Failed to restore organization: %v", err)
+		return nil, err
+	}
+	org.DeletedAt = gorm.DeletedAt{}
+
+	s.audit.Record(audit.UserEvent(actorID, orgID, audit.ActionOrgRestored, "organization", orgID.String()))
+	return org, nil
+}
+
+// PurgeDeletedOrganizations permanently removes organizations that have been
+// soft-deleted for longer than the retention period. An organization whose
+// children are still within theirs is left for a later run.
+func (s *service) PurgeDeletedOrganizations() (int, error) {
+	orgs, err := s.orgRepo.ListDeletedBefore(time.Now().Add(-s.retention))
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for _, org := range orgs {
+		if err := s.orgRepo.Purge(org.ID); errors.Is(err, repository.ErrOrganizationHasChildren) {
+			log.Printf("Not purging organization %s yet: it has child organizations", org.ID)
+			continue
+		} else if err != nil {
+			log.Printf("Failed to purge organization %s: %v", org.ID, err)
+			continue
+		}
+		event := audit.SystemEvent(audit.ActionOrgPurged, "organization", org.ID.String())
+		event.Metadata["name"] = org.Name
+		event.Metadata["slug"] = org.Slug
+		s.audit.Record(event)
+		purged++
+	}
+	return purged, nil
+}
+
+func (s *service) GetOrganizationTree(actorID, orgID uuid.UUID) (*OrganizationNode, error) {
+	if err := s.authorize(actorID, orgID, rbac.PermOrgRead); err != nil {
+		return nil, err
+	}
+
+	orgs, err := s.orgRepo.ListDescendants(orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make(map[uuid.UUID]*OrganizationNode, len(orgs))
+	for _, org := range orgs {
+		nodes[org.ID] = &OrganizationNode{Organization: org, Children: []*OrganizationNode{}}
+	}
+	for _, org := range orgs {
+		if org.ID == orgID || org.ParentID == nil {
+			continue
+		}
+		if parent, ok := nodes[*org.ParentID]; ok {
+			parent.Children = append(parent.Children, nodes[org.ID])
+		}
+	}
+
+	root, ok := nodes[orgID]
+	if !ok {
+		return nil, repository.ErrOrganizationNotFound
+	}
+	return root, nil
+}
+
+// GetOrganizationBySlug lets downstream services resolve a slug to an
+// organization. It is not scoped to the caller's memberships.
+func (s *service) GetOrganizationBySlug(slug string) (*model.Organization, error) {
+	return s.orgRepo.GetBySlug(slug)
+}
+
+// Settings is an organization's settings document and the session policy
+// that results from it.
+type Settings struct {
+	Settings      model.Settings     `json:"settings"`
+	SessionPolicy auth.SessionPolicy `json:"session_policy"`
+}
+
+func (s *service) GetSettings(actorID, orgID uuid.UUID) (*Settings, error) {
+	if err := s.authorize(actorID, orgID, rbac.PermOrgRead); err != nil {
+		return nil, err
+	}
+	org, err := s.orgRepo.GetByID(orgID)
+	if err != nil {
+		return nil, err
+	}
+	return s.settings(org), nil
+}
+
+// UpdateSettings replaces the organization's settings document.
+func (s *service) UpdateSettings(actorID, orgID uuid.UUID, settings model.Settings) (*Settings, error) {
+	if err := s.authorize(actorID, orgID, rbac.PermOrgUpdate); err != nil {
+		return nil, err
+	}
+	if err := ValidateSettings(settings); err != nil {
+		return nil, err
+	}
+
+	org, err := s.orgRepo.GetByID(orgID)
+	if err != nil {
+		return nil, err
+	}
+	org.Settings = settings
+	if err := s.orgRepo.Update(org); err != nil {
+		log.Printf("Failed to update organization settings: %v", err)
+		return nil, err
+	}
+	return s.settings(org), nil
+}
+
+// settings resolves the session policy of org's own settings. Members of
+// several organizations get the strictest of their organizations' policies,
+// which the auth service works out when their sessions start and refresh.
+func (s *service) settings(org *model.Organization) *Settings {
+	policy, err := s.sessionPolicy.WithSettings(org.Settings)
+	if err != nil {
+		policy = s.sessionPolicy
+	}
+	return &Settings{Settings: org.Settings, SessionPolicy: policy}
+}
+
+func (s *service) applyOrganizationInput(org *model.Organization, input OrganizationInput) error {
+	if input.Name != nil {
+		org.Name = *input.Name
+	}
+	if input.Slug != nil {
+		if err := validateSlug(*input.Slug); err != nil {
+			return err
+		}
+		taken, err := s.orgRepo.SlugExists(*input.Slug, org.ID)
+		if err != nil {
+			return err
+		}
+		if taken {
+			return repository.ErrSlugTaken
+		}
+		org.Slug = *input.Slug
+	}
+	if input.LogoURL != nil {
+		org.LogoURL = *input.LogoURL
+	}
+	if input.PrimaryColor != nil {
+		org.PrimaryColor = *input.PrimaryColor
+	}
+	return validateBranding(org.LogoURL, org.PrimaryColor)
+}
+
+// moveOrganization re-parents org. The actor must be able to manage children
+// of both the current and the new parent, and the move must not make org an
+// ancestor of itself.
+func (s *service) moveOrganization(actorID uuid.UUID, org *model.Organization, parent string) error {
+	var parentID *uuid.UUID
+	if parent != "" {
+		id, err := uuid.Parse(parent)
+		if err != nil {
+			return ErrInvalidParentOrg
+		}
+		parentID = &id
+	}
+
+	if org.ParentID != nil {
+		if err := s.authorize(actorID, *org.ParentID, rbac.PermOrgChildren); err != nil {
+			return err
+		}
+	}
+	if parentID != nil {
+		if err := s.authorize(actorID, *parentID, rbac.PermOrgChildren); err != nil {
+			return err
+		}
+		if err := s.checkOrgParent(org.ID, *parentID); err != nil {
+			return err
+		}
+	}
+
+	org.ParentID = parentID
+	return nil
+}
+
+func (s *service) checkOrgParent(orgID, parentID uuid.UUID) error {
+	id := &parentID
+	for depth := 0; id != nil; depth++ {
+		if *id == orgID || depth >= maxOrgDepth {
+			return ErrOrganizationCycle
+		}
+		parent, err := s.orgRepo.GetByID(*id)
+		if err != nil {
+			if errors.Is(err, repository.ErrOrganizationNotFound) {
+				return ErrInvalidParentOrg
+			}
+			return err
+		}
+		id = parent.ParentID
+	}
+	return nil
+}
+
+func (s *service) ListTeams(actorID, orgID uuid.UUID) ([]model.Team, error) {
+	if err := s.authorize(actorID, orgID, rbac.PermTeamsRead); err != nil {
+		return nil, err
+	}
+	return s.teamRepo.ListByOrganization(orgID)
+}
+
+func (s *service) CreateTeam(actorID, orgID uuid.UUID, input TeamInput) (*model.Team, error) {
+	access, err := s.authorizeAccess(actorID, orgID, rbac.PermTeamsManage)
+	if err != nil {
+		return nil, err
+	}
+
+	team := &model.Team{OrganizationID: orgID}
+	if err := s.applyTeamInput(team, input); err != nil {
+		return nil, err
+	}
+	if err := s.checkTeamGrant(access, team); err != nil {
+		return nil, err
+	}
+	if err := s.teamRepo.Create(team); err != nil {
+		log.Printf("Failed to create team: %v", err)
+		return nil, err
+	}
+	return team, nil
+}
+
+func (s *service) GetTeam(actorID, orgID, teamID uuid.UUID) (*model.Team, error) {
+	if err := s.authorize(actorID, orgID, rbac.PermTeamsRead); err != nil {
+		return nil, err
+	}
+	return s.getOrgTeam(orgID, teamID)
+}
+
+func (s *service) UpdateTeam(actorID, orgID, teamID uuid.UUID, input TeamInput) (*model.Team, error) {
+	access, err := s.authorizeAccess(actorID, orgID, rbac.PermTeamsManage)
+	if err != nil {
+		return nil, err
+	}
+
+	team, err := s.getOrgTeam(orgID, teamID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkTeamGrant(access, team); err != nil {
+		return nil, err
+	}
+	if err := s.applyTeamInput(team, input); err != nil {
+		return nil, err
+	}
+	if err := s.checkTeamGrant(access, team); err != nil {
+		return nil, err
+	}
+	if err := s.teamRepo.Update(team); err != nil {
+		log.Printf("Failed to update team: %v", err)
+		return nil, err
+	}
+	return team, nil
+}
+
+func (s *service) DeleteTeam(actorID, orgID, teamID uuid.UUID) error {
+	access, err := s.authorizeAccess(actorID, orgID, rbac.PermTeamsManage)
+	if err != nil {
+		return err
+	}
+
+	team, err := s.getOrgTeam(orgID, teamID)
+	if err != nil {
+		return err
+	}
+	if err := s.checkTeamGrant(access, team); err != nil {
+		return err
+	}
+	children, err := s.teamRepo.CountChildren(teamID)
+	if err != nil {
+		return err
+	}
+	if children > 0 {
+		return ErrTeamHasChildren
+	}
+	return s.teamRepo.Delete(teamID)
+}
+
+func (s *service) ListTeamMembers(actorID, orgID, teamID uuid.UUID) ([]model.User, error) {
+	if err := s.authorize(actorID, orgID, rbac.PermTeamsRead); err != nil {
+		return nil, err
+	}
+	if _, err := s.getOrgTeam(orgID, teamID); err != nil {
+		return nil, err
+	}
+	return s.teamRepo.ListMembers(teamID)
+}
+
+func (s *service) AddTeamMember(actorID, orgID, teamID, userID uuid.UUID) error {
+	access, err := s.authorizeAccess(actorID, orgID, rbac.PermTeamsManage)
+	if err != nil {
+		return err
+	}
+	team, err := s.getOrgTeam(orgID, teamID)
+	if err != nil {
+		return err
+	}
+	if err := s.checkTeamGrant(access, team); err != nil {
+		return err
+	}
+	if _, err := s.orgRepo.GetMembership(orgID, userID); err != nil {
+		if errors.Is(err, repository.ErrMembershipNotFound) {
+			return ErrNotMember
+		}
+		return err
+	}
+	return s.teamRepo.AddMember(teamID, userID)
+}
+
+func (s *service) RemoveTeamMember(actorID, orgID, teamID, userID uuid.UUID) error {
+	access, err := s.authorizeAccess(actorID, orgID, rbac.PermTeamsManage)
+	if err != nil {
+		return err
+	}
+	team, err := s.getOrgTeam(orgID, teamID)
+	if err != nil {
+		return err
+	}
+	if err := s.checkTeamGrant(access, team); err != nil {
+		return err
+	}
+	return s.teamRepo.RemoveMember(teamID, userID)
+}
+
+func (s *service) GetAccess(actorID, orgID, userID uuid.UUID) (*Access, error) {
+	if actorID != userID {
+		if err := s.authorize(actorID, orgID, rbac.PermMembersRead); err != nil {
+			return nil, err
+		}
+	}
+	return s.EffectiveAccess(orgID, userID)
+}
+
+// EffectiveAccess resolves the union of the user's direct role, the roles of
+// every team they belong to, and the grants they hold in any ancestor
+// organization. Membership of a nested team implies membership of all of its
+// ancestors, and roles on a parent organization flow down to its children.
+func (s *service) EffectiveAccess(orgID, userID uuid.UUID) (*Access, error) {
+	org, err := s.orgRepo.GetByID(orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	access := &Access{
+		OrganizationID: orgID,
+		UserID:         userID,
+		Teams:          []model.Team{},
+		permissions:    rbac.PermissionSet{},
+	}
+
+	member := false
+	for depth := 0; ; depth++ {
+		grant, err := s.grantIn(org.ID, userID, access.permissions)
+		if err != nil {
+			return nil, err
+		}
+		if grant != nil {
+			member = true
+			if depth == 0 {
+				access.Role = grant.Role
+				access.Teams = grant.Teams
+			} else {
+				access.Inherited = append(access.Inherited, *grant)
+			}
+		}
+
+		if org.ParentID == nil || depth >= maxOrgDepth {
+			break
+		}
+		if org, err = s.orgRepo.GetByID(*org.ParentID); err != nil {
+			return nil, err
+		}
+	}
+	if !member {
+		return nil, ErrNotMember
+	}
+
+	access.Permissions = access.permissions.List()
+	return access, nil
+}
+
+// grantIn returns the user's role and teams in a single organization, adding
+// the permissions they carry to perms. Service accounts are granted their
+// role, and API keys their scopes, in the organization that owns them. It
+// returns nil if the principal is not a member of that organization.
+func (s *service) grantIn(orgID, userID uuid.UUID, perms rbac.PermissionSet) (*Grant, error) {
+	membership, err := s.orgRepo.GetMembership(orgID, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrMembershipNotFound) {
+			return s.machineGrant(orgID, userID, perms)
+		}
+		return nil, err
+	}
+
+	grant := &Grant{OrganizationID: orgID, Role: membership.Role, Teams: []model.Team{}}
+	perms.Grant(rbac.Role(membership.Role))
+
+	direct, err := s.teamRepo.GetUserTeams(orgID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(direct) == 0 {
+		return grant, nil
+	}
+
+	all, err := s.teamRepo.ListByOrganization(orgID)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uuid.UUID]*model.Team, len(all))
+	for i := range all {
+		byID[all[i].ID] = &all[i]
+	}
+
+	seen := map[uuid.UUID]bool{}
+	for _, team := range direct {
+		for current := byID[team.ID]; current != nil && !seen[current.ID]; {
+			seen[current.ID] = true
+			grant.Teams = append(grant.Teams, *current)
+			if current.Role != "" {
+				perms.Grant(rbac.Role(current.Role))
+			}
+			if current.ParentTeamID == nil {
+				break
+			}
+			current = byID[*current.ParentTeamID]
+		}
+	}
+	return grant, nil
+}
+
+// machineGrant resolves a principal that is not a member: a service account
+// or an API key owned by the organization.
+func (s *service) machineGrant(orgID, id uuid.UUID, perms rbac.PermissionSet) (*Grant, error) {
+	grant, err := s.serviceAccountGrant(orgID, id)
+	if err != nil || grant != nil {
+		if grant != nil {
+			perms.Grant(rbac.Role(grant.Role))
+		}
+		return grant, err
+	}
+	return s.apiKeyGrant(orgID, id, perms)
+}
+
+func (s *service) authorize(actorID, orgID uuid.UUID, perm rbac.Permission) error {
+	_, err := s.authorizeAccess(actorID, orgID, perm)
+	return err
+}
+
+// authorizeAccess is authorize for callers that need the actor's access for
+// further checks.
+func (s *service) authorizeAccess(actorID, orgID uuid.UUID, perm rbac.Permission) (*Access, error) {
+	access, err := s.EffectiveAccess(orgID, actorID)
+	if err != nil {
+		if errors.Is(err, ErrNotMember) {
+			return nil, ErrForbidden
+		}
+		return nil, err
+	}
+	if !access.Has(perm) {
+		return nil, ErrForbidden
+	}
+	return access, nil
+}
+
+func (s *service) getOrgTeam(orgID, teamID uuid.UUID) (*model.Team, error) {
+	team, err := s.teamRepo.GetByID(teamID)
+	if err != nil {
+		return nil, err
+	}
+	if team.OrganizationID != orgID {
+		return nil, repository.ErrTeamNotFound
+	}
+	return team, nil
+}
+
+func (s *service) applyTeamInput(team *model.Team, input TeamInput) error {
+	if input.Name != nil {
+		team.Name = *input.Name
+	}
+	if input.Description != nil {
+		team.Description = *input.Description
+	}
+	if input.Role != nil {
+		if *input.Role != "" && !rbac.Role(*input.Role).Valid() {
+			return ErrInvalidRole
+		}
+		team.Role = *input.Role
+	}
+	if input.ParentTeamID != nil {
+		if *input.ParentTeamID == "" {
+			team.ParentTeamID = nil
+			return nil
+		}
+		parentID, err := uuid.Parse(*input.ParentTeamID)
+		if err != nil {
+			return ErrInvalidParentTeam
+		}
+		if err := s.checkTeamParent(team, parentID); err != nil {
+			return err
+		}
+		team.ParentTeamID = &parentID
+	}
+	return nil
+}
+
+// checkTeamGrant refuses to let an actor manage a team whose members get a
+// role the actor does not hold themselves. Members of a team hold the roles
+// of all its ancestors too, so those are checked as well. Without it an
+// admin could create an owner team and join it.
+func (s *service) checkTeamGrant(access *Access, team *model.Team) error {
+	seen := map[uuid.UUID]bool{}
+	for current := team; !seen[current.ID]; {
+		seen[current.ID] = true
+		if current.Role != "" && !access.HasRole(rbac.Role(current.Role)) {
+			return ErrForbidden
+		}
+		if current.ParentTeamID == nil {
+			return nil
+		}
+		parent, err := s.teamRepo.GetByID(*current.ParentTeamID)
+		if err != nil {
+			return err
+		}
+		current = parent
+	}
+	return nil
+}
+
+// checkTeamParent ensures parentID is a team in the same organization and
+// that making it the parent of team would not introduce a cycle. A cycle
+// already above parentID, which only corrupt data could contain, is reported
+// as one too rather than walked forever.
+func (s *service) checkTeamParent(team *model.Team, parentID uuid.UUID) error {
+	seen := map[uuid.UUID]bool{}
+	for id := &parentID; id != nil; {
+		if *id == team.ID || seen[*id] {
+			return ErrTeamCycle
+		}
+		seen[*id] = true
+		parent, err := s.teamRepo.GetByID(*id)
+		if err != nil {
+			if errors.Is(err, repository.ErrTeamNotFound) {
+				return ErrInvalidParentTeam
+			}
+			return err
+		}
+		if parent.OrganizationID != team.OrganizationID {
+			return ErrInvalidParentTeam
+		}
+		id = parent.ParentTeamID
+	}
+	return nil
+}
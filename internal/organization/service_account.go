@@ -0,0 +1,276 @@
+package organization
+
+import (
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/radiatus-ai/auth-service/internal/audit"
+	"github.com/radiatus-ai/auth-service/internal/credential"
+	"github.com/radiatus-ai/auth-service/internal/model"
+	"github.com/radiatus-ai/auth-service/internal/rbac"
+	"github.com/radiatus-ai/auth-service/internal/repository"
+)
+
+// maxServiceAccountSecrets bounds how many secrets can be live at once; two
+// are enough to rotate, the rest is headroom for several deployments.
+const maxServiceAccountSecrets = 5
+
+// ServiceAccountInput carries the writable fields of a service account. Nil
+// fields are left unchanged on update.
+type ServiceAccountInput struct {
+	Name         *string `json:"name"`
+	Description  *string `json:"description"`
+	Role         *string `json:"role"`
+	Disabled     *bool   `json:"disabled"`
+	RequireDPoP  *bool   `json:"require_dpop"`
+	TLSSubjectDN *string `json:"tls_client_auth_subject_dn"`
+	TokenFormat  *string `json:"access_token_format"`
+}
+
+// SecretInput describes a new service account secret.
+type SecretInput struct {
+	Description string     `json:"description"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+}
+
+// NewSecret is a freshly generated secret. The plaintext is only available at
+// creation time.
+type NewSecret struct {
+	model.ServiceAccountSecret
+	Secret string `json:"secret"`
+}
+
+func (s *service) ListServiceAccounts(actorID, orgID uuid.UUID) ([]model.ServiceAccount, error) {
+	if err := s.authorize(actorID, orgID, rbac.PermServiceAccountsRead); err != nil {
+		return nil, err
+	}
+	return s.serviceAccountRepo.ListByOrganization(orgID)
+}
+
+// CreateServiceAccount creates a machine identity in the organization along
+// with its first secret.
+func (s *service) CreateServiceAccount(actorID, orgID uuid.UUID, input ServiceAccountInput) (*model.ServiceAccount, *NewSecret, error) {
+	if err := s.authorize(actorID, orgID, rbac.PermServiceAccountsManage); err != nil {
+		return nil, nil, err
+	}
+
+	clientID, err := credential.Generate(12)
+	if err != nil {
+		return nil, nil, err
+	}
+	account := &model.ServiceAccount{
+		OrganizationID: orgID,
+		ClientID:       model.ServiceAccountClientIDPrefix + clientID,
+		Role:           string(rbac.RoleMember),
+		TokenFormat:    model.TokenFormatJWT,
+		CreatedBy:      &actorID,
+	}
+	if err := applyServiceAccountInput(account, input); err != nil {
+		return nil, nil, err
+	}
+	if account.Name == "" {
+		return nil, nil, ErrInvalidServiceAccount
+	}
+	if err := s.serviceAccountRepo.Create(account); err != nil {
+		log.Printf("Failed to create service account: %v", err)
+		return nil, nil, err
+	}
+
+	secret, err := s.addSecret(account.ID, SecretInput{Description: "initial"})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s.audit.Record(audit.UserEvent(actorID, orgID, audit.ActionServiceAccountCreated, "service_account", account.ID.String()))
+	return account, secret, nil
+}
+
+func (s *service) GetServiceAccount(actorID, orgID, accountID uuid.UUID) (*model.ServiceAccount, error) {
+	if err := s.authorize(actorID, orgID, rbac.PermServiceAccountsRead); err != nil {
+		return nil, err
+	}
+	return s.getOrgServiceAccount(orgID, accountID)
+}
+
+func (s *service) UpdateServiceAccount(actorID, orgID, accountID uuid.UUID, input ServiceAccountInput) (*model.ServiceAccount, error) {
+	if err := s.authorize(actorID, orgID, rbac.PermServiceAccountsManage); err != nil {
+		return nil, err
+	}
+
+	account, err := s.getOrgServiceAccount(orgID, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyServiceAccountInput(account, input); err != nil {
+		return nil, err
+	}
+	if err := s.serviceAccountRepo.Update(account); err != nil {
+		log.Printf("Failed to update service account: %v", err)
+		return nil, err
+	}
+
+	s.audit.Record(audit.UserEvent(actorID, orgID, audit.ActionServiceAccountUpdated, "service_account", account.ID.String()))
+	return account, nil
+}
+
+func (s *service) DeleteServiceAccount(actorID, orgID, accountID uuid.UUID) error {
+	if err := s.authorize(actorID, orgID, rbac.PermServiceAccountsManage); err != nil {
+		return err
+	}
+	if _, err := s.getOrgServiceAccount(orgID, accountID); err != nil {
+		return err
+	}
+	if err := s.serviceAccountRepo.Delete(accountID); err != nil {
+		log.Printf("Failed to delete service account: %v", err)
+		return err
+	}
+
+	s.audit.Record(audit.UserEvent(actorID, orgID, audit.ActionServiceAccountDeleted, "service_account", accountID.String()))
+	return nil
+}
+
+func (s *service) ListServiceAccountSecrets(actorID, orgID, accountID uuid.UUID) ([]model.ServiceAccountSecret, error) {
+	if err := s.authorize(actorID, orgID, rbac.PermServiceAccountsRead); err != nil {
+		return nil, err
+	}
+	if _, err := s.getOrgServiceAccount(orgID, accountID); err != nil {
+		return nil, err
+	}
+	return s.serviceAccountRepo.ListSecrets(accountID)
+}
+
+// CreateServiceAccountSecret adds a secret alongside the existing ones, so a
+// client can be moved to it before the old secret is deleted.
+func (s *service) CreateServiceAccountSecret(actorID, orgID, accountID uuid.UUID, input SecretInput) (*NewSecret, error) {
+	if err := s.authorize(actorID, orgID, rbac.PermServiceAccountsManage); err != nil {
+		return nil, err
+	}
+	if _, err := s.getOrgServiceAccount(orgID, accountID); err != nil {
+		return nil, err
+	}
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(time.Now()) {
+		return nil, ErrInvalidServiceAccount
+	}
+
+	existing, err := s.serviceAccountRepo.ListSecrets(accountID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= maxServiceAccountSecrets {
+		return nil, ErrTooManySecrets
+	}
+
+	secret, err := s.addSecret(accountID, input)
+	if err != nil {
+		return nil, err
+	}
+
+	s.audit.Record(audit.UserEvent(actorID, orgID, audit.ActionServiceAccountSecretCreated, "service_account", accountID.String()))
+	return secret, nil
+}
+
+func (s *service) DeleteServiceAccountSecret(actorID, orgID, accountID, secretID uuid.UUID) error {
+	if err := s.authorize(actorID, orgID, rbac.PermServiceAccountsManage); err != nil {
+		return err
+	}
+	if _, err := s.getOrgServiceAccount(orgID, accountID); err != nil {
+		return err
+	}
+	if err := s.serviceAccountRepo.DeleteSecret(accountID, secretID); err != nil {
+		return err
+	}
+
+	s.audit.Record(audit.UserEvent(actorID, orgID, audit.ActionServiceAccountSecretDeleted, "service_account", accountID.String()))
+	return nil
+}
+
+func (s *service) addSecret(accountID uuid.UUID, input SecretInput) (*NewSecret, error) {
+	plaintext, err := credential.Generate(32)
+	if err != nil {
+		return nil, err
+	}
+	secret := &NewSecret{
+		ServiceAccountSecret: model.ServiceAccountSecret{
+			ServiceAccountID: accountID,
+			SecretHash:       credential.Hash(plaintext),
+			Description:      input.Description,
+			ExpiresAt:        input.ExpiresAt,
+		},
+		Secret: plaintext,
+	}
+	if err := s.serviceAccountRepo.AddSecret(&secret.ServiceAccountSecret); err != nil {
+		log.Printf("Failed to create service account secret: %v", err)
+		return nil, err
+	}
+	return secret, nil
+}
+
+func (s *service) getOrgServiceAccount(orgID, accountID uuid.UUID) (*model.ServiceAccount, error) {
+	account, err := s.serviceAccountRepo.GetByID(accountID)
+	if err != nil {
+		return nil, err
+	}
+	if account.OrganizationID != orgID {
+		return nil, repository.ErrServiceAccountNotFound
+	}
+	return account, nil
+}
+
+// serviceAccountGrant returns the role a service account holds in orgID, or
+// nil if id is not a service account owned by that organization.
+func (s *service) serviceAccountGrant(orgID, id uuid.UUID) (*Grant, error) {
+	account, err := s.serviceAccountRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, repository.ErrServiceAccountNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if account.OrganizationID != orgID || account.Disabled {
+		return nil, nil
+	}
+	return &Grant{OrganizationID: orgID, Role: account.Role, Teams: []model.Team{}}, nil
+}
+
+// applyServiceAccountInput applies input to account. Service accounts can
+// never be owners: ownership carries obligations, such as the last-owner
+// checks, that only make sense for people.
+func applyServiceAccountInput(account *model.ServiceAccount, input ServiceAccountInput) error {
+	if input.Name != nil {
+		name := strings.TrimSpace(*input.Name)
+		if name == "" {
+			return ErrInvalidServiceAccount
+		}
+		account.Name = name
+	}
+	if input.Description != nil {
+		account.Description = *input.Description
+	}
+	if input.Role != nil {
+		role := rbac.Role(*input.Role)
+		if role != rbac.RoleAdmin && role != rbac.RoleMember {
+			return ErrInvalidServiceAccountRole
+		}
+		account.Role = string(role)
+	}
+	if input.Disabled != nil {
+		account.Disabled = *input.Disabled
+	}
+	if input.RequireDPoP != nil {
+		account.RequireDPoP = *input.RequireDPoP
+	}
+	if input.TLSSubjectDN != nil {
+		account.TLSSubjectDN = strings.TrimSpace(*input.TLSSubjectDN)
+	}
+	if input.TokenFormat != nil {
+		if !model.ValidTokenFormat(*input.TokenFormat) {
+			return ErrInvalidServiceAccount
+		}
+		account.TokenFormat = *input.TokenFormat
+	}
+	return nil
+}
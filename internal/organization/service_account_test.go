@@ -0,0 +1,87 @@
+package organization
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/radiatus-ai/auth-service/internal/credential"
+	"github.com/radiatus-ai/auth-service/internal/rbac"
+)
+
+func TestCreateServiceAccount_ReturnsHashedSecret(t *testing.T) {
+	svc, orgRepo, _ := newTestService()
+	orgID, adminID := orgRepo.addOrg(nil), uuid.New()
+	orgRepo.addMember(orgID, adminID, rbac.RoleAdmin)
+
+	name, role := "ci", string(rbac.RoleAdmin)
+	account, secret, err := svc.CreateServiceAccount(adminID, orgID, ServiceAccountInput{Name: &name, Role: &role})
+
+	assert.NoError(t, err)
+	assert.Equal(t, orgID, account.OrganizationID)
+	assert.Contains(t, account.ClientID, "sa_")
+	assert.NotEmpty(t, secret.Secret)
+	assert.True(t, credential.Matches(secret.Secret, secret.SecretHash))
+}
+
+func TestCreateServiceAccount_RejectsOwnerRole(t *testing.T) {
+	svc, orgRepo, _ := newTestService()
+	orgID, ownerID := orgRepo.addOrg(nil), uuid.New()
+	orgRepo.addMember(orgID, ownerID, rbac.RoleOwner)
+
+	name, role := "ci", string(rbac.RoleOwner)
+	_, _, err := svc.CreateServiceAccount(ownerID, orgID, ServiceAccountInput{Name: &name, Role: &role})
+
+	assert.ErrorIs(t, err, ErrInvalidServiceAccountRole)
+}
+
+func TestCreateServiceAccount_ForbiddenForMembers(t *testing.T) {
+	svc, orgRepo, _ := newTestService()
+	orgID, userID := orgRepo.addOrg(nil), uuid.New()
+	orgRepo.addMember(orgID, userID, rbac.RoleMember)
+
+	name := "ci"
+	_, _, err := svc.CreateServiceAccount(userID, orgID, ServiceAccountInput{Name: &name})
+
+	assert.ErrorIs(t, err, ErrForbidden)
+}
+
+func TestCreateServiceAccountSecret_Limit(t *testing.T) {
+	svc, orgRepo, _ := newTestService()
+	orgID, adminID := orgRepo.addOrg(nil), uuid.New()
+	orgRepo.addMember(orgID, adminID, rbac.RoleAdmin)
+
+	name := "ci"
+	account, _, err := svc.CreateServiceAccount(adminID, orgID, ServiceAccountInput{Name: &name})
+	assert.NoError(t, err)
+
+	for i := 1; i < maxServiceAccountSecrets; i++ {
+		_, err := svc.CreateServiceAccountSecret(adminID, orgID, account.ID, SecretInput{})
+		assert.NoError(t, err)
+	}
+	_, err = svc.CreateServiceAccountSecret(adminID, orgID, account.ID, SecretInput{})
+
+	assert.ErrorIs(t, err, ErrTooManySecrets)
+}
+
+func TestEffectiveAccess_ServiceAccountRole(t *testing.T) {
+	svc, orgRepo, _ := newTestService()
+	parentID := orgRepo.addOrg(nil)
+	childID := orgRepo.addOrg(&parentID)
+	adminID := uuid.New()
+	orgRepo.addMember(parentID, adminID, rbac.RoleAdmin)
+
+	name, role := "deployer", string(rbac.RoleAdmin)
+	account, _, err := svc.CreateServiceAccount(adminID, parentID, ServiceAccountInput{Name: &name, Role: &role})
+	assert.NoError(t, err)
+
+	access, err := svc.EffectiveAccess(childID, account.ID)
+	assert.NoError(t, err)
+	assert.True(t, access.Has(rbac.PermMembersManage))
+
+	// Disabled service accounts hold no role.
+	svc.serviceAccountRepo.(*mockServiceAccountRepository).accounts[account.ID].Disabled = true
+	_, err = svc.EffectiveAccess(parentID, account.ID)
+	assert.ErrorIs(t, err, ErrNotMember)
+}
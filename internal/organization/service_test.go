@@ -0,0 +1,411 @@
+package organization
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/radiatus-ai/auth-service/internal/model"
+	"github.com/radiatus-ai/auth-service/internal/rbac"
+	"github.com/radiatus-ai/auth-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockOrganizationRepository struct {
+	repository.OrganizationRepository
+	orgs        map[uuid.UUID]*model.Organization
+	memberships map[[2]uuid.UUID]*model.Membership
+}
+
+func (m *mockOrganizationRepository) GetByID(id uuid.UUID) (*model.Organization, error) {
+	org, ok := m.orgs[id]
+	if !ok {
+		return nil, repository.ErrOrganizationNotFound
+	}
+	copied := *org
+	return &copied, nil
+}
+
+func (m *mockOrganizationRepository) Update(org *model.Organization) error {
+	m.orgs[org.ID] = org
+	return nil
+}
+
+func (m *mockOrganizationRepository) UpdateMemberRole(orgID, userID uuid.UUID, role string) error {
+	m.memberships[[2]uuid.UUID{orgID, userID}].Role = role
+	return nil
+}
+
+func (m *mockOrganizationRepository) CountMembersWithRole(orgID uuid.UUID, role string) (int64, error) {
+	var count int64
+	for key, membership := range m.memberships {
+		if key[0] == orgID && membership.Role == role {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (m *mockOrganizationRepository) RemoveUser(orgID, userID uuid.UUID) error {
+	delete(m.memberships, [2]uuid.UUID{orgID, userID})
+	return nil
+}
+
+func (m *mockOrganizationRepository) RemoveUserUnlessLastOwner(orgID, userID uuid.UUID, ownerRole string) error {
+	owners, _ := m.CountMembersWithRole(orgID, ownerRole)
+	if m.memberships[[2]uuid.UUID{orgID, userID}].Role == ownerRole && owners <= 1 {
+		return repository.ErrLastOwner
+	}
+	return m.RemoveUser(orgID, userID)
+}
+
+func (m *mockOrganizationRepository) addOrg(parentID *uuid.UUID) uuid.UUID {
+	org := &model.Organization{ID: uuid.New(), ParentID: parentID}
+	m.orgs[org.ID] = org
+	return org.ID
+}
+
+func (m *mockOrganizationRepository) addMember(orgID, userID uuid.UUID, role rbac.Role) {
+	m.memberships[[2]uuid.UUID{orgID, userID}] = &model.Membership{OrganizationID: orgID, UserID: userID, Role: string(role)}
+}
+
+func (m *mockOrganizationRepository) GetMembership(orgID, userID uuid.UUID) (*model.Membership, error) {
+	membership, ok := m.memberships[[2]uuid.UUID{orgID, userID}]
+	if !ok {
+		return nil, repository.ErrMembershipNotFound
+	}
+	return membership, nil
+}
+
+type mockTeamRepository struct {
+	repository.TeamRepository
+	teams   map[uuid.UUID]*model.Team
+	members map[uuid.UUID][]uuid.UUID
+}
+
+func (m *mockTeamRepository) GetByID(id uuid.UUID) (*model.Team, error) {
+	team, ok := m.teams[id]
+	if !ok {
+		return nil, repository.ErrTeamNotFound
+	}
+	copied := *team
+	return &copied, nil
+}
+
+func (m *mockTeamRepository) Create(team *model.Team) error {
+	team.ID = uuid.New()
+	m.teams[team.ID] = team
+	return nil
+}
+
+func (m *mockTeamRepository) AddMember(teamID, userID uuid.UUID) error {
+	m.members[teamID] = append(m.members[teamID], userID)
+	return nil
+}
+
+func (m *mockTeamRepository) Update(team *model.Team) error {
+	m.teams[team.ID] = team
+	return nil
+}
+
+func (m *mockTeamRepository) ListByOrganization(orgID uuid.UUID) ([]model.Team, error) {
+	var teams []model.Team
+	for _, team := range m.teams {
+		if team.OrganizationID == orgID {
+			teams = append(teams, *team)
+		}
+	}
+	return teams, nil
+}
+
+func (m *mockTeamRepository) GetUserTeams(orgID, userID uuid.UUID) ([]model.Team, error) {
+	var teams []model.Team
+	for teamID, members := range m.members {
+		for _, member := range members {
+			if member == userID && m.teams[teamID].OrganizationID == orgID {
+				teams = append(teams, *m.teams[teamID])
+			}
+		}
+	}
+	return teams, nil
+}
+
+func (m *mockTeamRepository) RemoveUserFromOrganization(orgID, userID uuid.UUID) error {
+	return nil
+}
+
+type mockTransferRepository struct {
+	transfers []*model.OwnershipTransfer
+	orgRepo   *mockOrganizationRepository
+}
+
+func (m *mockTransferRepository) Create(transfer *model.OwnershipTransfer) error {
+	transfer.ID = uuid.New()
+	m.transfers = append(m.transfers, transfer)
+	return nil
+}
+
+func (m *mockTransferRepository) GetPending(orgID uuid.UUID) (*model.OwnershipTransfer, error) {
+	for _, transfer := range m.transfers {
+		if transfer.OrganizationID == orgID && transfer.Status == model.TransferPending {
+			return transfer, nil
+		}
+	}
+	return nil, repository.ErrTransferNotFound
+}
+
+func (m *mockTransferRepository) Update(transfer *model.OwnershipTransfer) error {
+	return nil
+}
+
+func (m *mockTransferRepository) Accept(transfer *model.OwnershipTransfer, recipientRole, initiatorRole string) error {
+	if transfer.Status != model.TransferPending {
+		return repository.ErrTransferNotFound
+	}
+	m.orgRepo.UpdateMemberRole(transfer.OrganizationID, transfer.ToUserID, recipientRole)
+	m.orgRepo.UpdateMemberRole(transfer.OrganizationID, transfer.FromUserID, initiatorRole)
+	now := time.Now()
+	transfer.Status = model.TransferAccepted
+	transfer.ResolvedAt = &now
+	return nil
+}
+
+type mockAuditLogger struct {
+	events []*model.AuditEvent
+}
+
+func (m *mockAuditLogger) Record(event *model.AuditEvent) {
+	m.events = append(m.events, event)
+}
+
+func (m *mockAuditLogger) ListByOrganization(orgID uuid.UUID, limit int) ([]model.AuditEvent, error) {
+	return nil, nil
+}
+
+type mockServiceAccountRepository struct {
+	repository.ServiceAccountRepository
+	accounts map[uuid.UUID]*model.ServiceAccount
+	secrets  []model.ServiceAccountSecret
+	policies []model.TrustPolicy
+}
+
+func (m *mockServiceAccountRepository) Create(account *model.ServiceAccount) error {
+	account.ID = uuid.New()
+	m.accounts[account.ID] = account
+	return nil
+}
+
+func (m *mockServiceAccountRepository) GetByID(id uuid.UUID) (*model.ServiceAccount, error) {
+	account, ok := m.accounts[id]
+	if !ok {
+		return nil, repository.ErrServiceAccountNotFound
+	}
+	copied := *account
+	return &copied, nil
+}
+
+func (m *mockServiceAccountRepository) AddSecret(secret *model.ServiceAccountSecret) error {
+	secret.ID = uuid.New()
+	m.secrets = append(m.secrets, *secret)
+	return nil
+}
+
+func (m *mockServiceAccountRepository) ListSecrets(accountID uuid.UUID) ([]model.ServiceAccountSecret, error) {
+	var secrets []model.ServiceAccountSecret
+	for _, secret := range m.secrets {
+		if secret.ServiceAccountID == accountID {
+			secrets = append(secrets, secret)
+		}
+	}
+	return secrets, nil
+}
+
+func (m *mockServiceAccountRepository) AddTrustPolicy(policy *model.TrustPolicy) error {
+	policy.ID = uuid.New()
+	m.policies = append(m.policies, *policy)
+	return nil
+}
+
+func (m *mockServiceAccountRepository) ListTrustPolicies(accountID uuid.UUID) ([]model.TrustPolicy, error) {
+	var policies []model.TrustPolicy
+	for _, policy := range m.policies {
+		if policy.ServiceAccountID == accountID {
+			policies = append(policies, policy)
+		}
+	}
+	return policies, nil
+}
+
+type mockAPIKeyRepository struct {
+	repository.APIKeyRepository
+	keys map[uuid.UUID]*model.APIKey
+}
+
+func (m *mockAPIKeyRepository) Create(key *model.APIKey) error {
+	key.ID = uuid.New()
+	m.keys[key.ID] = key
+	return nil
+}
+
+func (m *mockAPIKeyRepository) GetByID(id uuid.UUID) (*model.APIKey, error) {
+	key, ok := m.keys[id]
+	if !ok {
+		return nil, repository.ErrAPIKeyNotFound
+	}
+	copied := *key
+	return &copied, nil
+}
+
+func (m *mockAPIKeyRepository) Update(key *model.APIKey) error {
+	m.keys[key.ID] = key
+	return nil
+}
+
+func newTestService() (*service, *mockOrganizationRepository, *mockTeamRepository) {
+	orgRepo := &mockOrganizationRepository{orgs: map[uuid.UUID]*model.Organization{}, memberships: map[[2]uuid.UUID]*model.Membership{}}
+	teamRepo := &mockTeamRepository{teams: map[uuid.UUID]*model.Team{}, members: map[uuid.UUID][]uuid.UUID{}}
+	return &service{
+		orgRepo:      orgRepo,
+		teamRepo:     teamRepo,
+		transferRepo: &mockTransferRepository{orgRepo: orgRepo},
+		serviceAccountRepo: &mockServiceAccountRepository{
+			accounts: map[uuid.UUID]*model.ServiceAccount{},
+		},
+		apiKeyRepo: &mockAPIKeyRepository{keys: map[uuid.UUID]*model.APIKey{}},
+		audit:      &mockAuditLogger{},
+	}, orgRepo, teamRepo
+}
+
+func TestEffectiveAccess_UnionOfDirectAndInheritedTeamGrants(t *testing.T) {
+	svc, orgRepo, teamRepo := newTestService()
+	orgID, userID := orgRepo.addOrg(nil), uuid.New()
+	orgRepo.addMember(orgID, userID, rbac.RoleMember)
+
+	parent := &model.Team{ID: uuid.New(), OrganizationID: orgID, Name: "platform", Role: string(rbac.RoleAdmin)}
+	child := &model.Team{ID: uuid.New(), OrganizationID: orgID, Name: "platform-oncall", ParentTeamID: &parent.ID}
+	teamRepo.teams[parent.ID] = parent
+	teamRepo.teams[child.ID] = child
+	teamRepo.members[child.ID] = []uuid.UUID{userID}
+
+	access, err := svc.EffectiveAccess(orgID, userID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, string(rbac.RoleMember), access.Role)
+	assert.Len(t, access.Teams, 2)
+	assert.True(t, access.Has(rbac.PermTeamsManage))
+	assert.False(t, access.Has(rbac.PermOrgDelete))
+}
+
+func TestEffectiveAccess_NotMember(t *testing.T) {
+	svc, orgRepo, _ := newTestService()
+
+	_, err := svc.EffectiveAccess(orgRepo.addOrg(nil), uuid.New())
+
+	assert.ErrorIs(t, err, ErrNotMember)
+}
+
+func TestUpdateTeam_RejectsCycle(t *testing.T) {
+	svc, orgRepo, teamRepo := newTestService()
+	orgID, adminID := orgRepo.addOrg(nil), uuid.New()
+	orgRepo.addMember(orgID, adminID, rbac.RoleAdmin)
+
+	parent := &model.Team{ID: uuid.New(), OrganizationID: orgID, Name: "parent"}
+	child := &model.Team{ID: uuid.New(), OrganizationID: orgID, Name: "child", ParentTeamID: &parent.ID}
+	teamRepo.teams[parent.ID] = parent
+	teamRepo.teams[child.ID] = child
+
+	newParent := child.ID.String()
+	_, err := svc.UpdateTeam(adminID, orgID, parent.ID, TeamInput{ParentTeamID: &newParent})
+
+	assert.ErrorIs(t, err, ErrTeamCycle)
+}
+
+func TestUpdateTeam_StopsAtExistingCycle(t *testing.T) {
+	svc, orgRepo, teamRepo := newTestService()
+	orgID, adminID := orgRepo.addOrg(nil), uuid.New()
+	orgRepo.addMember(orgID, adminID, rbac.RoleAdmin)
+
+	first := &model.Team{ID: uuid.New(), OrganizationID: orgID, Name: "first"}
+	second := &model.Team{ID: uuid.New(), OrganizationID: orgID, Name: "second", ParentTeamID: &first.ID}
+	first.ParentTeamID = &second.ID
+	team := &model.Team{ID: uuid.New(), OrganizationID: orgID, Name: "team"}
+	for _, existing := range []*model.Team{first, second, team} {
+		teamRepo.teams[existing.ID] = existing
+	}
+
+	newParent := first.ID.String()
+	_, err := svc.UpdateTeam(adminID, orgID, team.ID, TeamInput{ParentTeamID: &newParent})
+
+	assert.ErrorIs(t, err, ErrTeamCycle)
+}
+
+func TestCreateTeam_ForbiddenForMembers(t *testing.T) {
+	svc, orgRepo, _ := newTestService()
+	orgID, userID := orgRepo.addOrg(nil), uuid.New()
+	orgRepo.addMember(orgID, userID, rbac.RoleMember)
+
+	name := "engineering"
+	_, err := svc.CreateTeam(userID, orgID, TeamInput{Name: &name})
+
+	assert.ErrorIs(t, err, ErrForbidden)
+}
+
+func TestTeams_AdminCannotGrantOwner(t *testing.T) {
+	svc, orgRepo, teamRepo := newTestService()
+	orgID, adminID, ownerID := orgRepo.addOrg(nil), uuid.New(), uuid.New()
+	orgRepo.addMember(orgID, adminID, rbac.RoleAdmin)
+	orgRepo.addMember(orgID, ownerID, rbac.RoleOwner)
+
+	name, owner := "root", string(rbac.RoleOwner)
+	_, err := svc.CreateTeam(adminID, orgID, TeamInput{Name: &name, Role: &owner})
+	assert.ErrorIs(t, err, ErrForbidden)
+
+	ownerTeam, err := svc.CreateTeam(ownerID, orgID, TeamInput{Name: &name, Role: &owner})
+	assert.NoError(t, err)
+	assert.ErrorIs(t, svc.AddTeamMember(adminID, orgID, ownerTeam.ID, adminID), ErrForbidden)
+
+	// Nor through a team nested under the owner team.
+	admins := "admins"
+	team, err := svc.CreateTeam(adminID, orgID, TeamInput{Name: &admins})
+	assert.NoError(t, err)
+	parent := ownerTeam.ID.String()
+	_, err = svc.UpdateTeam(adminID, orgID, team.ID, TeamInput{ParentTeamID: &parent})
+	assert.ErrorIs(t, err, ErrForbidden)
+	_, err = svc.UpdateTeam(adminID, orgID, team.ID, TeamInput{Role: &owner})
+	assert.ErrorIs(t, err, ErrForbidden)
+
+	access, err := svc.EffectiveAccess(orgID, adminID)
+	assert.NoError(t, err)
+	assert.False(t, access.Has(rbac.PermOrgDelete))
+	assert.Empty(t, teamRepo.members[ownerTeam.ID])
+}
+
+func TestEffectiveAccess_InheritedFromParentOrganization(t *testing.T) {
+	svc, orgRepo, _ := newTestService()
+	parentID := orgRepo.addOrg(nil)
+	childID := orgRepo.addOrg(&parentID)
+	grandchildID := orgRepo.addOrg(&childID)
+	adminID := uuid.New()
+	orgRepo.addMember(parentID, adminID, rbac.RoleAdmin)
+
+	access, err := svc.EffectiveAccess(grandchildID, adminID)
+
+	assert.NoError(t, err)
+	assert.Empty(t, access.Role)
+	assert.Len(t, access.Inherited, 1)
+	assert.Equal(t, parentID, access.Inherited[0].OrganizationID)
+	assert.True(t, access.Has(rbac.PermMembersManage))
+}
+
+func TestUpdateOrganization_RejectsCycle(t *testing.T) {
+	svc, orgRepo, _ := newTestService()
+	parentID := orgRepo.addOrg(nil)
+	childID := orgRepo.addOrg(&parentID)
+	ownerID := uuid.New()
+	orgRepo.addMember(parentID, ownerID, rbac.RoleOwner)
+
+	newParent := childID.String()
+	_, err := svc.UpdateOrganization(ownerID, parentID, OrganizationInput{ParentID: &newParent})
+
+	assert.ErrorIs(t, err, ErrOrganizationCycle)
+}
@@ -0,0 +1,93 @@
+package organization
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+
+	"github.com/radiatus-ai/auth-service/internal/auth"
+	"github.com/radiatus-ai/auth-service/internal/model"
+	"github.com/radiatus-ai/auth-service/internal/rbac"
+)
+
+var (
+	slugPattern  = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+	colorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+)
+
+// settingsSchema lists the keys an organization settings document may contain
+// and the validation applied to each. Integration specific data that the
+// service does not interpret belongs under "custom", which accepts any object.
+var settingsSchema = map[string]func(value interface{}) error{
+	"default_member_role":     validateRoleSetting,
+	"require_verified_domain": validateBoolSetting,
+	"locale":                  validateStringSetting(35),
+	auth.SessionPolicySetting: auth.ValidateSessionPolicySetting,
+	"custom":                  validateObjectSetting,
+}
+
+// ValidateSettings checks a settings document against settingsSchema.
+func ValidateSettings(settings model.Settings) error {
+	for key, value := range settings {
+		validate, ok := settingsSchema[key]
+		if !ok {
+			return fmt.Errorf("%w: unknown key %q", ErrInvalidSettings, key)
+		}
+		if err := validate(value); err != nil {
+			return fmt.Errorf("%w: %s %v", ErrInvalidSettings, key, err)
+		}
+	}
+	return nil
+}
+
+func validateRoleSetting(value interface{}) error {
+	role, ok := value.(string)
+	if !ok || !rbac.Role(role).Valid() {
+		return fmt.Errorf("must be one of owner, admin, member")
+	}
+	return nil
+}
+
+func validateBoolSetting(value interface{}) error {
+	if _, ok := value.(bool); !ok {
+		return fmt.Errorf("must be a boolean")
+	}
+	return nil
+}
+
+func validateStringSetting(maxLen int) func(interface{}) error {
+	return func(value interface{}) error {
+		s, ok := value.(string)
+		if !ok || len(s) > maxLen {
+			return fmt.Errorf("must be a string of at most %d characters", maxLen)
+		}
+		return nil
+	}
+}
+
+func validateObjectSetting(value interface{}) error {
+	if _, ok := value.(map[string]interface{}); !ok {
+		return fmt.Errorf("must be an object")
+	}
+	return nil
+}
+
+func validateSlug(slug string) error {
+	if !slugPattern.MatchString(slug) {
+		return ErrInvalidSlug
+	}
+	return nil
+}
+
+func validateBranding(logoURL, primaryColor string) error {
+	if logoURL != "" {
+		u, err := url.Parse(logoURL)
+		if err != nil || u.Scheme != "https" || u.Host == "" {
+			return fmt.Errorf("%w: logo_url must be an https URL", ErrInvalidBranding)
+		}
+	}
+	if primaryColor != "" && !colorPattern.MatchString(primaryColor) {
+		return fmt.Errorf("%w: primary_color must be a hex color like #1a2b3c", ErrInvalidBranding)
+	}
+	return nil
+}
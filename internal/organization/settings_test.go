@@ -0,0 +1,37 @@
+package organization
+
+import (
+	"testing"
+
+	"github.com/radiatus-ai/auth-service/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateSettings(t *testing.T) {
+	valid := model.Settings{
+		"default_member_role":     "member",
+		"require_verified_domain": true,
+		"custom":                  map[string]interface{}{"plan": "enterprise"},
+	}
+	assert.NoError(t, ValidateSettings(valid))
+
+	assert.ErrorIs(t, ValidateSettings(model.Settings{"unknown": 1}), ErrInvalidSettings)
+	assert.ErrorIs(t, ValidateSettings(model.Settings{"default_member_role": "superuser"}), ErrInvalidSettings)
+	assert.ErrorIs(t, ValidateSettings(model.Settings{"require_verified_domain": "yes"}), ErrInvalidSettings)
+	assert.ErrorIs(t, ValidateSettings(model.Settings{"custom": "not an object"}), ErrInvalidSettings)
+}
+
+func TestValidateBranding(t *testing.T) {
+	assert.NoError(t, validateBranding("https://cdn.example.com/logo.png", "#1a2B3c"))
+	assert.ErrorIs(t, validateBranding("http://cdn.example.com/logo.png", ""), ErrInvalidBranding)
+	assert.ErrorIs(t, validateBranding("", "red"), ErrInvalidBranding)
+}
+
+func TestValidateSettings_SessionPolicy(t *testing.T) {
+	assert.NoError(t, ValidateSettings(model.Settings{
+		"session_policy": map[string]interface{}{"token_ttl_seconds": float64(900), "idle_timeout_seconds": float64(1800)},
+	}))
+	assert.ErrorIs(t, ValidateSettings(model.Settings{
+		"session_policy": map[string]interface{}{"token_ttl_seconds": float64(1)},
+	}), ErrInvalidSettings)
+}
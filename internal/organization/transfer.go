@@ -0,0 +1,189 @@
+package organization
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/radiatus-ai/auth-service/internal/audit"
+	"github.com/radiatus-ai/auth-service/internal/model"
+	"github.com/radiatus-ai/auth-service/internal/rbac"
+	"github.com/radiatus-ai/auth-service/internal/repository"
+)
+
+// transferTTL is how long a recipient has to accept an ownership transfer.
+const transferTTL = 7 * 24 * time.Hour
+
+// InitiateTransfer offers ownership of the organization to another member.
+// Only a direct owner may do so, and any earlier pending offer is cancelled.
+func (s *service) InitiateTransfer(actorID, orgID, toUserID uuid.UUID) (*model.OwnershipTransfer, error) {
+	if err := s.requireOwner(actorID, orgID); err != nil {
+		return nil, err
+	}
+	if toUserID == actorID {
+		return nil, ErrInvalidTransfer
+	}
+	if _, err := s.orgRepo.GetMembership(orgID, toUserID); err != nil {
+		if errors.Is(err, repository.ErrMembershipNotFound) {
+			return nil, ErrNotMember
+		}
+		return nil, err
+	}
+
+	if pending, err := s.transferRepo.GetPending(orgID); err == nil {
+		if err := s.resolveTransfer(pending, model.TransferCancelled); err != nil {
+			return nil, err
+		}
+	} else if !errors.Is(err, repository.ErrTransferNotFound) {
+		return nil, err
+	}
+
+	transfer := &model.OwnershipTransfer{
+		OrganizationID: orgID,
+		FromUserID:     actorID,
+		ToUserID:       toUserID,
+		Status:         model.TransferPending,
+		ExpiresAt:      time.Now().Add(transferTTL),
+	}
+	if err := s.transferRepo.Create(transfer); err != nil {
+		log.Printf("Failed to create ownership transfer: %v", err)
+		return nil, err
+	}
+
+	s.audit.Record(audit.UserEvent(actorID, orgID, audit.ActionTransferInitiated, "user", toUserID.String()))
+	return transfer, nil
+}
+
+func (s *service) GetPendingTransfer(actorID, orgID uuid.UUID) (*model.OwnershipTransfer, error) {
+	if err := s.authorize(actorID, orgID, rbac.PermOrgRead); err != nil {
+		return nil, err
+	}
+	return s.transferRepo.GetPending(orgID)
+}
+
+// AcceptTransfer makes the recipient an owner and demotes the initiating
+// owner to admin, in one transaction so that a failure cannot leave the
+// organization with both or neither as owner.
+func (s *service) AcceptTransfer(actorID, orgID uuid.UUID) (*model.OwnershipTransfer, error) {
+	transfer, err := s.transferRepo.GetPending(orgID)
+	if err != nil {
+		return nil, err
+	}
+	if transfer.ToUserID != actorID {
+		return nil, ErrForbidden
+	}
+	if time.Now().After(transfer.ExpiresAt) {
+		if err := s.resolveTransfer(transfer, model.TransferCancelled); err != nil {
+			return nil, err
+		}
+		return nil, ErrTransferExpired
+	}
+	if err := s.requireOwner(transfer.FromUserID, orgID); err != nil {
+		// The initiator lost ownership after making the offer.
+		if err := s.resolveTransfer(transfer, model.TransferCancelled); err != nil {
+			return nil, err
+		}
+		return nil, ErrTransferExpired
+	}
+
+	if err := s.transferRepo.Accept(transfer, string(rbac.RoleOwner), string(rbac.RoleAdmin)); err != nil {
+		log.Printf("Failed to accept ownership transfer %s: %v", transfer.ID, err)
+		return nil, err
+	}
+
+	s.audit.Record(audit.UserEvent(actorID, orgID, audit.ActionTransferAccepted, "user", transfer.FromUserID.String()))
+	return transfer, nil
+}
+
+// CancelTransfer withdraws a pending offer when called by the initiator, or
+// declines it when called by the recipient.
+func (s *service) CancelTransfer(actorID, orgID uuid.UUID) error {
+	transfer, err := s.transferRepo.GetPending(orgID)
+	if err != nil {
+		return err
+	}
+
+	switch actorID {
+	case transfer.FromUserID:
+		if err := s.resolveTransfer(transfer, model.TransferCancelled); err != nil {
+			return err
+		}
+		s.audit.Record(audit.UserEvent(actorID, orgID, audit.ActionTransferCancelled, "user", transfer.ToUserID.String()))
+	case transfer.ToUserID:
+		if err := s.resolveTransfer(transfer, model.TransferDeclined); err != nil {
+			return err
+		}
+		s.audit.Record(audit.UserEvent(actorID, orgID, audit.ActionTransferDeclined, "user", transfer.FromUserID.String()))
+	default:
+		return ErrForbidden
+	}
+	return nil
+}
+
+// LeaveOrganization removes the actor's own membership. The last owner cannot
+// leave, since that would leave nobody able to administer the organization.
+func (s *service) LeaveOrganization(actorID, orgID uuid.UUID) error {
+	if _, err := s.orgRepo.GetMembership(orgID, actorID); err != nil {
+		if errors.Is(err, repository.ErrMembershipNotFound) {
+			return ErrNotMember
+		}
+		return err
+	}
+
+	if err := s.orgRepo.RemoveUserUnlessLastOwner(orgID, actorID, string(rbac.RoleOwner)); err != nil {
+		if errors.Is(err, repository.ErrLastOwner) {
+			return ErrLastOwner
+		}
+		log.Printf("Failed to remove user from organization: %v", err)
+		return err
+	}
+
+	if pending, err := s.transferRepo.GetPending(orgID); err == nil {
+		if pending.FromUserID == actorID || pending.ToUserID == actorID {
+			if err := s.resolveTransfer(pending, model.TransferCancelled); err != nil {
+				return err
+			}
+		}
+	} else if !errors.Is(err, repository.ErrTransferNotFound) {
+		return err
+	}
+
+	if err := s.teamRepo.RemoveUserFromOrganization(orgID, actorID); err != nil {
+		return err
+	}
+
+	s.audit.Record(audit.UserEvent(actorID, orgID, audit.ActionMemberLeft, "user", actorID.String()))
+	return nil
+}
+
+func (s *service) ListAuditEvents(actorID, orgID uuid.UUID, limit int) ([]model.AuditEvent, error) {
+	if err := s.authorize(actorID, orgID, rbac.PermAuditRead); err != nil {
+		return nil, err
+	}
+	return s.audit.ListByOrganization(orgID, limit)
+}
+
+// requireOwner checks that the user directly owns the organization. Owners of
+// a parent organization do not count.
+func (s *service) requireOwner(userID, orgID uuid.UUID) error {
+	membership, err := s.orgRepo.GetMembership(orgID, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrMembershipNotFound) {
+			return ErrForbidden
+		}
+		return err
+	}
+	if membership.Role != string(rbac.RoleOwner) {
+		return ErrForbidden
+	}
+	return nil
+}
+
+func (s *service) resolveTransfer(transfer *model.OwnershipTransfer, status string) error {
+	now := time.Now()
+	transfer.Status = status
+	transfer.ResolvedAt = &now
+	return s.transferRepo.Update(transfer)
+}
@@ -0,0 +1,65 @@
+package organization
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/radiatus-ai/auth-service/internal/audit"
+	"github.com/radiatus-ai/auth-service/internal/model"
+	"github.com/radiatus-ai/auth-service/internal/rbac"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOwnershipTransfer_AcceptSwapsRoles(t *testing.T) {
+	svc, orgRepo, _ := newTestService()
+	orgID := orgRepo.addOrg(nil)
+	ownerID, memberID := uuid.New(), uuid.New()
+	orgRepo.addMember(orgID, ownerID, rbac.RoleOwner)
+	orgRepo.addMember(orgID, memberID, rbac.RoleMember)
+
+	_, err := svc.InitiateTransfer(ownerID, orgID, memberID)
+	assert.NoError(t, err)
+
+	_, err = svc.AcceptTransfer(ownerID, orgID)
+	assert.ErrorIs(t, err, ErrForbidden)
+
+	transfer, err := svc.AcceptTransfer(memberID, orgID)
+	assert.NoError(t, err)
+	assert.Equal(t, model.TransferAccepted, transfer.Status)
+	assert.Equal(t, string(rbac.RoleOwner), orgRepo.memberships[[2]uuid.UUID{orgID, memberID}].Role)
+	assert.Equal(t, string(rbac.RoleAdmin), orgRepo.memberships[[2]uuid.UUID{orgID, ownerID}].Role)
+
+	events := svc.audit.(*mockAuditLogger).events
+	assert.Equal(t, audit.ActionTransferInitiated, events[0].Action)
+	assert.Equal(t, audit.ActionTransferAccepted, events[1].Action)
+}
+
+func TestOwnershipTransfer_ExpiredOfferRejected(t *testing.T) {
+	svc, orgRepo, _ := newTestService()
+	orgID := orgRepo.addOrg(nil)
+	ownerID, memberID := uuid.New(), uuid.New()
+	orgRepo.addMember(orgID, ownerID, rbac.RoleOwner)
+	orgRepo.addMember(orgID, memberID, rbac.RoleMember)
+
+	transfer, err := svc.InitiateTransfer(ownerID, orgID, memberID)
+	assert.NoError(t, err)
+	transfer.ExpiresAt = time.Now().Add(-time.Minute)
+
+	_, err = svc.AcceptTransfer(memberID, orgID)
+
+	assert.ErrorIs(t, err, ErrTransferExpired)
+	assert.Equal(t, string(rbac.RoleMember), orgRepo.memberships[[2]uuid.UUID{orgID, memberID}].Role)
+}
+
+func TestLeaveOrganization_LastOwnerRefused(t *testing.T) {
+	svc, orgRepo, _ := newTestService()
+	orgID := orgRepo.addOrg(nil)
+	ownerID, memberID := uuid.New(), uuid.New()
+	orgRepo.addMember(orgID, ownerID, rbac.RoleOwner)
+	orgRepo.addMember(orgID, memberID, rbac.RoleMember)
+
+	assert.ErrorIs(t, svc.LeaveOrganization(ownerID, orgID), ErrLastOwner)
+	assert.NoError(t, svc.LeaveOrganization(memberID, orgID))
+	assert.NotContains(t, orgRepo.memberships, [2]uuid.UUID{orgID, memberID})
+}
@@ -0,0 +1,118 @@
+package organization
+
+import (
+	"log"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/radiatus-ai/auth-service/internal/audit"
+	"github.com/radiatus-ai/auth-service/internal/model"
+	"github.com/radiatus-ai/auth-service/internal/rbac"
+)
+
+// maxTrustPolicies bounds the policies per service account; each federated
+// token is checked against all of them.
+const maxTrustPolicies = 20
+
+// TrustPolicyInput describes which external tokens may act as a service
+// account, for example a Kubernetes service account token:
+//
+//	issuer:           https://container.googleapis.com/v1/projects/p/locations/l/clusters/c
+//	subject_pattern:  system:serviceaccount:payments:*
+//	audience_pattern: auth-service
+type TrustPolicyInput struct {
+	Issuer          string `json:"issuer"`
+	SubjectPattern  string `json:"subject_pattern"`
+	AudiencePattern string `json:"audience_pattern"`
+	Description     string `json:"description"`
+}
+
+func (s *service) ListTrustPolicies(actorID, orgID, accountID uuid.UUID) ([]model.TrustPolicy, error) {
+	if err := s.authorize(actorID, orgID, rbac.PermServiceAccountsRead); err != nil {
+		return nil, err
+	}
+	if _, err := s.getOrgServiceAccount(orgID, accountID); err != nil {
+		return nil, err
+	}
+	return s.serviceAccountRepo.ListTrustPolicies(accountID)
+}
+
+// CreateTrustPolicy lets tokens from an external issuer be exchanged for
+// tokens of the service account. The JWKS used to verify them is discovered
+// from the issuer, so it must be served over https.
+func (s *service) CreateTrustPolicy(actorID, orgID, accountID uuid.UUID, input TrustPolicyInput) (*model.TrustPolicy, error) {
+	if err := s.authorize(actorID, orgID, rbac.PermServiceAccountsManage); err != nil {
+		return nil, err
+	}
+	if _, err := s.getOrgServiceAccount(orgID, accountID); err != nil {
+		return nil, err
+	}
+	policy, err := newTrustPolicy(accountID, input)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.serviceAccountRepo.ListTrustPolicies(accountID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= maxTrustPolicies {
+		return nil, ErrTooManyTrustPolicies
+	}
+
+	if err := s.serviceAccountRepo.AddTrustPolicy(policy); err != nil {
+		log.Printf("Failed to create trust policy: %v", err)
+		return nil, err
+	}
+
+	s.audit.Record(audit.UserEvent(actorID, orgID, audit.ActionTrustPolicyCreated, "service_account", accountID.String()))
+	return policy, nil
+}
+
+func (s *service) DeleteTrustPolicy(actorID, orgID, accountID, policyID uuid.UUID) error {
+	if err := s.authorize(actorID, orgID, rbac.PermServiceAccountsManage); err != nil {
+		return err
+	}
+	if _, err := s.getOrgServiceAccount(orgID, accountID); err != nil {
+		return err
+	}
+	if err := s.serviceAccountRepo.DeleteTrustPolicy(accountID, policyID); err != nil {
+		return err
+	}
+
+	s.audit.Record(audit.UserEvent(actorID, orgID, audit.ActionTrustPolicyDeleted, "service_account", accountID.String()))
+	return nil
+}
+
+// newTrustPolicy validates input. A subject pattern of "*" is refused: it
+// would let every workload trusted by the issuer, in any namespace or
+// project, act as the service account.
+func newTrustPolicy(accountID uuid.UUID, input TrustPolicyInput) (*model.TrustPolicy, error) {
+	issuer := strings.TrimRight(strings.TrimSpace(input.Issuer), "/")
+	u, err := url.Parse(issuer)
+	if err != nil || u.Scheme != "https" || u.Host == "" || u.RawQuery != "" || u.Fragment != "" {
+		return nil, ErrInvalidTrustPolicy
+	}
+
+	subject := strings.TrimSpace(input.SubjectPattern)
+	audience := strings.TrimSpace(input.AudiencePattern)
+	if subject == "" || subject == "*" || audience == "" || audience == "*" {
+		return nil, ErrInvalidTrustPolicy
+	}
+	for _, pattern := range []string{subject, audience} {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, ErrInvalidTrustPolicy
+		}
+	}
+
+	return &model.TrustPolicy{
+		ServiceAccountID: accountID,
+		Issuer:           issuer,
+		SubjectPattern:   subject,
+		AudiencePattern:  audience,
+		Description:      input.Description,
+	}, nil
+}
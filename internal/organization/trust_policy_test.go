@@ -0,0 +1,63 @@
+package organization
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/radiatus-ai/auth-service/internal/rbac"
+)
+
+func TestCreateTrustPolicy(t *testing.T) {
+	svc, orgRepo, _ := newTestService()
+	orgID, adminID := orgRepo.addOrg(nil), uuid.New()
+	orgRepo.addMember(orgID, adminID, rbac.RoleAdmin)
+	name := "deployer"
+	account, _, err := svc.CreateServiceAccount(adminID, orgID, ServiceAccountInput{Name: &name})
+	assert.NoError(t, err)
+
+	policy, err := svc.CreateTrustPolicy(adminID, orgID, account.ID, TrustPolicyInput{
+		Issuer:          "https://token.actions.githubusercontent.com/",
+		SubjectPattern:  "repo:radiatus-ai/*:ref:refs/heads/main",
+		AudiencePattern: "auth-service",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "https://token.actions.githubusercontent.com", policy.Issuer)
+	policies, _ := svc.ListTrustPolicies(adminID, orgID, account.ID)
+	assert.Len(t, policies, 1)
+}
+
+func TestCreateTrustPolicy_Validation(t *testing.T) {
+	for name, input := range map[string]TrustPolicyInput{
+		"http issuer":      {Issuer: "http://cluster.local", SubjectPattern: "system:serviceaccount:ci:*", AudiencePattern: "auth-service"},
+		"missing issuer":   {SubjectPattern: "system:serviceaccount:ci:*", AudiencePattern: "auth-service"},
+		"any subject":      {Issuer: "https://cluster.local", SubjectPattern: "*", AudiencePattern: "auth-service"},
+		"missing audience": {Issuer: "https://cluster.local", SubjectPattern: "system:serviceaccount:ci:*"},
+		"bad pattern":      {Issuer: "https://cluster.local", SubjectPattern: "system:[", AudiencePattern: "auth-service"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := newTrustPolicy(uuid.New(), input)
+
+			assert.ErrorIs(t, err, ErrInvalidTrustPolicy)
+		})
+	}
+}
+
+func TestCreateTrustPolicy_ForbiddenForMembers(t *testing.T) {
+	svc, orgRepo, _ := newTestService()
+	orgID, adminID, userID := orgRepo.addOrg(nil), uuid.New(), uuid.New()
+	orgRepo.addMember(orgID, adminID, rbac.RoleAdmin)
+	orgRepo.addMember(orgID, userID, rbac.RoleMember)
+	name := "deployer"
+	account, _, _ := svc.CreateServiceAccount(adminID, orgID, ServiceAccountInput{Name: &name})
+
+	_, err := svc.CreateTrustPolicy(userID, orgID, account.ID, TrustPolicyInput{
+		Issuer:          "https://cluster.local",
+		SubjectPattern:  "system:serviceaccount:ci:*",
+		AudiencePattern: "auth-service",
+	})
+
+	assert.ErrorIs(t, err, ErrForbidden)
+}
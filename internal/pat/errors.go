@@ -0,0 +1,11 @@
+package pat
+
+import "errors"
+
+var (
+	ErrInvalidName   = errors.New("token name is required")
+	ErrInvalidScopes = errors.New("scopes must be one or more of api:read, api:write")
+	ErrInvalidExpiry = errors.New("expiry must be in the future and at most one year away")
+	ErrTooManyTokens = errors.New("too many personal access tokens")
+	// Add other token-related errors here
+)
@@ -0,0 +1,111 @@
+package pat
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/radiatus-ai/auth-service/internal/auth"
+	"github.com/radiatus-ai/auth-service/internal/organization"
+	"github.com/radiatus-ai/auth-service/internal/repository"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+func (h *Handler) ListTokens(c *gin.Context) {
+	userID, ok := sessionUser(c)
+	if !ok {
+		return
+	}
+
+	tokens, err := h.service.ListTokens(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list tokens"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tokens": tokens})
+}
+
+func (h *Handler) CreateToken(c *gin.Context) {
+	userID, ok := sessionUser(c)
+	if !ok {
+		return
+	}
+
+	var req TokenInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	token, plaintext, err := h.service.CreateToken(userID, req)
+	if err != nil {
+		respondError(c, err, "Failed to create token")
+		return
+	}
+
+	// The plaintext is only ever shown here; only its hash is stored.
+	c.JSON(http.StatusCreated, gin.H{"token": token, "secret": plaintext})
+}
+
+func (h *Handler) RevokeToken(c *gin.Context) {
+	userID, ok := sessionUser(c)
+	if !ok {
+		return
+	}
+	tokenID, err := uuid.Parse(c.Param("tokenID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tokenID"})
+		return
+	}
+
+	if err := h.service.RevokeToken(userID, tokenID); err != nil {
+		respondError(c, err, "Failed to revoke token")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// sessionUser returns the signed-in user. Tokens are managed by people, so
+// service accounts and personal access tokens themselves are turned away.
+func sessionUser(c *gin.Context) (uuid.UUID, bool) {
+	claims, _ := c.Get("claims")
+	if claims, ok := claims.(*auth.Claims); ok && (claims.IsServiceAccount() || claims.IsPersonalAccessToken()) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Personal access tokens can only be managed from a signed-in session"})
+		return uuid.Nil, false
+	}
+	userID, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user"})
+		return uuid.Nil, false
+	}
+	return userID, true
+}
+
+func respondError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, repository.ErrPersonalAccessTokenNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrInvalidName),
+		errors.Is(err, ErrInvalidScopes),
+		errors.Is(err, ErrInvalidExpiry):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, organization.ErrNotMember),
+		errors.Is(err, repository.ErrOrganizationNotFound):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+	case errors.Is(err, ErrTooManyTokens):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
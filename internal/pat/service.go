@@ -0,0 +1,133 @@
+package pat
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/radiatus-ai/auth-service/internal/auth"
+	"github.com/radiatus-ai/auth-service/internal/credential"
+	"github.com/radiatus-ai/auth-service/internal/model"
+	"github.com/radiatus-ai/auth-service/internal/organization"
+	"github.com/radiatus-ai/auth-service/internal/repository"
+)
+
+const (
+	// maxTokensPerUser keeps a forgotten loop from minting tokens forever.
+	maxTokensPerUser = 50
+	maxTokenLifetime = 365 * 24 * time.Hour
+	// tokenHintLength is how much of the token, prefix included, is kept in
+	// clear so users can recognize it.
+	tokenHintLength = len(auth.PersonalAccessTokenPrefix) + 4
+)
+
+// Service manages a user's personal access tokens. Verifying them is part of
+// auth.Service.VerifyClaims.
+type Service interface {
+	CreateToken(userID uuid.UUID, input TokenInput) (*model.PersonalAccessToken, string, error)
+	ListTokens(userID uuid.UUID) ([]model.PersonalAccessToken, error)
+	RevokeToken(userID, tokenID uuid.UUID) error
+}
+
+type service struct {
+	patRepo    repository.PersonalAccessTokenRepository
+	orgService organization.Service
+}
+
+func NewService(patRepo repository.PersonalAccessTokenRepository, orgService organization.Service) Service {
+	return &service{
+		patRepo:    patRepo,
+		orgService: orgService,
+	}
+}
+
+// TokenInput describes a new token. A nil ExpiresAt creates a token that
+// never expires; a nil OrganizationID one usable on every organization.
+type TokenInput struct {
+	Name           string     `json:"name"`
+	Scopes         []string   `json:"scopes"`
+	OrganizationID *uuid.UUID `json:"organization_id"`
+	ExpiresAt      *time.Time `json:"expires_at"`
+}
+
+// CreateToken returns the stored token and its plaintext, which is never
+// available again.
+func (s *service) CreateToken(userID uuid.UUID, input TokenInput) (*model.PersonalAccessToken, string, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return nil, "", ErrInvalidName
+	}
+	if err := validateScopes(input.Scopes); err != nil {
+		return nil, "", err
+	}
+	if input.ExpiresAt != nil {
+		now := time.Now()
+		if !input.ExpiresAt.After(now) || input.ExpiresAt.After(now.Add(maxTokenLifetime)) {
+			return nil, "", ErrInvalidExpiry
+		}
+	}
+	if input.OrganizationID != nil {
+		// Only organizations the user can access can be named, so tokens
+		// cannot be used to probe for organization IDs.
+		if _, err := s.orgService.EffectiveAccess(*input.OrganizationID, userID); err != nil {
+			return nil, "", err
+		}
+	}
+
+	existing, err := s.patRepo.ListByUser(userID)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(existing) >= maxTokensPerUser {
+		return nil, "", ErrTooManyTokens
+	}
+
+	secret, err := credential.Generate(32)
+	if err != nil {
+		return nil, "", err
+	}
+	plaintext := auth.PersonalAccessTokenPrefix + secret
+
+	token := &model.PersonalAccessToken{
+		UserID:         userID,
+		OrganizationID: input.OrganizationID,
+		Name:           name,
+		TokenHash:      credential.Hash(plaintext),
+		TokenHint:      plaintext[:tokenHintLength],
+		Scopes:         model.StringList(input.Scopes),
+		ExpiresAt:      input.ExpiresAt,
+	}
+	if err := s.patRepo.Create(token); err != nil {
+		log.Printf("Failed to create personal access token: %v", err)
+		return nil, "", err
+	}
+
+	log.Printf("Created personal access token %s for user ID: %s", token.ID, userID)
+	return token, plaintext, nil
+}
+
+func (s *service) ListTokens(userID uuid.UUID) ([]model.PersonalAccessToken, error) {
+	return s.patRepo.ListByUser(userID)
+}
+
+func (s *service) RevokeToken(userID, tokenID uuid.UUID) error {
+	if err := s.patRepo.Delete(userID, tokenID); err != nil {
+		return err
+	}
+	log.Printf("Revoked personal access token %s for user ID: %s", tokenID, userID)
+	return nil
+}
+
+func validateScopes(scopes []string) error {
+	if len(scopes) == 0 {
+		return ErrInvalidScopes
+	}
+	for _, scope := range scopes {
+		if !model.StringList(auth.PersonalAccessTokenScopes).Contains(scope) {
+			return ErrInvalidScopes
+		}
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package pat
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/radiatus-ai/auth-service/internal/auth"
+	"github.com/radiatus-ai/auth-service/internal/credential"
+	"github.com/radiatus-ai/auth-service/internal/model"
+	"github.com/radiatus-ai/auth-service/internal/organization"
+	"github.com/radiatus-ai/auth-service/internal/repository"
+)
+
+type mockTokenRepository struct {
+	repository.PersonalAccessTokenRepository
+	tokens []*model.PersonalAccessToken
+}
+
+func (m *mockTokenRepository) Create(token *model.PersonalAccessToken) error {
+	token.ID = uuid.New()
+	m.tokens = append(m.tokens, token)
+	return nil
+}
+
+func (m *mockTokenRepository) ListByUser(userID uuid.UUID) ([]model.PersonalAccessToken, error) {
+	var tokens []model.PersonalAccessToken
+	for _, token := range m.tokens {
+		if token.UserID == userID {
+			tokens = append(tokens, *token)
+		}
+	}
+	return tokens, nil
+}
+
+type mockOrganizationService struct {
+	organization.Service
+	members map[uuid.UUID]bool
+}
+
+func (m *mockOrganizationService) EffectiveAccess(orgID, userID uuid.UUID) (*organization.Access, error) {
+	if !m.members[orgID] {
+		return nil, organization.ErrNotMember
+	}
+	return &organization.Access{OrganizationID: orgID, UserID: userID}, nil
+}
+
+func newTestService(memberOf ...uuid.UUID) (*service, *mockTokenRepository) {
+	repo := &mockTokenRepository{}
+	orgService := &mockOrganizationService{members: map[uuid.UUID]bool{}}
+	for _, orgID := range memberOf {
+		orgService.members[orgID] = true
+	}
+	return &service{patRepo: repo, orgService: orgService}, repo
+}
+
+func TestCreateToken_StoresOnlyHash(t *testing.T) {
+	svc, repo := newTestService()
+	userID := uuid.New()
+
+	token, plaintext, err := svc.CreateToken(userID, TokenInput{Name: "deploy script", Scopes: []string{auth.ScopeAPIRead}})
+
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(plaintext, auth.PersonalAccessTokenPrefix))
+	assert.Equal(t, credential.Hash(plaintext), token.TokenHash)
+	assert.True(t, strings.HasPrefix(plaintext, token.TokenHint))
+	assert.NotContains(t, token.TokenHash, plaintext)
+	assert.Len(t, repo.tokens, 1)
+}
+
+func TestCreateToken_Validation(t *testing.T) {
+	orgID := uuid.New()
+	past := time.Now().Add(-time.Hour)
+	tooLate := time.Now().Add(2 * maxTokenLifetime)
+	otherOrg := uuid.New()
+
+	for name, tc := range map[string]struct {
+		input TokenInput
+		want  error
+	}{
+		"missing name":      {TokenInput{Scopes: []string{auth.ScopeAPIRead}}, ErrInvalidName},
+		"no scopes":         {TokenInput{Name: "t"}, ErrInvalidScopes},
+		"unknown scope":     {TokenInput{Name: "t", Scopes: []string{"admin"}}, ErrInvalidScopes},
+		"expired":           {TokenInput{Name: "t", Scopes: []string{auth.ScopeAPIRead}, ExpiresAt: &past}, ErrInvalidExpiry},
+		"too long":          {TokenInput{Name: "t", Scopes: []string{auth.ScopeAPIRead}, ExpiresAt: &tooLate}, ErrInvalidExpiry},
+		"not a member":      {TokenInput{Name: "t", Scopes: []string{auth.ScopeAPIRead}, OrganizationID: &otherOrg}, organization.ErrNotMember},
+		"member of the org": {TokenInput{Name: "t", Scopes: []string{auth.ScopeAPIRead}, OrganizationID: &orgID}, nil},
+	} {
+		t.Run(name, func(t *testing.T) {
+			svc, _ := newTestService(orgID)
+
+			_, _, err := svc.CreateToken(uuid.New(), tc.input)
+
+			if tc.want == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package rbac
+
+import "sort"
+
+// Role is a named bundle of permissions that can be granted to a user, either
+// directly on an organization membership or through a team.
+type Role string
+
+const (
+	RoleOwner  Role = "owner"
+	RoleAdmin  Role = "admin"
+	RoleMember Role = "member"
+)
+
+// Permission is a single action a principal may perform within an organization.
+type Permission string
+
+const (
+	PermOrgRead       Permission = "org:read"
+	PermOrgUpdate     Permission = "org:update"
+	PermOrgDelete     Permission = "org:delete"
+	PermOrgChildren   Permission = "org:children:manage"
+	PermMembersRead   Permission = "members:read"
+	PermMembersManage Permission = "members:manage"
+	PermTeamsRead     Permission = "teams:read"
+	PermTeamsManage   Permission = "teams:manage"
+	PermAuditRead     Permission = "audit:read"
+
+	PermServiceAccountsRead   Permission = "service_accounts:read"
+	PermServiceAccountsManage Permission = "service_accounts:manage"
+	PermAPIKeysRead           Permission = "api_keys:read"
+	PermAPIKeysManage         Permission = "api_keys:manage"
+)
+
+var rolePermissions = map[Role][]Permission{
+	RoleOwner: {
+		PermOrgRead, PermOrgUpdate, PermOrgDelete, PermOrgChildren,
+		PermMembersRead, PermMembersManage,
+		PermTeamsRead, PermTeamsManage,
+		PermAuditRead,
+		PermServiceAccountsRead, PermServiceAccountsManage,
+		PermAPIKeysRead, PermAPIKeysManage,
+	},
+	RoleAdmin: {
+		PermOrgRead, PermOrgUpdate, PermOrgChildren,
+		PermMembersRead, PermMembersManage,
+		PermTeamsRead, PermTeamsManage,
+		PermAuditRead,
+		PermServiceAccountsRead, PermServiceAccountsManage,
+		PermAPIKeysRead, PermAPIKeysManage,
+	},
+	RoleMember: {
+		PermOrgRead,
+		PermMembersRead,
+		PermTeamsRead,
+	},
+}
+
+// Valid reports whether r is one of the known roles.
+func (r Role) Valid() bool {
+	_, ok := rolePermissions[r]
+	return ok
+}
+
+// Permissions returns the permissions granted by r.
+func (r Role) Permissions() []Permission {
+	return rolePermissions[r]
+}
+
+// Valid reports whether p is a known permission. Owners hold every
+// permission, so that is the list to check against.
+func (p Permission) Valid() bool {
+	for _, known := range rolePermissions[RoleOwner] {
+		if p == known {
+			return true
+		}
+	}
+	return false
+}
+
+// PermissionSet is the union of permissions granted by one or more roles.
+type PermissionSet map[Permission]struct{}
+
+func NewPermissionSet(roles ...Role) PermissionSet {
+	set := PermissionSet{}
+	for _, role := range roles {
+		set.Grant(role)
+	}
+	return set
+}
+
+// Grant adds every permission of role to the set.
+func (s PermissionSet) Grant(role Role) {
+	for _, p := range role.Permissions() {
+		s[p] = struct{}{}
+	}
+}
+
+// Add adds individual permissions to the set, for principals such as API
+// keys that hold permissions rather than roles.
+func (s PermissionSet) Add(perms ...Permission) {
+	for _, p := range perms {
+		s[p] = struct{}{}
+	}
+}
+
+func (s PermissionSet) Has(p Permission) bool {
+	_, ok := s[p]
+	return ok
+}
+
+// List returns the permissions in the set in a stable order.
+func (s PermissionSet) List() []Permission {
+	perms := make([]Permission, 0, len(s))
+	for p := range s {
+		perms = append(perms, p)
+	}
+	sort.Slice(perms, func(i, j int) bool { return perms[i] < perms[j] })
+	return perms
+}
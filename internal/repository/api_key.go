@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/radiatus-ai/auth-service/internal/model"
+)
+
+var (
+	ErrAPIKeyNotFound = errors.New("API key not found")
+)
+
+type APIKeyRepository interface {
+	Create(key *model.APIKey) error
+	GetByID(id uuid.UUID) (*model.APIKey, error)
+	GetByHash(hash string) (*model.APIKey, error)
+	ListByOrganization(orgID uuid.UUID) ([]model.APIKey, error)
+	Update(key *model.APIKey) error
+	Delete(id uuid.UUID) error
+	TouchLastUsed(id uuid.UUID, at time.Time, ip string) error
+}
+
+type apiKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyRepository(db *gorm.DB) APIKeyRepository {
+	return &apiKeyRepository{db: db}
+}
+
+func (r *apiKeyRepository) Create(key *model.APIKey) error {
+	return r.db.Create(key).Error
+}
+
+func (r *apiKeyRepository) GetByID(id uuid.UUID) (*model.APIKey, error) {
+	var key model.APIKey
+	if err := r.db.First(&key, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAPIKeyNotFound
+		}
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *apiKeyRepository) GetByHash(hash string) (*model.APIKey, error) {
+	var key model.APIKey
+	if err := r.db.Where("key_hash = ?", hash).First(&key).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAPIKeyNotFound
+		}
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *apiKeyRepository) ListByOrganization(orgID uuid.UUID) ([]model.APIKey, error) {
+	var keys []model.APIKey
+	if err := r.db.Where("organization_id = ?", orgID).Order("created_at DESC").Find(&keys).Error; err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (r *apiKeyRepository) Update(key *model.APIKey) error {
+	return r.db.Save(key).Error
+}
+
+func (r *apiKeyRepository) Delete(id uuid.UUID) error {
+	return r.db.Delete(&model.APIKey{}, id).Error
+}
+
+func (r *apiKeyRepository) TouchLastUsed(id uuid.UUID, at time.Time, ip string) error {
+	return r.db.Model(&model.APIKey{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"last_used_at": at, "last_used_ip": ip}).Error
+}
@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/radiatus-ai/auth-service/internal/model"
+)
+
+type AuditRepository interface {
+	Create(event *model.AuditEvent) error
+	ListByOrganization(orgID uuid.UUID, limit int) ([]model.AuditEvent, error)
+}
+
+type auditRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditRepository(db *gorm.DB) AuditRepository {
+	return &auditRepository{db: db}
+}
+
+func (r *auditRepository) Create(event *model.AuditEvent) error {
+	return r.db.Create(event).Error
+}
+
+func (r *auditRepository) ListByOrganization(orgID uuid.UUID, limit int) ([]model.AuditEvent, error) {
+	var events []model.AuditEvent
+	err := r.db.Where("organization_id = ?", orgID).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&events).Error
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/radiatus-ai/auth-service/internal/model"
+)
+
+var (
+	ErrClientNotFound = errors.New("client not found")
+)
+
+type ClientRepository interface {
+	Create(client *model.OAuthClient) error
+	GetByID(id uuid.UUID) (*model.OAuthClient, error)
+	GetByClientID(clientID string) (*model.OAuthClient, error)
+	List() ([]model.OAuthClient, error)
+	Update(client *model.OAuthClient) error
+}
+
+type clientRepository struct {
+	db *gorm.DB
+}
+
+func NewClientRepository(db *gorm.DB) ClientRepository {
+	return &clientRepository{db: db}
+}
+
+func (r *clientRepository) Create(client *model.OAuthClient) error {
+	return r.db.Create(client).Error
+}
+
+func (r *clientRepository) GetByID(id uuid.UUID) (*model.OAuthClient, error) {
+	var client model.OAuthClient
+	if err := r.db.First(&client, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrClientNotFound
+		}
+		return nil, err
+	}
+	return &client, nil
+}
+
+func (r *clientRepository) GetByClientID(clientID string) (*model.OAuthClient, error) {
+	var client model.OAuthClient
+	if err := r.db.Where("client_id = ?", clientID).First(&client).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrClientNotFound
+		}
+		return nil, err
+	}
+	return &client, nil
+}
+
+func (r *clientRepository) List() ([]model.OAuthClient, error) {
+	var clients []model.OAuthClient
+	if err := r.db.Order("name").Find(&clients).Error; err != nil {
+		return nil, err
+	}
+	return clients, nil
+}
+
+func (r *clientRepository) Update(client *model.OAuthClient) error {
+	return r.db.Save(client).Error
+}
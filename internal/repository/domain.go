@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/radiatus-ai/auth-service/internal/model"
+)
+
+var (
+	ErrDomainNotFound = errors.New("domain not found")
+)
+
+type DomainRepository interface {
+	Create(domain *model.OrganizationDomain) error
+	GetByID(id uuid.UUID) (*model.OrganizationDomain, error)
+	ListByOrganization(orgID uuid.UUID) ([]model.OrganizationDomain, error)
+	GetVerified(domain string) (*model.OrganizationDomain, error)
+	MarkVerified(id uuid.UUID, at time.Time) error
+	Delete(id uuid.UUID) error
+}
+
+type domainRepository struct {
+	db *gorm.DB
+}
+
+func NewDomainRepository(db *gorm.DB) DomainRepository {
+	return &domainRepository{db: db}
+}
+
+func (r *domainRepository) Create(domain *model.OrganizationDomain) error {
+	return r.db.Create(domain).Error
+}
+
+func (r *domainRepository) GetByID(id uuid.UUID) (*model.OrganizationDomain, error) {
+	var domain model.OrganizationDomain
+	if err := r.db.First(&domain, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrDomainNotFound
+		}
+		return nil, err
+	}
+	return &domain, nil
+}
+
+func (r *domainRepository) ListByOrganization(orgID uuid.UUID) ([]model.OrganizationDomain, error) {
+	var domains []model.OrganizationDomain
+	if err := r.db.Where("organization_id = ?", orgID).Order("domain").Find(&domains).Error; err != nil {
+		return nil, err
+	}
+	return domains, nil
+}
+
+// GetVerified returns the organization's claim on a domain that has passed verification.
+func (r *domainRepository) GetVerified(domain string) (*model.OrganizationDomain, error) {
+	var d model.OrganizationDomain
+	if err := r.db.Where("domain = ? AND verified_at IS NOT NULL", domain).First(&d).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrDomainNotFound
+		}
+		return nil, err
+	}
+	return &d, nil
+}
+
+func (r *domainRepository) MarkVerified(id uuid.UUID, at time.Time) error {
+	return r.db.Model(&model.OrganizationDomain{}).Where("id = ?", id).Update("verified_at", at).Error
+}
+
+func (r *domainRepository) Delete(id uuid.UUID) error {
+	return r.db.Delete(&model.OrganizationDomain{}, id).Error
+}
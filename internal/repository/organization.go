@@ -3,27 +3,56 @@ package repository
 
 import (
 	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"github.com/radiatus-ai/auth-service/internal/model"
 )
 
 var (
-	ErrOrganizationNotFound = errors.New("organization not found")
+	ErrOrganizationNotFound    = errors.New("organization not found")
+	ErrMembershipNotFound      = errors.New("membership not found")
+	ErrSlugTaken               = errors.New("slug already in use")
+	ErrOrganizationHasChildren = errors.New("organization has child organizations")
+	ErrLastOwner               = errors.New("the organization has no other owner")
 )
 
+var nonSlugChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// maxSlugLength leaves room for a collision suffix within the 63 character column.
+const maxSlugLength = 50
+
 type OrganizationRepository interface {
 	Create(org *model.Organization) error
 	GetByID(id uuid.UUID) (*model.Organization, error)
+	GetBySlug(slug string) (*model.Organization, error)
+	SlugExists(slug string, excludeID uuid.UUID) (bool, error)
 	Update(org *model.Organization) error
 	Delete(id uuid.UUID) error
+	GetDeletedByID(id uuid.UUID) (*model.Organization, error)
+	Restore(id uuid.UUID) error
+	ListDeletedBefore(cutoff time.Time) ([]model.Organization, error)
+	Purge(id uuid.UUID) error
 	List() ([]model.Organization, error)
+	CountChildren(id uuid.UUID) (int64, error)
+	ListDescendants(id uuid.UUID) ([]model.Organization, error)
 	AddUser(orgID, userID uuid.UUID) error
+	AddMember(orgID, userID uuid.UUID, role string) error
+	GetMembership(orgID, userID uuid.UUID) (*model.Membership, error)
+	UpdateMemberRole(orgID, userID uuid.UUID, role string) error
+	CountMembersWithRole(orgID uuid.UUID, role string) (int64, error)
+	ListUserMemberships(userID uuid.UUID) ([]model.Membership, error)
 	RemoveUser(orgID, userID uuid.UUID) error
+	RemoveUserUnlessLastOwner(orgID, userID uuid.UUID, ownerRole string) error
 	GetUserOrganizations(userID uuid.UUID) ([]model.Organization, error)
 	GetUserOrganization(userID uuid.UUID) (*model.Organization, error)
+	GetUserDeletedOrganizations(userID uuid.UUID) ([]model.Organization, error)
 }
 
 type organizationRepository struct {
@@ -34,10 +63,71 @@ func NewOrganizationRepository(db *gorm.DB) OrganizationRepository {
 	return &organizationRepository{db: db}
 }
 
+// Create inserts the organization, generating a unique slug from its name
+// when none is set.
 func (r *organizationRepository) Create(org *model.Organization) error {
+	if org.Slug == "" {
+		slug, err := r.uniqueSlug(org.Name)
+		if err != nil {
+			return err
+		}
+		org.Slug = slug
+	}
 	return r.db.Create(org).Error
 }
 
+func (r *organizationRepository) GetBySlug(slug string) (*model.Organization, error) {
+	var org model.Organization
+	if err := r.db.Where("slug = ?", slug).First(&org).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOrganizationNotFound
+		}
+		return nil, err
+	}
+	return &org, nil
+}
+
+// SlugExists counts soft-deleted organizations too: their slug is kept for
+// them until they are purged, so that they can be restored.
+func (r *organizationRepository) SlugExists(slug string, excludeID uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.Unscoped().Model(&model.Organization{}).Where("slug = ? AND id <> ?", slug, excludeID).Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *organizationRepository) uniqueSlug(name string) (string, error) {
+	base := Slugify(name)
+	slug := base
+	for i := 2; i <= 10; i++ {
+		exists, err := r.SlugExists(slug, uuid.Nil)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			return slug, nil
+		}
+		slug = fmt.Sprintf("%s-%d", base, i)
+	}
+	// Heavily contended names fall back to a random suffix.
+	return fmt.Sprintf("%s-%s", base, uuid.New().String()[:8]), nil
+}
+
+// Slugify turns an organization name into a lowercase, hyphen separated slug.
+func Slugify(name string) string {
+	slug := nonSlugChars.ReplaceAllString(strings.ToLower(name), "-")
+	if len(slug) > maxSlugLength {
+		slug = slug[:maxSlugLength]
+	}
+	slug = strings.Trim(slug, "-")
+	if slug == "" {
+		return "org"
+	}
+	return slug
+}
+
 func (r *organizationRepository) GetByID(id uuid.UUID) (*model.Organization, error) {
 	var org model.Organization
 	if err := r.db.First(&org, id).Error; err != nil {
@@ -53,10 +143,57 @@ func (r *organizationRepository) Update(org *model.Organization) error {
 	return r.db.Save(org).Error
 }
 
+// Delete soft-deletes the organization. Use Purge to remove it permanently.
 func (r *organizationRepository) Delete(id uuid.UUID) error {
 	return r.db.Delete(&model.Organization{}, id).Error
 }
 
+func (r *organizationRepository) GetDeletedByID(id uuid.UUID) (*model.Organization, error) {
+	var org model.Organization
+	if err := r.db.Unscoped().Where("deleted_at IS NOT NULL").First(&org, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOrganizationNotFound
+		}
+		return nil, err
+	}
+	return &org, nil
+}
+
+func (r *organizationRepository) Restore(id uuid.UUID) error {
+	return r.db.Unscoped().Model(&model.Organization{}).Where("id = ?", id).Update("deleted_at", nil).Error
+}
+
+func (r *organizationRepository) ListDeletedBefore(cutoff time.Time) ([]model.Organization, error) {
+	var orgs []model.Organization
+	// Oldest first, so children, which must be deleted before their parent,
+	// are purged before it.
+	if err := r.db.Unscoped().Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).Order("deleted_at").Find(&orgs).Error; err != nil {
+		return nil, err
+	}
+	return orgs, nil
+}
+
+// Purge permanently removes a soft-deleted organization. Memberships are
+// removed explicitly; teams, domains, transfers and other dependent rows
+// follow through ON DELETE CASCADE. An organization with children, deleted
+// or not, is not purged, since ON DELETE SET NULL would turn them into
+// top-level organizations.
+func (r *organizationRepository) Purge(id uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var children int64
+		if err := tx.Unscoped().Model(&model.Organization{}).Where("parent_id = ?", id).Count(&children).Error; err != nil {
+			return err
+		}
+		if children > 0 {
+			return ErrOrganizationHasChildren
+		}
+		if err := tx.Exec("DELETE FROM user_organizations WHERE organization_id = ?", id).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Where("deleted_at IS NOT NULL").Delete(&model.Organization{}, id).Error
+	})
+}
+
 func (r *organizationRepository) List() ([]model.Organization, error) {
 	var orgs []model.Organization
 	if err := r.db.Find(&orgs).Error; err != nil {
@@ -65,14 +202,104 @@ func (r *organizationRepository) List() ([]model.Organization, error) {
 	return orgs, nil
 }
 
+func (r *organizationRepository) CountChildren(id uuid.UUID) (int64, error) {
+	var count int64
+	if err := r.db.Model(&model.Organization{}).Where("parent_id = ?", id).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// ListDescendants returns the organization itself followed by every
+// organization below it in the hierarchy.
+func (r *organizationRepository) ListDescendants(id uuid.UUID) ([]model.Organization, error) {
+	var orgs []model.Organization
+	err := r.db.Raw(`
+		WITH RECURSIVE tree AS (
+			SELECT * FROM organizations WHERE id = ? AND deleted_at IS NULL
+			UNION ALL
+			SELECT o.* FROM organizations o JOIN tree ON o.parent_id = tree.id WHERE o.deleted_at IS NULL
+		)
+		SELECT * FROM tree ORDER BY name`, id).Scan(&orgs).Error
+	if err != nil {
+		return nil, err
+	}
+	return orgs, nil
+}
+
 func (r *organizationRepository) AddUser(orgID, userID uuid.UUID) error {
 	return r.db.Exec("INSERT INTO user_organizations (user_id, organization_id) VALUES (?, ?)", userID, orgID).Error
 }
 
+func (r *organizationRepository) AddMember(orgID, userID uuid.UUID, role string) error {
+	return r.db.Create(&model.Membership{OrganizationID: orgID, UserID: userID, Role: role}).Error
+}
+
+func (r *organizationRepository) GetMembership(orgID, userID uuid.UUID) (*model.Membership, error) {
+	var membership model.Membership
+	err := r.db.Where("organization_id = ? AND user_id = ?", orgID, userID).First(&membership).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMembershipNotFound
+		}
+		return nil, err
+	}
+	return &membership, nil
+}
+
+func (r *organizationRepository) UpdateMemberRole(orgID, userID uuid.UUID, role string) error {
+	return r.db.Model(&model.Membership{}).
+		Where("organization_id = ? AND user_id = ?", orgID, userID).
+		Update("role", role).Error
+}
+
+func (r *organizationRepository) CountMembersWithRole(orgID uuid.UUID, role string) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.Membership{}).
+		Where("organization_id = ? AND role = ?", orgID, role).
+		Count(&count).Error
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *organizationRepository) ListUserMemberships(userID uuid.UUID) ([]model.Membership, error) {
+	var memberships []model.Membership
+	if err := r.db.Where("user_id = ?", userID).Find(&memberships).Error; err != nil {
+		return nil, err
+	}
+	return memberships, nil
+}
+
 func (r *organizationRepository) RemoveUser(orgID, userID uuid.UUID) error {
 	return r.db.Exec("DELETE FROM user_organizations WHERE user_id = ? AND organization_id = ?", userID, orgID).Error
 }
 
+// RemoveUserUnlessLastOwner removes the membership unless it is the
+// organization's last owner, returning ErrLastOwner then. The owner rows are
+// locked first, so two owners leaving at once cannot both succeed.
+func (r *organizationRepository) RemoveUserUnlessLastOwner(orgID, userID uuid.UUID, ownerRole string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var owners []uuid.UUID
+		if err := tx.Model(&model.Membership{}).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("organization_id = ? AND role = ?", orgID, ownerRole).
+			Pluck("user_id", &owners).Error; err != nil {
+			return err
+		}
+		result := tx.Exec("DELETE FROM user_organizations WHERE user_id = ? AND organization_id = ? AND (role <> ? OR ? > 1)",
+			userID, orgID, ownerRole, len(owners))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrLastOwner
+		}
+		return nil
+	})
+}
+
 func (r *organizationRepository) GetUserOrganizations(userID uuid.UUID) ([]model.Organization, error) {
 	var orgs []model.Organization
 	err := r.db.Joins("JOIN user_organizations ON user_organizations.organization_id = organizations.id").
@@ -98,3 +325,15 @@ func (r *organizationRepository) GetUserOrganization(userID uuid.UUID) (*model.O
 	}
 	return &org, nil
 }
+
+func (r *organizationRepository) GetUserDeletedOrganizations(userID uuid.UUID) ([]model.Organization, error) {
+	var orgs []model.Organization
+	err := r.db.Unscoped().
+		Joins("JOIN user_organizations ON user_organizations.organization_id = organizations.id").
+		Where("user_organizations.user_id = ? AND organizations.deleted_at IS NOT NULL", userID).
+		Find(&orgs).Error
+	if err != nil {
+		return nil, err
+	}
+	return orgs, nil
+}
@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/radiatus-ai/auth-service/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlugify(t *testing.T) {
+	assert.Equal(t, "acme-corp", Slugify("Acme Corp."))
+	assert.Equal(t, "jane-doe", Slugify("jane.doe"))
+	assert.Equal(t, "org", Slugify("!!!"))
+	assert.Len(t, Slugify("a very long organization name that keeps going and going forever"), maxSlugLength)
+}
+
+func TestCreateOrganization_SlugCollision(t *testing.T) {
+	sqlDB, db, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	repo := NewOrganizationRepository(db)
+	org := &model.Organization{Name: "Acme"}
+
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "organizations"`).
+		WithArgs("acme", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "organizations"`).
+		WithArgs("acme-2", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO "organizations"`).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err := repo.Create(org)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "acme-2", org.Slug)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteOrganization_IsSoftDelete(t *testing.T) {
+	sqlDB, db, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	repo := NewOrganizationRepository(db)
+	id := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "organizations" SET "deleted_at"=\$1 WHERE "organizations"."id" = \$2 AND "organizations"."deleted_at" IS NULL`).
+		WithArgs(sqlmock.AnyArg(), id).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, repo.Delete(id))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPurgeOrganization_RemovesMemberships(t *testing.T) {
+	sqlDB, db, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	repo := NewOrganizationRepository(db)
+	id := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "organizations" WHERE parent_id = \$1`).
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectExec(`DELETE FROM user_organizations WHERE organization_id = \$1`).
+		WithArgs(id).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(`DELETE FROM "organizations" WHERE deleted_at IS NOT NULL AND "organizations"."id" = \$1`).
+		WithArgs(id).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, repo.Purge(id))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPurgeOrganization_KeepsParentOfDeletedChildren(t *testing.T) {
+	sqlDB, db, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	repo := NewOrganizationRepository(db)
+	id := uuid.New()
+
+	mock.ExpectBegin()
+	// Unscoped, so soft-deleted children count.
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "organizations" WHERE parent_id = \$1$`).
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectRollback()
+
+	assert.ErrorIs(t, repo.Purge(id), ErrOrganizationHasChildren)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSlugExists_CountsDeletedOrganizations(t *testing.T) {
+	sqlDB, db, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	repo := NewOrganizationRepository(db)
+
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "organizations" WHERE slug = \$1 AND id <> \$2$`).
+		WithArgs("acme", uuid.Nil).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	exists, err := repo.SlugExists("acme", uuid.Nil)
+
+	assert.NoError(t, err)
+	assert.True(t, exists)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRemoveUserUnlessLastOwner_LocksOwners(t *testing.T) {
+	sqlDB, db, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	repo := NewOrganizationRepository(db)
+	orgID, userID := uuid.New(), uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT "user_id" FROM "user_organizations" WHERE organization_id = \$1 AND role = \$2 FOR UPDATE`).
+		WithArgs(orgID, "owner").
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(userID).AddRow(uuid.New()))
+	mock.ExpectExec(`DELETE FROM user_organizations WHERE user_id = \$1 AND organization_id = \$2 AND \(role <> \$3 OR \$4 > 1\)`).
+		WithArgs(userID, orgID, "owner", 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, repo.RemoveUserUnlessLastOwner(orgID, userID, "owner"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRemoveUserUnlessLastOwner_RefusesLastOwner(t *testing.T) {
+	sqlDB, db, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	repo := NewOrganizationRepository(db)
+	orgID, userID := uuid.New(), uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT "user_id" FROM "user_organizations" .* FOR UPDATE`).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(userID))
+	mock.ExpectExec(`DELETE FROM user_organizations`).
+		WithArgs(userID, orgID, "owner", 1).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	assert.ErrorIs(t, repo.RemoveUserUnlessLastOwner(orgID, userID, "owner"), ErrLastOwner)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/radiatus-ai/auth-service/internal/model"
+)
+
+var (
+	ErrReferenceTokenNotFound = errors.New("reference token not found")
+)
+
+type ReferenceTokenRepository interface {
+	Create(token *model.ReferenceToken) error
+	GetByHash(hash string) (*model.ReferenceToken, error)
+	Delete(id uuid.UUID) error
+	DeleteExpired(before time.Time) (int64, error)
+}
+
+type referenceTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewReferenceTokenRepository(db *gorm.DB) ReferenceTokenRepository {
+	return &referenceTokenRepository{db: db}
+}
+
+func (r *referenceTokenRepository) Create(token *model.ReferenceToken) error {
+	return r.db.Create(token).Error
+}
+
+func (r *referenceTokenRepository) GetByHash(hash string) (*model.ReferenceToken, error) {
+	var token model.ReferenceToken
+	if err := r.db.Where("token_hash = ?", hash).First(&token).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrReferenceTokenNotFound
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *referenceTokenRepository) Delete(id uuid.UUID) error {
+	result := r.db.Delete(&model.ReferenceToken{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrReferenceTokenNotFound
+	}
+	return nil
+}
+
+// DeleteExpired removes tokens that expired before before, returning how
+// many there were.
+func (r *referenceTokenRepository) DeleteExpired(before time.Time) (int64, error) {
+	result := r.db.Where("expires_at < ?", before).Delete(&model.ReferenceToken{})
+	return result.RowsAffected, result.Error
+}
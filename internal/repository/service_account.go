@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/radiatus-ai/auth-service/internal/model"
+)
+
+var (
+	ErrServiceAccountNotFound = errors.New("service account not found")
+	ErrSecretNotFound         = errors.New("secret not found")
+	ErrTrustPolicyNotFound    = errors.New("trust policy not found")
+)
+
+type ServiceAccountRepository interface {
+	Create(account *model.ServiceAccount) error
+	GetByID(id uuid.UUID) (*model.ServiceAccount, error)
+	GetByClientID(clientID string) (*model.ServiceAccount, error)
+	ListByOrganization(orgID uuid.UUID) ([]model.ServiceAccount, error)
+	Update(account *model.ServiceAccount) error
+	Delete(id uuid.UUID) error
+	AddSecret(secret *model.ServiceAccountSecret) error
+	ListSecrets(accountID uuid.UUID) ([]model.ServiceAccountSecret, error)
+	DeleteSecret(accountID, secretID uuid.UUID) error
+	TouchSecret(secretID uuid.UUID, at time.Time) error
+	AddTrustPolicy(policy *model.TrustPolicy) error
+	ListTrustPolicies(accountID uuid.UUID) ([]model.TrustPolicy, error)
+	DeleteTrustPolicy(accountID, policyID uuid.UUID) error
+}
+
+type serviceAccountRepository struct {
+	db *gorm.DB
+}
+
+func NewServiceAccountRepository(db *gorm.DB) ServiceAccountRepository {
+	return &serviceAccountRepository{db: db}
+}
+
+func (r *serviceAccountRepository) Create(account *model.ServiceAccount) error {
+	return r.db.Create(account).Error
+}
+
+func (r *serviceAccountRepository) GetByID(id uuid.UUID) (*model.ServiceAccount, error) {
+	var account model.ServiceAccount
+	if err := r.db.First(&account, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrServiceAccountNotFound
+		}
+		return nil, err
+	}
+	return &account, nil
+}
+
+func (r *serviceAccountRepository) GetByClientID(clientID string) (*model.ServiceAccount, error) {
+	var account model.ServiceAccount
+	if err := r.db.Where("client_id = ?", clientID).First(&account).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrServiceAccountNotFound
+		}
+		return nil, err
+	}
+	return &account, nil
+}
+
+func (r *serviceAccountRepository) ListByOrganization(orgID uuid.UUID) ([]model.ServiceAccount, error) {
+	var accounts []model.ServiceAccount
+	if err := r.db.Where("organization_id = ?", orgID).Order("name").Find(&accounts).Error; err != nil {
+		return nil, err
+	}
+	return accounts, nil
+}
+
+func (r *serviceAccountRepository) Update(account *model.ServiceAccount) error {
+	return r.db.Save(account).Error
+}
+
+func (r *serviceAccountRepository) Delete(id uuid.UUID) error {
+	return r.db.Delete(&model.ServiceAccount{}, id).Error
+}
+
+func (r *serviceAccountRepository) AddSecret(secret *model.ServiceAccountSecret) error {
+	return r.db.Create(secret).Error
+}
+
+func (r *serviceAccountRepository) ListSecrets(accountID uuid.UUID) ([]model.ServiceAccountSecret, error) {
+	var secrets []model.ServiceAccountSecret
+	if err := r.db.Where("service_account_id = ?", accountID).Order("created_at").Find(&secrets).Error; err != nil {
+		return nil, err
+	}
+	return secrets, nil
+}
+
+func (r *serviceAccountRepository) DeleteSecret(accountID, secretID uuid.UUID) error {
+	result := r.db.Where("id = ? AND service_account_id = ?", secretID, accountID).Delete(&model.ServiceAccountSecret{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrSecretNotFound
+	}
+	return nil
+}
+
+func (r *serviceAccountRepository) TouchSecret(secretID uuid.UUID, at time.Time) error {
+	return r.db.Model(&model.ServiceAccountSecret{}).Where("id = ?", secretID).Update("last_used_at", at).Error
+}
+
+func (r *serviceAccountRepository) AddTrustPolicy(policy *model.TrustPolicy) error {
+	return r.db.Create(policy).Error
+}
+
+func (r *serviceAccountRepository) ListTrustPolicies(accountID uuid.UUID) ([]model.TrustPolicy, error) {
+	var policies []model.TrustPolicy
+	if err := r.db.Where("service_account_id = ?", accountID).Order("created_at").Find(&policies).Error; err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+func (r *serviceAccountRepository) DeleteTrustPolicy(accountID, policyID uuid.UUID) error {
+	result := r.db.Where("id = ? AND service_account_id = ?", policyID, accountID).Delete(&model.TrustPolicy{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrTrustPolicyNotFound
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/radiatus-ai/auth-service/internal/model"
+)
+
+var (
+	ErrSessionNotFound = errors.New("session not found")
+)
+
+type SessionRepository interface {
+	Create(session *model.Session) error
+	GetByID(id uuid.UUID) (*model.Session, error)
+	GetByRefreshTokenHash(hash string) (*model.Session, error)
+	ListByUser(userID uuid.UUID, now time.Time) ([]model.Session, error)
+	Touch(id uuid.UUID, at time.Time) error
+	Update(session *model.Session) error
+	// Rotate saves a session whose refresh token was replaced, provided its
+	// stored refresh token is still the one with oldHash. It returns
+	// ErrSessionNotFound if another request rotated it first.
+	Rotate(session *model.Session, oldHash string) error
+	Delete(userID, id uuid.UUID) error
+	DeleteOthers(userID, keepID uuid.UUID) (int64, error)
+}
+
+type sessionRepository struct {
+	db *gorm.DB
+}
+
+func NewSessionRepository(db *gorm.DB) SessionRepository {
+	return &sessionRepository{db: db}
+}
+
+func (r *sessionRepository) Create(session *model.Session) error {
+	return r.db.Create(session).Error
+}
+
+func (r *sessionRepository) GetByID(id uuid.UUID) (*model.Session, error) {
+	var session model.Session
+	if err := r.db.Where("id = ?", id).First(&session).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *sessionRepository) GetByRefreshTokenHash(hash string) (*model.Session, error) {
+	var session model.Session
+	if err := r.db.Where("refresh_token_hash = ?", hash).First(&session).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+// ListByUser returns the user's sessions that have not expired at now, most
+// recently used first.
+func (r *sessionRepository) ListByUser(userID uuid.UUID, now time.Time) ([]model.Session, error) {
+	var sessions []model.Session
+	if err := r.db.Where("user_id = ? AND expires_at > ?", userID, now).
+		Order("last_seen_at DESC").Find(&sessions).Error; err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+func (r *sessionRepository) Touch(id uuid.UUID, at time.Time) error {
+	return r.db.Model(&model.Session{}).Where("id = ?", id).Update("last_seen_at", at).Error
+}
+
+func (r *sessionRepository) Update(session *model.Session) error {
+	return r.db.Save(session).Error
+}
+
+func (r *sessionRepository) Rotate(session *model.Session, oldHash string) error {
+	result := r.db.Model(session).Where("refresh_token_hash = ?", oldHash).Select("*").Updates(session)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+// Delete ends one of the user's sessions. Sessions belonging to someone else
+// are reported as not found.
+func (r *sessionRepository) Delete(userID, id uuid.UUID) error {
+	result := r.db.Where("id = ? AND user_id = ?", id, userID).Delete(&model.Session{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+// DeleteOthers ends every session of the user but keepID, which may be
+// uuid.Nil to end them all, and returns how many were ended.
+func (r *sessionRepository) DeleteOthers(userID, keepID uuid.UUID) (int64, error) {
+	result := r.db.Where("user_id = ? AND id <> ?", userID, keepID).Delete(&model.Session{})
+	return result.RowsAffected, result.Error
+}
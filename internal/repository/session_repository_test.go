@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/radiatus-ai/auth-service/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRotateSession_OnlyReplacesTheCurrentRefreshToken(t *testing.T) {
+	sqlDB, db, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	repo := NewSessionRepository(db)
+	newHash := "new-hash"
+	session := &model.Session{ID: uuid.New(), UserID: uuid.New(), RefreshTokenHash: &newHash}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "user_sessions" SET .* WHERE refresh_token_hash = \$\d+ AND "id" = \$\d+`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	assert.ErrorIs(t, repo.Rotate(session, "old-hash"), ErrSessionNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/radiatus-ai/auth-service/internal/model"
+)
+
+var (
+	ErrTeamNotFound = errors.New("team not found")
+)
+
+type TeamRepository interface {
+	Create(team *model.Team) error
+	GetByID(id uuid.UUID) (*model.Team, error)
+	Update(team *model.Team) error
+	Delete(id uuid.UUID) error
+	ListByOrganization(orgID uuid.UUID) ([]model.Team, error)
+	CountChildren(id uuid.UUID) (int64, error)
+	AddMember(teamID, userID uuid.UUID) error
+	RemoveMember(teamID, userID uuid.UUID) error
+	RemoveUserFromOrganization(orgID, userID uuid.UUID) error
+	ListMembers(teamID uuid.UUID) ([]model.User, error)
+	GetUserTeams(orgID, userID uuid.UUID) ([]model.Team, error)
+}
+
+type teamRepository struct {
+	db *gorm.DB
+}
+
+func NewTeamRepository(db *gorm.DB) TeamRepository {
+	return &teamRepository{db: db}
+}
+
+func (r *teamRepository) Create(team *model.Team) error {
+	return r.db.Create(team).Error
+}
+
+func (r *teamRepository) GetByID(id uuid.UUID) (*model.Team, error) {
+	var team model.Team
+	if err := r.db.First(&team, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTeamNotFound
+		}
+		return nil, err
+	}
+	return &team, nil
+}
+
+func (r *teamRepository) Update(team *model.Team) error {
+	return r.db.Save(team).Error
+}
+
+func (r *teamRepository) Delete(id uuid.UUID) error {
+	return r.db.Delete(&model.Team{}, id).Error
+}
+
+func (r *teamRepository) ListByOrganization(orgID uuid.UUID) ([]model.Team, error) {
+	var teams []model.Team
+	if err := r.db.Where("organization_id = ?", orgID).Order("name").Find(&teams).Error; err != nil {
+		return nil, err
+	}
+	return teams, nil
+}
+
+func (r *teamRepository) CountChildren(id uuid.UUID) (int64, error) {
+	var count int64
+	if err := r.db.Model(&model.Team{}).Where("parent_team_id = ?", id).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *teamRepository) AddMember(teamID, userID uuid.UUID) error {
+	return r.db.Exec("INSERT INTO team_members (team_id, user_id) VALUES (?, ?) ON CONFLICT DO NOTHING", teamID, userID).Error
+}
+
+func (r *teamRepository) RemoveMember(teamID, userID uuid.UUID) error {
+	return r.db.Exec("DELETE FROM team_members WHERE team_id = ? AND user_id = ?", teamID, userID).Error
+}
+
+// RemoveUserFromOrganization drops the user from every team in the organization.
+func (r *teamRepository) RemoveUserFromOrganization(orgID, userID uuid.UUID) error {
+	return r.db.Exec("DELETE FROM team_members WHERE user_id = ? AND team_id IN (SELECT id FROM teams WHERE organization_id = ?)", userID, orgID).Error
+}
+
+func (r *teamRepository) ListMembers(teamID uuid.UUID) ([]model.User, error) {
+	var users []model.User
+	err := r.db.Joins("JOIN team_members ON team_members.user_id = users.id").
+		Where("team_members.team_id = ?", teamID).
+		Find(&users).Error
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// GetUserTeams returns the teams in an organization the user is a direct member of.
+func (r *teamRepository) GetUserTeams(orgID, userID uuid.UUID) ([]model.Team, error) {
+	var teams []model.Team
+	err := r.db.Joins("JOIN team_members ON team_members.team_id = teams.id").
+		Where("team_members.user_id = ? AND teams.organization_id = ?", userID, orgID).
+		Find(&teams).Error
+	if err != nil {
+		return nil, err
+	}
+	return teams, nil
+}
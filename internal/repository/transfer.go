@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/radiatus-ai/auth-service/internal/model"
+)
+
+var (
+	ErrTransferNotFound = errors.New("ownership transfer not found")
+)
+
+type TransferRepository interface {
+	Create(transfer *model.OwnershipTransfer) error
+	GetPending(orgID uuid.UUID) (*model.OwnershipTransfer, error)
+	Update(transfer *model.OwnershipTransfer) error
+	// Accept resolves a pending transfer as accepted and gives its recipient
+	// and initiator their new roles, all or nothing. It returns
+	// ErrTransferNotFound if the transfer is no longer pending.
+	Accept(transfer *model.OwnershipTransfer, recipientRole, initiatorRole string) error
+}
+
+type transferRepository struct {
+	db *gorm.DB
+}
+
+func NewTransferRepository(db *gorm.DB) TransferRepository {
+	return &transferRepository{db: db}
+}
+
+func (r *transferRepository) Create(transfer *model.OwnershipTransfer) error {
+	return r.db.Create(transfer).Error
+}
+
+func (r *transferRepository) GetPending(orgID uuid.UUID) (*model.OwnershipTransfer, error) {
+	var transfer model.OwnershipTransfer
+	err := r.db.Where("organization_id = ? AND status = ?", orgID, model.TransferPending).First(&transfer).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTransferNotFound
+		}
+		return nil, err
+	}
+	return &transfer, nil
+}
+
+func (r *transferRepository) Update(transfer *model.OwnershipTransfer) error {
+	return r.db.Save(transfer).Error
+}
+
+func (r *transferRepository) Accept(transfer *model.OwnershipTransfer, recipientRole, initiatorRole string) error {
+	now := time.Now()
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.OwnershipTransfer{}).
+			Where("id = ? AND status = ?", transfer.ID, model.TransferPending).
+			Updates(map[string]interface{}{"status": model.TransferAccepted, "resolved_at": now})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrTransferNotFound
+		}
+		if err := tx.Model(&model.Membership{}).
+			Where("organization_id = ? AND user_id = ?", transfer.OrganizationID, transfer.ToUserID).
+			Update("role", recipientRole).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.Membership{}).
+			Where("organization_id = ? AND user_id = ?", transfer.OrganizationID, transfer.FromUserID).
+			Update("role", initiatorRole).Error
+	})
+	if err != nil {
+		return err
+	}
+	transfer.Status = model.TransferAccepted
+	transfer.ResolvedAt = &now
+	return nil
+}
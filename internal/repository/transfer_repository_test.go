@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/radiatus-ai/auth-service/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAcceptTransfer_IsOneTransaction(t *testing.T) {
+	sqlDB, db, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	repo := NewTransferRepository(db)
+	transfer := &model.OwnershipTransfer{ID: uuid.New(), OrganizationID: uuid.New(), FromUserID: uuid.New(), ToUserID: uuid.New(), Status: model.TransferPending}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "ownership_transfers" SET .* WHERE id = \$\d+ AND status = \$\d+`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE "user_organizations" SET "role"=\$1 WHERE organization_id = \$2 AND user_id = \$3`).
+		WithArgs("owner", transfer.OrganizationID, transfer.ToUserID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE "user_organizations" SET "role"=\$1 WHERE organization_id = \$2 AND user_id = \$3`).
+		WithArgs("admin", transfer.OrganizationID, transfer.FromUserID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, repo.Accept(transfer, "owner", "admin"))
+	assert.Equal(t, model.TransferAccepted, transfer.Status)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAcceptTransfer_RollsBackWhenNoLongerPending(t *testing.T) {
+	sqlDB, db, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	repo := NewTransferRepository(db)
+	transfer := &model.OwnershipTransfer{ID: uuid.New(), OrganizationID: uuid.New(), FromUserID: uuid.New(), ToUserID: uuid.New(), Status: model.TransferPending}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "ownership_transfers"`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	assert.ErrorIs(t, repo.Accept(transfer, "owner", "admin"), ErrTransferNotFound)
+	assert.Equal(t, model.TransferPending, transfer.Status)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
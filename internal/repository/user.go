@@ -3,9 +3,12 @@ package repository
 import (
 	"errors"
 	"log"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"github.com/radiatus-ai/auth-service/internal/model"
 )
@@ -20,6 +23,12 @@ type UserRepository interface {
 	GetByID(id uuid.UUID) (*model.User, error)
 	GetByGoogleID(googleID string) (*model.User, error)
 	ExistsByEmail(email string) (bool, error)
+	Search(query string, limit int) ([]model.User, error)
+	SetPlatformRole(id uuid.UUID, role string) error
+	SetStatus(id uuid.UUID, status, reason string, at time.Time) error
+	RevokeTokens(id uuid.UUID, at time.Time) error
+	Delete(id uuid.UUID) error
+	DeleteUnlessLastOwner(id uuid.UUID, ownerRole string) error
 }
 
 type userRepository struct {
@@ -80,3 +89,77 @@ func (r *userRepository) ExistsByEmail(email string) (bool, error) {
 	}
 	return count > 0, nil
 }
+
+// Search matches users by exact ID or by email substring.
+func (r *userRepository) Search(query string, limit int) ([]model.User, error) {
+	var users []model.User
+	db := r.db.Order("email").Limit(limit)
+	if id, err := uuid.Parse(query); err == nil {
+		db = db.Where("id = ?", id)
+	} else if query != "" {
+		db = db.Where("email ILIKE ?", "%"+escapeLike(query)+"%")
+	}
+	if err := db.Find(&users).Error; err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+func (r *userRepository) SetPlatformRole(id uuid.UUID, role string) error {
+	return r.db.Model(&model.User{}).Where("id = ?", id).Update("platform_role", role).Error
+}
+
+// SetStatus changes the user's status and revokes every token issued before
+// the change, whatever the new status is.
+func (r *userRepository) SetStatus(id uuid.UUID, status, reason string, at time.Time) error {
+	return r.db.Model(&model.User{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":             status,
+		"status_reason":      reason,
+		"status_changed_at":  at,
+		"tokens_valid_after": at,
+	}).Error
+}
+
+// RevokeTokens invalidates every token issued to the user before at and ends
+// the user's sessions.
+func (r *userRepository) RevokeTokens(id uuid.UUID, at time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.User{}).Where("id = ?", id).Update("tokens_valid_after", at).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", id).Delete(&model.Session{}).Error
+	})
+}
+
+func (r *userRepository) Delete(id uuid.UUID) error {
+	return r.db.Delete(&model.User{}, id).Error
+}
+
+// DeleteUnlessLastOwner deletes the user unless they are the last owner of
+// an organization, returning ErrLastOwner then. The owner rows of those
+// organizations are locked first, so that owners cannot leave them meanwhile.
+func (r *userRepository) DeleteUnlessLastOwner(id uuid.UUID, ownerRole string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var owners []model.Membership
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("role = ? AND organization_id IN (?)", ownerRole,
+				tx.Model(&model.Membership{}).Select("organization_id").Where("user_id = ? AND role = ?", id, ownerRole)).
+			Find(&owners).Error; err != nil {
+			return err
+		}
+		counts := make(map[uuid.UUID]int)
+		for _, owner := range owners {
+			counts[owner.OrganizationID]++
+		}
+		for _, count := range counts {
+			if count <= 1 {
+				return ErrLastOwner
+			}
+		}
+		return tx.Delete(&model.User{}, id).Error
+	})
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
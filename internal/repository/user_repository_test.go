@@ -43,7 +43,7 @@ func TestCreateUser_Success(t *testing.T) {
 
 	mock.ExpectBegin()
 	mock.ExpectExec(`INSERT INTO "users" (.+) VALUES (.+)`).
-		WithArgs(user.ID, user.Email, user.GoogleID, sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+		WithArgs(user.ID, user.Email, user.GoogleID, model.UserStatusActive, "", nil, "", nil, sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
 	mock.ExpectCommit()
 
 	err := repo.Create(user)
@@ -52,3 +52,44 @@ func TestCreateUser_Success(t *testing.T) {
 	// assert.Equal(t, user, result)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestDeleteUnlessLastOwner(t *testing.T) {
+	userID, sharedOrg, soleOrg := uuid.New(), uuid.New(), uuid.New()
+	ownerRows := func(orgs ...uuid.UUID) *sqlmock.Rows {
+		rows := sqlmock.NewRows([]string{"user_id", "organization_id", "role"})
+		for _, org := range orgs {
+			rows.AddRow(uuid.New(), org, "owner")
+		}
+		return rows
+	}
+
+	t.Run("other owners remain", func(t *testing.T) {
+		sqlDB, db, mock := DbMock(t)
+		defer sqlDB.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT \* FROM "user_organizations" WHERE role = \$1 AND organization_id IN \(SELECT "organization_id" FROM "user_organizations" WHERE user_id = \$2 AND role = \$3\) FOR UPDATE`).
+			WithArgs("owner", userID, "owner").
+			WillReturnRows(ownerRows(sharedOrg, sharedOrg))
+		mock.ExpectExec(`DELETE FROM "users" WHERE "users"."id" = \$1`).
+			WithArgs(userID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		assert.NoError(t, NewUserRepository(db).DeleteUnlessLastOwner(userID, "owner"))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("sole owner", func(t *testing.T) {
+		sqlDB, db, mock := DbMock(t)
+		defer sqlDB.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT \* FROM "user_organizations" .* FOR UPDATE`).
+			WillReturnRows(ownerRows(sharedOrg, sharedOrg, soleOrg))
+		mock.ExpectRollback()
+
+		assert.ErrorIs(t, NewUserRepository(db).DeleteUnlessLastOwner(userID, "owner"), ErrLastOwner)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,98 @@
+package session
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/radiatus-ai/auth-service/internal/auth"
+	"github.com/radiatus-ai/auth-service/internal/repository"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+func (h *Handler) ListSessions(c *gin.Context) {
+	userID, currentID, ok := sessionUser(c)
+	if !ok {
+		return
+	}
+
+	sessions, err := h.service.ListSessions(userID, currentID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list sessions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"sessions": sessions})
+}
+
+func (h *Handler) RevokeSession(c *gin.Context) {
+	userID, _, ok := sessionUser(c)
+	if !ok {
+		return
+	}
+	sessionID, err := uuid.Parse(c.Param("sessionID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sessionID"})
+		return
+	}
+
+	if err := h.service.RevokeSession(userID, sessionID); err != nil {
+		if errors.Is(err, repository.ErrSessionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke session"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RevokeOtherSessions signs out everywhere but the current session.
+func (h *Handler) RevokeOtherSessions(c *gin.Context) {
+	userID, currentID, ok := sessionUser(c)
+	if !ok {
+		return
+	}
+
+	revoked, err := h.service.RevokeOtherSessions(userID, currentID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke sessions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"revoked": revoked})
+}
+
+// sessionUser returns the signed-in user and the session the request was made
+// from, uuid.Nil for tokens issued before sessions were recorded. Sessions
+// are managed by people, so machine credentials, personal access tokens and
+// impersonating admins are turned away.
+func sessionUser(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	value, _ := c.Get("claims")
+	claims, ok := value.(*auth.Claims)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	if claims.SubjectType != auth.SubjectUser || claims.IsPersonalAccessToken() || claims.IsImpersonated() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Sessions can only be managed from a signed-in session"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	currentID, _ := uuid.Parse(claims.SessionID)
+	return userID, currentID, true
+}
@@ -0,0 +1,67 @@
+package session
+
+import (
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/radiatus-ai/auth-service/internal/model"
+	"github.com/radiatus-ai/auth-service/internal/repository"
+)
+
+// Service lets users see where they are signed in and sign devices out.
+// Sessions are created at login and checked by auth.Service.VerifyClaims.
+type Service interface {
+	ListSessions(userID, currentID uuid.UUID) ([]model.Session, error)
+	RevokeSession(userID, sessionID uuid.UUID) error
+	RevokeOtherSessions(userID, currentID uuid.UUID) (int64, error)
+}
+
+type service struct {
+	sessionRepo repository.SessionRepository
+}
+
+func NewService(sessionRepo repository.SessionRepository) Service {
+	return &service{sessionRepo: sessionRepo}
+}
+
+// ListSessions returns the user's active sessions, marking currentID, the
+// session the request was made from.
+func (s *service) ListSessions(userID, currentID uuid.UUID) ([]model.Session, error) {
+	now := time.Now()
+	sessions, err := s.sessionRepo.ListByUser(userID, now)
+	if err != nil {
+		return nil, err
+	}
+	active := sessions[:0]
+	for _, session := range sessions {
+		// Idle sessions have ended even though they have not expired.
+		if session.Expired(now) {
+			continue
+		}
+		session.Current = session.ID == currentID
+		active = append(active, session)
+	}
+	return active, nil
+}
+
+func (s *service) RevokeSession(userID, sessionID uuid.UUID) error {
+	if err := s.sessionRepo.Delete(userID, sessionID); err != nil {
+		return err
+	}
+	log.Printf("Revoked session %s for user ID: %s", sessionID, userID)
+	return nil
+}
+
+// RevokeOtherSessions signs out every session of the user but currentID. A
+// request made outside of a session (currentID is uuid.Nil) signs out all of
+// them.
+func (s *service) RevokeOtherSessions(userID, currentID uuid.UUID) (int64, error) {
+	revoked, err := s.sessionRepo.DeleteOthers(userID, currentID)
+	if err != nil {
+		return 0, err
+	}
+	log.Printf("Revoked %d other sessions for user ID: %s", revoked, userID)
+	return revoked, nil
+}
@@ -0,0 +1,123 @@
+package session
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/radiatus-ai/auth-service/internal/auth"
+	"github.com/radiatus-ai/auth-service/internal/model"
+	"github.com/radiatus-ai/auth-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockSessionRepository struct {
+	repository.SessionRepository
+	sessions []model.Session
+}
+
+func (m *mockSessionRepository) ListByUser(userID uuid.UUID, now time.Time) ([]model.Session, error) {
+	var sessions []model.Session
+	for _, session := range m.sessions {
+		if session.UserID == userID && !session.Expired(now) {
+			sessions = append(sessions, session)
+		}
+	}
+	return sessions, nil
+}
+
+func (m *mockSessionRepository) Delete(userID, id uuid.UUID) error {
+	for i, session := range m.sessions {
+		if session.ID == id && session.UserID == userID {
+			m.sessions = append(m.sessions[:i], m.sessions[i+1:]...)
+			return nil
+		}
+	}
+	return repository.ErrSessionNotFound
+}
+
+func (m *mockSessionRepository) DeleteOthers(userID, keepID uuid.UUID) (int64, error) {
+	var kept []model.Session
+	for _, session := range m.sessions {
+		if session.UserID != userID || session.ID == keepID {
+			kept = append(kept, session)
+		}
+	}
+	revoked := int64(len(m.sessions) - len(kept))
+	m.sessions = kept
+	return revoked, nil
+}
+
+func newTestSessions(userID, otherUserID uuid.UUID) *mockSessionRepository {
+	expiresAt := time.Now().Add(time.Hour)
+	return &mockSessionRepository{sessions: []model.Session{
+		{ID: uuid.New(), UserID: userID, ExpiresAt: expiresAt},
+		{ID: uuid.New(), UserID: userID, ExpiresAt: expiresAt},
+		{ID: uuid.New(), UserID: userID, ExpiresAt: time.Now().Add(-time.Hour)},
+		{ID: uuid.New(), UserID: otherUserID, ExpiresAt: expiresAt},
+	}}
+}
+
+func TestListSessions(t *testing.T) {
+	userID := uuid.New()
+	repo := newTestSessions(userID, uuid.New())
+	svc := NewService(repo)
+
+	sessions, err := svc.ListSessions(userID, repo.sessions[1].ID)
+
+	assert.NoError(t, err)
+	assert.Len(t, sessions, 2)
+	assert.False(t, sessions[0].Current)
+	assert.True(t, sessions[1].Current)
+}
+
+func TestRevokeSession(t *testing.T) {
+	userID, otherUserID := uuid.New(), uuid.New()
+	repo := newTestSessions(userID, otherUserID)
+	svc := NewService(repo)
+	otherSession := repo.sessions[3].ID
+
+	assert.ErrorIs(t, svc.RevokeSession(userID, otherSession), repository.ErrSessionNotFound)
+	assert.NoError(t, svc.RevokeSession(userID, repo.sessions[0].ID))
+	assert.Len(t, repo.sessions, 3)
+}
+
+func TestRevokeOtherSessions(t *testing.T) {
+	userID, otherUserID := uuid.New(), uuid.New()
+	repo := newTestSessions(userID, otherUserID)
+	svc := NewService(repo)
+	current := repo.sessions[0].ID
+
+	revoked, err := svc.RevokeOtherSessions(userID, current)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), revoked)
+	assert.Len(t, repo.sessions, 2)
+	assert.Equal(t, current, repo.sessions[0].ID)
+	assert.Equal(t, otherUserID, repo.sessions[1].UserID)
+}
+
+func TestHandler_RejectsNonSessionTokens(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	userID := uuid.New().String()
+
+	for name, claims := range map[string]*auth.Claims{
+		"personal access token": {Subject: userID, SubjectType: auth.SubjectUser, TokenID: uuid.New().String()},
+		"service account":       {Subject: userID, SubjectType: auth.SubjectServiceAccount},
+		"impersonation":         {Subject: userID, SubjectType: auth.SubjectUser, Actor: &auth.Actor{Subject: uuid.New().String()}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			_, r := gin.CreateTestContext(w)
+			r.Use(func(c *gin.Context) { c.Set("claims", claims) })
+			r.GET("/sessions", NewHandler(NewService(&mockSessionRepository{})).ListSessions)
+
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/sessions", nil))
+
+			assert.Equal(t, http.StatusForbidden, w.Code)
+		})
+	}
+}
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS team_members;
+DROP TABLE IF EXISTS teams;
+ALTER TABLE user_organizations
+    DROP COLUMN IF EXISTS created_at,
+    DROP COLUMN IF EXISTS role;
@@ -0,0 +1,4 @@
+ALTER TABLE organizations
+    ADD COLUMN IF NOT EXISTS parent_id UUID REFERENCES organizations(id);
+
+CREATE INDEX IF NOT EXISTS idx_organizations_parent_id ON organizations(parent_id);
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS organization_domains;
+DROP INDEX IF EXISTS idx_organizations_slug;
+ALTER TABLE organizations
+    DROP COLUMN IF EXISTS primary_color,
+    DROP COLUMN IF EXISTS logo_url,
+    DROP COLUMN IF EXISTS settings,
+    DROP COLUMN IF EXISTS slug;
@@ -0,0 +1,26 @@
+ALTER TABLE organizations
+    ADD COLUMN IF NOT EXISTS slug VARCHAR(63),
+    ADD COLUMN IF NOT EXISTS settings JSONB NOT NULL DEFAULT '{}',
+    ADD COLUMN IF NOT EXISTS logo_url TEXT,
+    ADD COLUMN IF NOT EXISTS primary_color VARCHAR(7);
+
+-- Backfill slugs for existing organizations, suffixed with part of the ID so they are unique.
+UPDATE organizations
+SET slug = trim(both '-' from left(lower(regexp_replace(name, '[^a-zA-Z0-9]+', '-', 'g')), 40)) || '-' || left(id::text, 8)
+WHERE slug IS NULL;
+
+ALTER TABLE organizations ALTER COLUMN slug SET NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_organizations_slug ON organizations(slug);
+
+CREATE TABLE organization_domains (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    domain VARCHAR(253) NOT NULL,
+    verification_token VARCHAR(64) NOT NULL,
+    verified_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (organization_id, domain)
+);
+
+-- A domain can be claimed by several organizations but verified by only one.
+CREATE UNIQUE INDEX idx_organization_domains_verified ON organization_domains(domain) WHERE verified_at IS NOT NULL;
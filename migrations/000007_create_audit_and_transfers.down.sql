@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS ownership_transfers;
+DROP TABLE IF EXISTS audit_events;
@@ -0,0 +1,13 @@
+ALTER TABLE organizations
+    DROP CONSTRAINT IF EXISTS organizations_parent_id_fkey,
+    ADD CONSTRAINT organizations_parent_id_fkey FOREIGN KEY (parent_id) REFERENCES organizations(id);
+
+ALTER TABLE user_organizations
+    DROP CONSTRAINT IF EXISTS user_organizations_user_id_fkey,
+    DROP CONSTRAINT IF EXISTS user_organizations_organization_id_fkey,
+    ADD CONSTRAINT user_organizations_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id),
+    ADD CONSTRAINT user_organizations_organization_id_fkey FOREIGN KEY (organization_id) REFERENCES organizations(id);
+
+DROP INDEX IF EXISTS idx_organizations_deleted_at;
+ALTER TABLE organizations
+    DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,15 @@
+ALTER TABLE organizations
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_organizations_deleted_at ON organizations(deleted_at);
+
+-- Purging an organization must not leave memberships dangling or fail on its children.
+ALTER TABLE user_organizations
+    DROP CONSTRAINT IF EXISTS user_organizations_user_id_fkey,
+    DROP CONSTRAINT IF EXISTS user_organizations_organization_id_fkey,
+    ADD CONSTRAINT user_organizations_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    ADD CONSTRAINT user_organizations_organization_id_fkey FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE;
+
+ALTER TABLE organizations
+    DROP CONSTRAINT IF EXISTS organizations_parent_id_fkey,
+    ADD CONSTRAINT organizations_parent_id_fkey FOREIGN KEY (parent_id) REFERENCES organizations(id) ON DELETE SET NULL;
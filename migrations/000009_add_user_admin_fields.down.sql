@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_users_platform_role;
+ALTER TABLE users
+    DROP COLUMN IF EXISTS tokens_valid_after,
+    DROP COLUMN IF EXISTS platform_role,
+    DROP COLUMN IF EXISTS status;
@@ -0,0 +1,11 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'active',
+    ADD COLUMN IF NOT EXISTS platform_role VARCHAR(50),
+    ADD COLUMN IF NOT EXISTS tokens_valid_after TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_users_platform_role ON users(platform_role) WHERE platform_role IS NOT NULL;
+
+-- Deleting a user removes their team memberships and any transfers they were part of.
+ALTER TABLE team_members
+    DROP CONSTRAINT IF EXISTS team_members_user_id_fkey,
+    ADD CONSTRAINT team_members_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
@@ -0,0 +1,13 @@
+CREATE TABLE oauth_clients (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    client_id VARCHAR(255) NOT NULL UNIQUE,
+    secret_hash VARCHAR(64) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    grant_types JSONB NOT NULL DEFAULT '[]',
+    exchange_audiences JSONB NOT NULL DEFAULT '[]',
+    exchange_scopes JSONB NOT NULL DEFAULT '[]',
+    exchange_token_ttl_seconds INTEGER NOT NULL DEFAULT 300,
+    disabled BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,30 @@
+CREATE TABLE service_accounts (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    client_id VARCHAR(255) NOT NULL UNIQUE,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    role VARCHAR(50) NOT NULL DEFAULT 'member',
+    disabled BOOLEAN NOT NULL DEFAULT FALSE,
+    -- The user or service account that created it.
+    created_by UUID,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (organization_id, name)
+);
+
+CREATE INDEX idx_service_accounts_organization_id ON service_accounts(organization_id);
+
+-- Several secrets can be live at once so that a new one can be rolled out
+-- before the old one is deleted.
+CREATE TABLE service_account_secrets (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    service_account_id UUID NOT NULL REFERENCES service_accounts(id) ON DELETE CASCADE,
+    secret_hash VARCHAR(64) NOT NULL,
+    description VARCHAR(255),
+    expires_at TIMESTAMP WITH TIME ZONE,
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_service_account_secrets_account_id ON service_account_secrets(service_account_id);
@@ -0,0 +1,18 @@
+CREATE TABLE organization_api_keys (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    key_hash VARCHAR(64) NOT NULL UNIQUE,
+    key_hint VARCHAR(20) NOT NULL,
+    scopes JSONB NOT NULL DEFAULT '[]',
+    allowed_cidrs JSONB NOT NULL DEFAULT '[]',
+    -- Set on the old key when it is rotated, ending its grace period.
+    expires_at TIMESTAMP WITH TIME ZONE,
+    rotated_from_id UUID REFERENCES organization_api_keys(id) ON DELETE SET NULL,
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    last_used_ip VARCHAR(45),
+    created_by UUID,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_organization_api_keys_organization_id ON organization_api_keys(organization_id);
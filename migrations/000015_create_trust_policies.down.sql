@@ -0,0 +1 @@
+DROP TABLE IF EXISTS service_account_trust_policies;
@@ -0,0 +1,13 @@
+-- Trust policies let workloads holding a token from an external issuer act
+-- as a service account without a stored secret.
+CREATE TABLE service_account_trust_policies (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    service_account_id UUID NOT NULL REFERENCES service_accounts(id) ON DELETE CASCADE,
+    issuer VARCHAR(512) NOT NULL,
+    subject_pattern VARCHAR(512) NOT NULL,
+    audience_pattern VARCHAR(512) NOT NULL,
+    description TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_trust_policies_service_account_id ON service_account_trust_policies(service_account_id);
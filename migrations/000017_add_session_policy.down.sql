@@ -0,0 +1,5 @@
+ALTER TABLE user_sessions
+    DROP COLUMN IF EXISTS refresh_token_hash,
+    DROP COLUMN IF EXISTS refresh_expires_at,
+    DROP COLUMN IF EXISTS idle_timeout_seconds,
+    DROP COLUMN IF EXISTS absolute_expires_at;
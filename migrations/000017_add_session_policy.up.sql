@@ -0,0 +1,8 @@
+-- Sessions remember the policy in force when they were last renewed, so
+-- tokens can be checked against it without looking up the user's
+-- organizations on every request.
+ALTER TABLE user_sessions
+    ADD COLUMN refresh_token_hash VARCHAR(64) UNIQUE,
+    ADD COLUMN refresh_expires_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN idle_timeout_seconds INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN absolute_expires_at TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,4 @@
+ALTER TABLE user_sessions
+    DROP COLUMN IF EXISTS auth_time,
+    DROP COLUMN IF EXISTS acr,
+    DROP COLUMN IF EXISTS amr;
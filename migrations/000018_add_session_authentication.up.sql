@@ -0,0 +1,10 @@
+-- When and how the user last authenticated in a session, so sensitive
+-- actions can demand a recent or stronger sign-in.
+ALTER TABLE user_sessions
+    ADD COLUMN auth_time TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN acr VARCHAR(50) NOT NULL DEFAULT 'aal1',
+    ADD COLUMN amr JSONB NOT NULL DEFAULT '["fed"]';
+
+UPDATE user_sessions SET auth_time = created_at;
+
+ALTER TABLE user_sessions ALTER COLUMN auth_time SET NOT NULL;
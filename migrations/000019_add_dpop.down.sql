@@ -0,0 +1,5 @@
+ALTER TABLE user_sessions DROP COLUMN IF EXISTS dpop_jkt;
+
+ALTER TABLE service_accounts DROP COLUMN IF EXISTS require_dpop;
+
+ALTER TABLE oauth_clients DROP COLUMN IF EXISTS require_dpop;
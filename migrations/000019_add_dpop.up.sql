@@ -0,0 +1,7 @@
+-- DPoP (RFC 9449): clients and service accounts can be required to bind
+-- their tokens to a key, and sessions remember the key they are bound to.
+ALTER TABLE oauth_clients ADD COLUMN require_dpop BOOLEAN NOT NULL DEFAULT false;
+
+ALTER TABLE service_accounts ADD COLUMN require_dpop BOOLEAN NOT NULL DEFAULT false;
+
+ALTER TABLE user_sessions ADD COLUMN dpop_jkt VARCHAR(64) NOT NULL DEFAULT '';
@@ -0,0 +1,3 @@
+ALTER TABLE service_accounts DROP COLUMN IF EXISTS tls_client_auth_subject_dn;
+
+ALTER TABLE oauth_clients DROP COLUMN IF EXISTS tls_client_auth_subject_dn;
@@ -0,0 +1,5 @@
+-- Mutual-TLS client authentication (RFC 8705): the subject DN of the client
+-- certificate a client or service account can authenticate with.
+ALTER TABLE oauth_clients ADD COLUMN tls_client_auth_subject_dn TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE service_accounts ADD COLUMN tls_client_auth_subject_dn TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,5 @@
+ALTER TABLE service_accounts DROP COLUMN IF EXISTS access_token_format;
+
+ALTER TABLE oauth_clients DROP COLUMN IF EXISTS access_token_format;
+
+DROP TABLE IF EXISTS reference_tokens;
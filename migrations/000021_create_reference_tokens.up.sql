@@ -0,0 +1,18 @@
+-- Reference tokens are opaque access tokens: clients get a random handle and
+-- the claims stay here, so nothing about the subject is readable from the
+-- token and deleting the row revokes it.
+CREATE TABLE reference_tokens (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    token_hash VARCHAR(255) NOT NULL UNIQUE,
+    subject VARCHAR(255) NOT NULL,
+    client_id VARCHAR(255) NOT NULL DEFAULT '',
+    claims JSONB NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_reference_tokens_expires_at ON reference_tokens(expires_at);
+
+ALTER TABLE oauth_clients ADD COLUMN access_token_format VARCHAR(20) NOT NULL DEFAULT 'jwt';
+
+ALTER TABLE service_accounts ADD COLUMN access_token_format VARCHAR(20) NOT NULL DEFAULT 'jwt';
@@ -0,0 +1,253 @@
+// Package authclient verifies tokens issued by the auth service in
+// downstream services. JWTs are verified locally against the service's JWKS,
+// which is refreshed in the background; tokens that cannot be verified
+// locally (personal access tokens, API keys, reference tokens, tokens signed
+// with the legacy shared secret or with a key not yet published) are checked
+// with the service's introspection endpoint, which requires the client
+// credentials in Config.
+//
+// Local verification does not notice tokens revoked before they expire, such
+// as those of a user suspended since logging in. Services that need that
+// guarantee should set Config.AlwaysIntrospect.
+package authclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+
+	"github.com/radiatus-ai/auth-service/pkg/dpop"
+	"github.com/radiatus-ai/auth-service/pkg/jwks"
+)
+
+var (
+	ErrMissingToken = errors.New("token is required")
+	ErrInvalidToken = errors.New("invalid token")
+)
+
+const (
+	// DefaultRefreshInterval is how often the JWKS is fetched in the
+	// background.
+	DefaultRefreshInterval = 5 * time.Minute
+	// minRefreshInterval bounds how often an unknown key ID can force a
+	// fetch, so tokens with made-up key IDs cannot hammer the service.
+	minRefreshInterval = 30 * time.Second
+	maxResponseBytes   = 1 << 20
+)
+
+type Config struct {
+	// BaseURL is where the auth service is reachable, e.g.
+	// "https://auth.example.com".
+	BaseURL string
+	// Audience is this service's name. Tokens restricted to another
+	// audience are rejected; tokens without an audience are accepted.
+	Audience string
+	// ClientID and ClientSecret are the OAuth client or service account
+	// this service authenticates to the introspection endpoint as. They
+	// may be left empty when HTTPClient presents a client certificate
+	// registered for the client instead.
+	ClientID     string
+	ClientSecret string
+	// HTTPClient defaults to a client with a 10 second timeout.
+	HTTPClient *http.Client
+	// RefreshInterval defaults to DefaultRefreshInterval.
+	RefreshInterval time.Duration
+	// AlwaysIntrospect skips local verification.
+	AlwaysIntrospect bool
+}
+
+// Client verifies tokens. It is safe for concurrent use; Close stops its
+// background refresh.
+type Client struct {
+	cfg        Config
+	httpClient *http.Client
+	dpop       *dpop.Verifier
+
+	mu          sync.RWMutex
+	keys        map[string]interface{}
+	refreshedAt time.Time
+
+	stop chan struct{}
+	once sync.Once
+}
+
+// New returns a Client and starts refreshing the JWKS. A failure to fetch
+// the JWKS at startup is not fatal: tokens are introspected until it
+// succeeds.
+func New(cfg Config) (*Client, error) {
+	if _, err := url.ParseRequestURI(cfg.BaseURL); err != nil {
+		return nil, fmt.Errorf("invalid base URL: %w", err)
+	}
+	cfg.BaseURL = strings.TrimSuffix(cfg.BaseURL, "/")
+	if cfg.RefreshInterval == 0 {
+		cfg.RefreshInterval = DefaultRefreshInterval
+	}
+	httpClient := cfg.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	c := &Client{
+		cfg:        cfg,
+		httpClient: httpClient,
+		dpop:       dpop.NewVerifier(),
+		keys:       map[string]interface{}{},
+		stop:       make(chan struct{}),
+	}
+	if !cfg.AlwaysIntrospect {
+		c.refresh()
+		go c.refreshLoop()
+	}
+	return c, nil
+}
+
+func (c *Client) Close() {
+	c.once.Do(func() { close(c.stop) })
+}
+
+// Verify checks token and returns its claims. clientIP is the address of the
+// caller presenting the token, which API keys with an allowlist are checked
+// against; it may be empty.
+func (c *Client) Verify(ctx context.Context, token, clientIP string) (*Claims, error) {
+	if token == "" {
+		return nil, ErrMissingToken
+	}
+
+	var claims *Claims
+	var err error
+	if c.cfg.AlwaysIntrospect || !c.verifiableLocally(token) {
+		claims, err = c.introspect(ctx, token, clientIP)
+	} else {
+		claims, err = c.verifyLocally(token)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if claims.Audience != "" && claims.Audience != c.cfg.Audience {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+// verifiableLocally reports whether token is an RS256 JWT signed with a key
+// in the JWKS, fetching it again if the key is new.
+func (c *Client) verifiableLocally(token string) bool {
+	parsed, _, err := new(jwt.Parser).ParseUnverified(token, jwt.MapClaims{})
+	if err != nil || parsed.Method != jwt.SigningMethodRS256 {
+		return false
+	}
+	kid, _ := parsed.Header["kid"].(string)
+	if c.key(kid) != nil {
+		return true
+	}
+
+	c.mu.RLock()
+	recent := time.Since(c.refreshedAt) < minRefreshInterval
+	c.mu.RUnlock()
+	if !recent {
+		c.refresh()
+	}
+	return c.key(kid) != nil
+}
+
+func (c *Client) verifyLocally(token string) (*Claims, error) {
+	parser := &jwt.Parser{ValidMethods: []string{jwt.SigningMethodRS256.Alg()}}
+	m := jwt.MapClaims{}
+	parsed, err := parser.ParseWithClaims(token, m, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		if key := c.key(kid); key != nil {
+			return key, nil
+		}
+		return nil, ErrInvalidToken
+	})
+	if err != nil || !parsed.Valid || !m.VerifyExpiresAt(time.Now().Unix(), true) {
+		return nil, ErrInvalidToken
+	}
+	return claimsFromMap(m)
+}
+
+func (c *Client) introspect(ctx context.Context, token, clientIP string) (*Claims, error) {
+	form := url.Values{"token": {token}}
+	if clientIP != "" {
+		form.Set("client_ip", clientIP)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.cfg.BaseURL+"/introspect", strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if c.cfg.ClientID != "" {
+		req.SetBasicAuth(c.cfg.ClientID, c.cfg.ClientSecret)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("introspection failed with status %d", resp.StatusCode)
+	}
+
+	var body map[string]interface{}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseBytes)).Decode(&body); err != nil {
+		return nil, err
+	}
+	if active, _ := body["active"].(bool); !active {
+		return nil, ErrInvalidToken
+	}
+	return claimsFromMap(body)
+}
+
+func (c *Client) key(kid string) interface{} {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.keys[kid]
+}
+
+func (c *Client) refreshLoop() {
+	ticker := time.NewTicker(c.cfg.RefreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			c.refresh()
+		case <-c.stop:
+			return
+		}
+	}
+}
+
+// refresh fetches the JWKS, keeping the previous keys if that fails.
+func (c *Client) refresh() {
+	c.mu.Lock()
+	c.refreshedAt = time.Now()
+	c.mu.Unlock()
+
+	resp, err := c.httpClient.Get(c.cfg.BaseURL + "/.well-known/jwks.json")
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return
+	}
+	var set jwks.Set
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseBytes)).Decode(&set); err != nil {
+		return
+	}
+
+	keys := set.SigningKeys()
+	c.mu.Lock()
+	c.keys = keys
+	c.mu.Unlock()
+}
@@ -0,0 +1,466 @@
+package authclient
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/radiatus-ai/auth-service/pkg/jwks"
+)
+
+const (
+	testClientID     = "billing"
+	testClientSecret = "s3cret"
+)
+
+// fakeAuthService serves a JWKS and an introspection endpoint like the auth
+// service does.
+type fakeAuthService struct {
+	server *httptest.Server
+
+	mu             sync.Mutex
+	keys           map[string]*rsa.PrivateKey
+	introspections int
+	active         map[string]map[string]interface{}
+}
+
+func newFakeAuthService(t *testing.T) *fakeAuthService {
+	t.Helper()
+	f := &fakeAuthService{keys: map[string]*rsa.PrivateKey{}, active: map[string]map[string]interface{}{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/jwks.json", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		set := jwks.Set{}
+		for kid, key := range f.keys {
+			set.Keys = append(set.Keys, jwks.RSAKey(kid, &key.PublicKey))
+		}
+		json.NewEncoder(w).Encode(set)
+	})
+	mux.HandleFunc("/introspect", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.introspections++
+		if id, secret, ok := r.BasicAuth(); !ok || id != testClientID || secret != testClientSecret {
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]interface{}{"error": "invalid_client"})
+			return
+		}
+		resp, ok := f.active[r.PostFormValue("token")]
+		if !ok {
+			resp = map[string]interface{}{"active": false}
+		}
+		json.NewEncoder(w).Encode(resp)
+	})
+	f.server = httptest.NewServer(mux)
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+func (f *fakeAuthService) addKey(t *testing.T, kid string) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	f.mu.Lock()
+	f.keys[kid] = key
+	f.mu.Unlock()
+	return key
+}
+
+func (f *fakeAuthService) introspectionCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.introspections
+}
+
+func sign(t *testing.T, key *rsa.PrivateKey, kid string, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+	return signed
+}
+
+func userClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"sub":   "user-1",
+		"email": "ada@example.com",
+		"iat":   time.Now().Unix(),
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+func newTestClient(t *testing.T, f *fakeAuthService, audience string) *Client {
+	t.Helper()
+	client, err := New(Config{BaseURL: f.server.URL, Audience: audience, ClientID: testClientID, ClientSecret: testClientSecret})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	t.Cleanup(client.Close)
+	return client
+}
+
+func TestVerify_Locally(t *testing.T) {
+	f := newFakeAuthService(t)
+	key := f.addKey(t, "key-1")
+	client := newTestClient(t, f, "")
+
+	claims, err := client.Verify(context.Background(), sign(t, key, "key-1", userClaims()), "")
+	if err != nil {
+		t.Fatalf("Failed to verify token: %v", err)
+	}
+	if claims.Subject != "user-1" || claims.Email != "ada@example.com" || !claims.IsUser() {
+		t.Errorf("Unexpected claims: %+v", claims)
+	}
+	if f.introspectionCount() != 0 {
+		t.Errorf("Expected local verification, got %d introspections", f.introspectionCount())
+	}
+}
+
+func TestVerify_ServiceAccountClaims(t *testing.T) {
+	f := newFakeAuthService(t)
+	key := f.addKey(t, "key-1")
+	client := newTestClient(t, f, "")
+
+	claims, err := client.Verify(context.Background(), sign(t, key, "key-1", jwt.MapClaims{
+		"sub":       "sa-1",
+		"sub_type":  SubjectServiceAccount,
+		"org_id":    "org-1",
+		"roles":     []string{"admin"},
+		"client_id": "sa_ci",
+		"exp":       time.Now().Add(time.Minute).Unix(),
+	}), "")
+	if err != nil {
+		t.Fatalf("Failed to verify token: %v", err)
+	}
+	if !claims.IsServiceAccount() || claims.OrganizationID != "org-1" || !claims.HasRole("admin") {
+		t.Errorf("Unexpected claims: %+v", claims)
+	}
+}
+
+func TestVerify_Rejections(t *testing.T) {
+	f := newFakeAuthService(t)
+	key := f.addKey(t, "key-1")
+	otherKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	client := newTestClient(t, f, "billing")
+
+	expired := userClaims()
+	expired["exp"] = time.Now().Add(-time.Minute).Unix()
+	noExpiry := userClaims()
+	delete(noExpiry, "exp")
+	otherAudience := userClaims()
+	otherAudience["aud"] = "reports"
+
+	for name, token := range map[string]string{
+		"expired":        sign(t, key, "key-1", expired),
+		"no expiry":      sign(t, key, "key-1", noExpiry),
+		"other audience": sign(t, key, "key-1", otherAudience),
+		"forged":         sign(t, otherKey, "key-1", userClaims()),
+		"opaque unknown": "r7pat_unknown",
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := client.Verify(context.Background(), token, ""); !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("Expected ErrInvalidToken, got %v", err)
+			}
+		})
+	}
+}
+
+func TestVerify_IntrospectsOpaqueTokens(t *testing.T) {
+	f := newFakeAuthService(t)
+	f.active["r7key_abc"] = map[string]interface{}{
+		"active":   true,
+		"sub":      "key-1",
+		"sub_type": SubjectAPIKey,
+		"org_id":   "org-1",
+		"scope":    "audit:read",
+	}
+	client := newTestClient(t, f, "")
+
+	claims, err := client.Verify(context.Background(), "r7key_abc", "10.0.0.1")
+	if err != nil {
+		t.Fatalf("Failed to verify token: %v", err)
+	}
+	if !claims.IsAPIKey() || claims.OrganizationID != "org-1" || !claims.HasScope("audit:read") || claims.HasScope("org:update") {
+		t.Errorf("Unexpected claims: %+v", claims)
+	}
+}
+
+func TestVerify_CustomClaims(t *testing.T) {
+	f := newFakeAuthService(t)
+	key := f.addKey(t, "key-1")
+	client := newTestClient(t, f, "")
+	jwtClaims := userClaims()
+	jwtClaims["plan"] = "enterprise"
+	f.active["r7at_abc"] = map[string]interface{}{"active": true, "token_type": "Bearer", "sub": "user-1", "plan": "enterprise"}
+
+	for name, token := range map[string]string{
+		"JWT":             sign(t, key, "key-1", jwtClaims),
+		"reference token": "r7at_abc",
+	} {
+		claims, err := client.Verify(context.Background(), token, "")
+		if err != nil {
+			t.Fatalf("%s: failed to verify token: %v", name, err)
+		}
+		if len(claims.Custom) != 1 || claims.Custom["plan"] != "enterprise" {
+			t.Errorf("%s: unexpected custom claims: %v", name, claims.Custom)
+		}
+	}
+}
+
+func TestVerify_FetchesRotatedKey(t *testing.T) {
+	f := newFakeAuthService(t)
+	f.addKey(t, "key-1")
+	client := newTestClient(t, f, "")
+	// Pretend the last fetch was long enough ago to allow another.
+	client.refreshedAt = time.Time{}
+
+	rotated := f.addKey(t, "key-2")
+	if _, err := client.Verify(context.Background(), sign(t, rotated, "key-2", userClaims()), ""); err != nil {
+		t.Fatalf("Failed to verify token signed with rotated key: %v", err)
+	}
+	if f.introspectionCount() != 0 {
+		t.Errorf("Expected the rotated key to be fetched rather than introspecting")
+	}
+}
+
+func TestVerify_IntrospectsLegacyTokens(t *testing.T) {
+	f := newFakeAuthService(t)
+	legacy, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, userClaims()).SignedString([]byte("secret"))
+	f.active[legacy] = map[string]interface{}{"active": true, "sub": "user-1", "email": "ada@example.com"}
+	client := newTestClient(t, f, "")
+
+	if _, err := client.Verify(context.Background(), legacy, ""); err != nil {
+		t.Fatalf("Failed to verify legacy token: %v", err)
+	}
+	if f.introspectionCount() != 1 {
+		t.Errorf("Expected 1 introspection, got %d", f.introspectionCount())
+	}
+}
+
+func TestVerify_IntrospectionRequiresCredentials(t *testing.T) {
+	f := newFakeAuthService(t)
+	legacy, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, userClaims()).SignedString([]byte("secret"))
+	f.active[legacy] = map[string]interface{}{"active": true, "sub": "user-1", "email": "ada@example.com"}
+	client, err := New(Config{BaseURL: f.server.URL, ClientID: testClientID, ClientSecret: "wrong"})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	t.Cleanup(client.Close)
+
+	if _, err := client.Verify(context.Background(), legacy, ""); err == nil {
+		t.Error("Expected introspection with the wrong secret to fail")
+	}
+}
+
+func TestHandler(t *testing.T) {
+	f := newFakeAuthService(t)
+	key := f.addKey(t, "key-1")
+	client := newTestClient(t, f, "")
+	handler := client.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, _ := FromContext(r.Context())
+		w.Write([]byte(claims.Subject))
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer "+sign(t, key, "key-1", userClaims()))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Body.String() != "user-1" {
+		t.Errorf("Expected the handler to see user-1, got %d %q", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without a token, got %d", w.Code)
+	}
+}
+
+func TestHandler_CertificateBoundToken(t *testing.T) {
+	f := newFakeAuthService(t)
+	key := f.addKey(t, "key-1")
+	client := newTestClient(t, f, "")
+	handler := client.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	cert := &x509.Certificate{Raw: []byte("client certificate")}
+	sum := sha256.Sum256(cert.Raw)
+	claims := userClaims()
+	claims["cnf"] = map[string]interface{}{"x5t#S256": base64.RawURLEncoding.EncodeToString(sum[:])}
+	token := sign(t, key, "key-1", claims)
+
+	for name, tc := range map[string]struct {
+		state *tls.ConnectionState
+		want  int
+	}{
+		"same certificate":  {&tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}, http.StatusOK},
+		"other certificate": {&tls.ConnectionState{PeerCertificates: []*x509.Certificate{{Raw: []byte("other")}}}, http.StatusUnauthorized},
+		"no certificate":    {nil, http.StatusUnauthorized},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.TLS = tc.state
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		if w.Code != tc.want {
+			t.Errorf("%s: expected %d, got %d", name, tc.want, w.Code)
+		}
+	}
+}
+
+// dpopKey is a client's DPoP key and its public JWK.
+type dpopKey struct {
+	key *ecdsa.PrivateKey
+	jwk map[string]interface{}
+	jkt string
+}
+
+func newDPoPKey(t *testing.T) *dpopKey {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	coordinate := func(b []byte) string {
+		padded := make([]byte, 32)
+		copy(padded[32-len(b):], b)
+		return base64.RawURLEncoding.EncodeToString(padded)
+	}
+	public := jwks.JSONWebKey{Kty: "EC", Crv: "P-256", X: coordinate(key.X.Bytes()), Y: coordinate(key.Y.Bytes())}
+	jkt, err := public.Thumbprint()
+	if err != nil {
+		t.Fatalf("Failed to compute thumbprint: %v", err)
+	}
+	return &dpopKey{key: key, jwk: map[string]interface{}{"kty": public.Kty, "crv": public.Crv, "x": public.X, "y": public.Y}, jkt: jkt}
+}
+
+func (k *dpopKey) proof(t *testing.T, method, url, accessToken string) string {
+	t.Helper()
+	sum := sha256.Sum256([]byte(accessToken))
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"jti": fmt.Sprint(time.Now().UnixNano()),
+		"htm": method,
+		"htu": url,
+		"iat": time.Now().Unix(),
+		"ath": base64.RawURLEncoding.EncodeToString(sum[:]),
+	})
+	token.Header["typ"] = "dpop+jwt"
+	token.Header["jwk"] = k.jwk
+	signed, err := token.SignedString(k.key)
+	if err != nil {
+		t.Fatalf("Failed to sign proof: %v", err)
+	}
+	return signed
+}
+
+func TestHandler_DPoPBoundToken(t *testing.T) {
+	f := newFakeAuthService(t)
+	key := f.addKey(t, "key-1")
+	client := newTestClient(t, f, "")
+	handler := client.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	holder := newDPoPKey(t)
+	claims := userClaims()
+	claims["cnf"] = map[string]interface{}{"jkt": holder.jkt}
+	token := sign(t, key, "key-1", claims)
+	const url = "http://api.example.com/things"
+
+	for name, tc := range map[string]struct {
+		scheme string
+		proof  string
+		want   int
+	}{
+		"valid proof":        {"DPoP", holder.proof(t, http.MethodGet, url, token), http.StatusOK},
+		"no proof":           {"DPoP", "", http.StatusUnauthorized},
+		"bearer scheme":      {"Bearer", holder.proof(t, http.MethodGet, url, token), http.StatusUnauthorized},
+		"other request":      {"DPoP", holder.proof(t, http.MethodPost, url, token), http.StatusUnauthorized},
+		"other key":          {"DPoP", newDPoPKey(t).proof(t, http.MethodGet, url, token), http.StatusUnauthorized},
+		"other access token": {"DPoP", holder.proof(t, http.MethodGet, url, "other"), http.StatusUnauthorized},
+	} {
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		req.Header.Set("Authorization", tc.scheme+" "+token)
+		if tc.proof != "" {
+			req.Header.Set("DPoP", tc.proof)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		if w.Code != tc.want {
+			t.Errorf("%s: expected %d, got %d", name, tc.want, w.Code)
+		}
+	}
+
+	proof := holder.proof(t, http.MethodGet, url, token)
+	for i, want := range []int{http.StatusOK, http.StatusUnauthorized} {
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		req.Header.Set("Authorization", "DPoP "+token)
+		req.Header.Set("DPoP", proof)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		if w.Code != want {
+			t.Errorf("Use %d of a proof: expected %d, got %d", i+1, want, w.Code)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, url, nil)
+	req.Header.Set("Authorization", "DPoP "+sign(t, key, "key-1", userClaims()))
+	req.Header.Set("DPoP", proof)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for an unbound token with the DPoP scheme, got %d", w.Code)
+	}
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	f := newFakeAuthService(t)
+	key := f.addKey(t, "key-1")
+	client := newTestClient(t, f, "")
+	interceptor := client.UnaryServerInterceptor()
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		claims, _ := FromContext(ctx)
+		return claims.Subject, nil
+	}
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+sign(t, key, "key-1", userClaims())))
+	resp, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{}, handler)
+	if err != nil || resp != "user-1" {
+		t.Errorf("Expected the handler to see user-1, got %v, %v", resp, err)
+	}
+
+	_, err = interceptor(context.Background(), nil, &grpc.UnaryServerInfo{}, handler)
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("Expected Unauthenticated without a token, got %v", err)
+	}
+
+	bound := userClaims()
+	bound["cnf"] = map[string]interface{}{"jkt": newDPoPKey(t).jkt}
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+sign(t, key, "key-1", bound)))
+	if _, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{}, handler); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("Expected Unauthenticated for a DPoP-bound token, got %v", err)
+	}
+}
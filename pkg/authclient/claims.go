@@ -0,0 +1,176 @@
+package authclient
+
+import (
+	"strings"
+	"time"
+)
+
+// Subject types, as in the sub_type claim.
+const (
+	SubjectUser           = "user"
+	SubjectServiceAccount = "service_account"
+	SubjectAPIKey         = "api_key"
+)
+
+// Claims describe a verified token.
+type Claims struct {
+	Subject     string
+	SubjectType string
+	// Email is set for users.
+	Email string
+	// OrganizationID is the organization a service account or API key
+	// belongs to, or the only one a personal access token may be used on.
+	OrganizationID string
+	// Roles is the role of a service account in its organization. Users'
+	// roles depend on the organization and are not part of the token.
+	Roles []string
+	// Scopes limit what the token may be used for; an unscoped token is not
+	// restricted.
+	Scopes   []string
+	Audience string
+	ClientID string
+	// ImpersonatorID is the admin acting as the subject, if any.
+	ImpersonatorID string
+	// AuthTime is when a user last signed in, and ACR and AMR how. A
+	// service can demand a recent or strong sign-in before sensitive
+	// actions.
+	AuthTime time.Time
+	ACR      string
+	AMR      []string
+	// KeyThumbprint is the DPoP key (RFC 9449) the token is bound to, from
+	// the cnf claim. Verify does not check DPoP proofs; the middleware
+	// requires one signed with the key, and services calling Verify
+	// directly must check the proof themselves.
+	KeyThumbprint string
+	// CertificateThumbprint is the client certificate (RFC 8705) the token
+	// is bound to, from the cnf claim. The middleware checks it against the
+	// certificate the connection was authenticated with.
+	CertificateThumbprint string
+	IssuedAt              time.Time
+	ExpiresAt             time.Time
+	// Custom are the claims the auth service's claims templates and
+	// pre-issue hook added, such as an organization's plan or feature flags.
+	Custom map[string]interface{}
+}
+
+func (c *Claims) IsUser() bool {
+	return c.SubjectType == SubjectUser
+}
+
+func (c *Claims) IsServiceAccount() bool {
+	return c.SubjectType == SubjectServiceAccount
+}
+
+func (c *Claims) IsAPIKey() bool {
+	return c.SubjectType == SubjectAPIKey
+}
+
+// HasScope reports whether the token may be used for scope.
+func (c *Claims) HasScope(scope string) bool {
+	if len(c.Scopes) == 0 {
+		return true
+	}
+	for _, s := range c.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Claims) HasRole(role string) bool {
+	for _, r := range c.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// standardClaims are the members that are not custom claims.
+var standardClaims = map[string]bool{
+	"sub": true, "sub_type": true, "iss": true, "iat": true, "nbf": true, "exp": true, "jti": true,
+	"email": true, "sid": true, "auth_time": true, "acr": true, "amr": true,
+	"aud": true, "scope": true, "client_id": true, "act": true, "cnf": true,
+	"org_id": true, "roles": true, "active": true, "token_type": true,
+}
+
+// claimsFromMap reads claims from a JWT payload or an introspection
+// response, which share their member names.
+func claimsFromMap(m map[string]interface{}) (*Claims, error) {
+	subject, _ := m["sub"].(string)
+	if subject == "" {
+		return nil, ErrInvalidToken
+	}
+
+	claims := &Claims{
+		Subject:   subject,
+		IssuedAt:  unixClaim(m["iat"]),
+		ExpiresAt: unixClaim(m["exp"]),
+	}
+	claims.SubjectType, _ = m["sub_type"].(string)
+	if claims.SubjectType == "" {
+		claims.SubjectType = SubjectUser
+	}
+	claims.Email, _ = m["email"].(string)
+	claims.OrganizationID, _ = m["org_id"].(string)
+	claims.Audience, _ = m["aud"].(string)
+	claims.ClientID, _ = m["client_id"].(string)
+	if scope, ok := m["scope"].(string); ok {
+		claims.Scopes = strings.Fields(scope)
+	}
+	claims.Roles = stringList(m["roles"])
+	claims.ImpersonatorID = impersonator(m["act"])
+	claims.AuthTime = unixClaim(m["auth_time"])
+	claims.ACR, _ = m["acr"].(string)
+	claims.AMR = stringList(m["amr"])
+	if cnf, ok := m["cnf"].(map[string]interface{}); ok {
+		claims.KeyThumbprint, _ = cnf["jkt"].(string)
+		claims.CertificateThumbprint, _ = cnf["x5t#S256"].(string)
+	}
+	for name, value := range m {
+		if standardClaims[name] {
+			continue
+		}
+		if claims.Custom == nil {
+			claims.Custom = map[string]interface{}{}
+		}
+		claims.Custom[name] = value
+	}
+	return claims, nil
+}
+
+// impersonator walks the act claim for the first actor that is a person
+// rather than an OAuth client.
+func impersonator(act interface{}) string {
+	for {
+		actor, ok := act.(map[string]interface{})
+		if !ok {
+			return ""
+		}
+		if _, client := actor["client_id"]; !client {
+			subject, _ := actor["sub"].(string)
+			return subject
+		}
+		act = actor["act"]
+	}
+}
+
+func stringList(v interface{}) []string {
+	var list []string
+	if values, ok := v.([]interface{}); ok {
+		for _, value := range values {
+			if s, ok := value.(string); ok {
+				list = append(list, s)
+			}
+		}
+	}
+	return list
+}
+
+func unixClaim(v interface{}) time.Time {
+	if f, ok := v.(float64); ok {
+		return time.Unix(int64(f), 0)
+	}
+	return time.Time{}
+}
@@ -0,0 +1,221 @@
+package authclient
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/radiatus-ai/auth-service/pkg/dpop"
+)
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying claims.
+func NewContext(ctx context.Context, claims *Claims) context.Context {
+	return context.WithValue(ctx, contextKey{}, claims)
+}
+
+// FromContext returns the claims the middleware stored in ctx.
+func FromContext(ctx context.Context) (*Claims, bool) {
+	claims, ok := ctx.Value(contextKey{}).(*Claims)
+	return claims, ok
+}
+
+// Gin returns middleware that rejects requests without a valid bearer token.
+// Tokens bound to a DPoP key must be presented with the DPoP scheme and a
+// proof. Like the auth service's own middleware it sets "user_id",
+// "subject_type" and "claims" on the gin context; the claims are also in the
+// request context.
+func (c *Client) Gin() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		claims, err := c.authenticateHTTP(ctx.Request, ctx.ClientIP())
+		if err != nil {
+			ctx.AbortWithStatusJSON(httpStatus(err), gin.H{"error": "Invalid or expired token"})
+			return
+		}
+
+		ctx.Set("user_id", claims.Subject)
+		ctx.Set("subject_type", claims.SubjectType)
+		ctx.Set("claims", claims)
+		ctx.Request = ctx.Request.WithContext(NewContext(ctx.Request.Context(), claims))
+		ctx.Next()
+	}
+}
+
+// Handler wraps next, rejecting requests without a valid bearer token as
+// Gin does and passing the claims in the request context.
+func (c *Client) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, err := c.authenticateHTTP(r, remoteIP(r.RemoteAddr))
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(httpStatus(err))
+			w.Write([]byte(`{"error":"Invalid or expired token"}`))
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), claims)))
+	})
+}
+
+func (c *Client) authenticateHTTP(r *http.Request, clientIP string) (*Claims, error) {
+	scheme, token := authorization(r.Header.Get("Authorization"))
+	claims, err := c.Verify(r.Context(), token, clientIP)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.checkKeyBinding(claims, scheme, token, r); err != nil {
+		return nil, err
+	}
+	if err := checkCertificateBinding(claims, r.TLS); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// UnaryServerInterceptor authenticates unary gRPC calls with the bearer
+// token in the "authorization" metadata. DPoP-bound tokens are rejected,
+// since gRPC calls carry no proof.
+func (c *Client) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := c.authenticateRPC(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor authenticates streaming gRPC calls.
+func (c *Client) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := c.authenticateRPC(ss.Context())
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func (c *Client) authenticateRPC(ctx context.Context) (context.Context, error) {
+	var token string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("authorization"); len(values) > 0 {
+			if scheme, value := authorization(values[0]); strings.EqualFold(scheme, "bearer") {
+				token = value
+			}
+		}
+	}
+	var clientIP string
+	var tlsState *tls.ConnectionState
+	if p, ok := peer.FromContext(ctx); ok {
+		clientIP = remoteIP(p.Addr.String())
+		if info, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+			tlsState = &info.State
+		}
+	}
+
+	claims, err := c.Verify(ctx, token, clientIP)
+	if err == nil && claims.KeyThumbprint != "" {
+		err = fmt.Errorf("%w: DPoP-bound tokens cannot be used over gRPC", ErrInvalidToken)
+	}
+	if err == nil {
+		err = checkCertificateBinding(claims, tlsState)
+	}
+	if err != nil {
+		if errors.Is(err, ErrMissingToken) || errors.Is(err, ErrInvalidToken) {
+			return nil, status.Error(codes.Unauthenticated, "invalid or expired token")
+		}
+		return nil, status.Error(codes.Unavailable, "token verification failed")
+	}
+	return NewContext(ctx, claims), nil
+}
+
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
+
+// checkKeyBinding rejects a token bound to a DPoP key (RFC 9449) unless it
+// was presented with the DPoP scheme and a proof for this request signed
+// with that key, and the DPoP scheme with a token that is not bound.
+func (c *Client) checkKeyBinding(claims *Claims, scheme, token string, r *http.Request) error {
+	proofExpected := strings.EqualFold(scheme, "dpop")
+	if claims.KeyThumbprint == "" {
+		if proofExpected {
+			return fmt.Errorf("%w: not bound to a DPoP key", ErrInvalidToken)
+		}
+		return nil
+	}
+	if !proofExpected {
+		return fmt.Errorf("%w: DPoP-bound tokens must be presented with the DPoP scheme", ErrInvalidToken)
+	}
+	jkt, err := c.dpop.Verify(r.Header.Get(dpop.Header), r.Method, dpop.RequestURL(r), token)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if jkt != claims.KeyThumbprint {
+		return fmt.Errorf("%w: DPoP proof is not signed with the key the token is bound to", ErrInvalidToken)
+	}
+	return nil
+}
+
+// checkCertificateBinding rejects a token bound to a client certificate
+// (RFC 8705) on a connection that was not authenticated with it. The TLS
+// handshake proved the client holds the certificate's key.
+func checkCertificateBinding(claims *Claims, state *tls.ConnectionState) error {
+	if claims.CertificateThumbprint == "" {
+		return nil
+	}
+	if state != nil && len(state.PeerCertificates) > 0 {
+		sum := sha256.Sum256(state.PeerCertificates[0].Raw)
+		if base64.RawURLEncoding.EncodeToString(sum[:]) == claims.CertificateThumbprint {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: bound to another client certificate", ErrInvalidToken)
+}
+
+// authorization splits an Authorization header into its scheme, "Bearer" or
+// "DPoP", and token.
+func authorization(header string) (string, string) {
+	parts := strings.Split(header, " ")
+	if len(parts) != 2 || (!strings.EqualFold(parts[0], "bearer") && !strings.EqualFold(parts[0], "dpop")) {
+		return "", ""
+	}
+	return parts[0], parts[1]
+}
+
+func remoteIP(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	return host
+}
+
+// httpStatus is 401 for rejected tokens and 503 when the auth service could
+// not be reached to decide.
+func httpStatus(err error) int {
+	if errors.Is(err, ErrMissingToken) || errors.Is(err, ErrInvalidToken) {
+		return http.StatusUnauthorized
+	}
+	return http.StatusServiceUnavailable
+}
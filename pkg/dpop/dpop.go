@@ -0,0 +1,191 @@
+// Package dpop verifies DPoP proofs (RFC 9449): JWTs signed with a key the
+// client holds, naming the request they were made for. The auth service
+// checks them when binding and accepting tokens, and downstream services
+// through authclient when they are presented a bound token.
+package dpop
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+
+	"github.com/radiatus-ai/auth-service/pkg/jwks"
+)
+
+// Header carries a DPoP proof.
+const Header = "DPoP"
+
+const (
+	// proofLifetime is how old a proof may be. Proofs are made for a single
+	// request, so this only absorbs latency and clock skew.
+	proofLifetime  = 5 * time.Minute
+	clockSkew      = time.Minute
+	maxJTILength   = 256
+	pruneInterval  = time.Minute
+	proofTokenType = "dpop+jwt"
+)
+
+var ErrInvalidProof = errors.New("invalid DPoP proof")
+
+// methods are the asymmetric algorithms a proof may be signed with.
+var methods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}
+
+// Verifier checks proofs and remembers those it accepted until they are too
+// old to be accepted anyway. The memory is per Verifier: behind a load
+// balancer a proof could be replayed once against each instance within its
+// short lifetime. It is safe for concurrent use.
+type Verifier struct {
+	mu        sync.Mutex
+	seen      map[string]time.Time
+	lastPrune time.Time
+}
+
+func NewVerifier() *Verifier {
+	return &Verifier{seen: make(map[string]time.Time)}
+}
+
+// Verify checks a proof was made for a method and url request, just now and
+// only once, and returns the thumbprint of the key it was signed with. When
+// presented with an access token, the proof must also be bound to it through
+// the ath claim; at a token endpoint accessToken is "".
+func (v *Verifier) Verify(proof, method, url, accessToken string) (string, error) {
+	if proof == "" {
+		return "", fmt.Errorf("%w: missing", ErrInvalidProof)
+	}
+	var jkt string
+	parser := &jwt.Parser{ValidMethods: methods, SkipClaimsValidation: true}
+	token, err := parser.Parse(proof, func(token *jwt.Token) (interface{}, error) {
+		if token.Header["typ"] != proofTokenType {
+			return nil, errors.New("typ must be " + proofTokenType)
+		}
+		key, thumbprint, err := proofKey(token.Header["jwk"])
+		if err != nil {
+			return nil, err
+		}
+		jkt = thumbprint
+		return key, nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidProof, err)
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return "", ErrInvalidProof
+	}
+
+	if htm, _ := claims["htm"].(string); htm != method {
+		return "", fmt.Errorf("%w: htm does not match the request", ErrInvalidProof)
+	}
+	if htu, _ := claims["htu"].(string); normalizeHTU(htu) != normalizeHTU(url) {
+		return "", fmt.Errorf("%w: htu does not match the request", ErrInvalidProof)
+	}
+	now := time.Now()
+	var issuedAt time.Time
+	if iat, ok := claims["iat"].(float64); ok {
+		issuedAt = time.Unix(int64(iat), 0)
+	}
+	if issuedAt.IsZero() || issuedAt.After(now.Add(clockSkew)) || now.Sub(issuedAt) > proofLifetime {
+		return "", fmt.Errorf("%w: iat is missing or out of range", ErrInvalidProof)
+	}
+	if accessToken != "" {
+		if ath, _ := claims["ath"].(string); ath != AccessTokenHash(accessToken) {
+			return "", fmt.Errorf("%w: ath does not match the access token", ErrInvalidProof)
+		}
+	}
+	jti, _ := claims["jti"].(string)
+	if jti == "" || len(jti) > maxJTILength {
+		return "", fmt.Errorf("%w: jti is missing or too long", ErrInvalidProof)
+	}
+	if !v.add(jkt+":"+jti, issuedAt.Add(proofLifetime+clockSkew), now) {
+		return "", fmt.Errorf("%w: proof has already been used", ErrInvalidProof)
+	}
+	return jkt, nil
+}
+
+// AccessTokenHash is the ath claim of a proof presented with accessToken.
+func AccessTokenHash(accessToken string) string {
+	sum := sha256.Sum256([]byte(accessToken))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// proofKey reads the public key from a proof's jwk header, refusing private
+// keys.
+func proofKey(header interface{}) (interface{}, string, error) {
+	members, ok := header.(map[string]interface{})
+	if !ok {
+		return nil, "", errors.New("jwk header is required")
+	}
+	if _, private := members["d"]; private {
+		return nil, "", errors.New("jwk must be a public key")
+	}
+	raw, err := json.Marshal(members)
+	if err != nil {
+		return nil, "", err
+	}
+	var jwk jwks.JSONWebKey
+	if err := json.Unmarshal(raw, &jwk); err != nil {
+		return nil, "", err
+	}
+	key, err := jwk.PublicKey()
+	if err != nil {
+		return nil, "", err
+	}
+	thumbprint, err := jwk.Thumbprint()
+	if err != nil {
+		return nil, "", err
+	}
+	return key, thumbprint, nil
+}
+
+// normalizeHTU drops the query and fragment, which are not part of htu, and
+// lowercases the scheme and host.
+func normalizeHTU(u string) string {
+	if i := strings.IndexAny(u, "?#"); i >= 0 {
+		u = u[:i]
+	}
+	scheme, rest, found := strings.Cut(u, "://")
+	if !found {
+		return u
+	}
+	host, path, _ := strings.Cut(rest, "/")
+	return strings.ToLower(scheme) + "://" + strings.ToLower(host) + "/" + path
+}
+
+// RequestURL is the URL a client called to make r, as a proof's htu names
+// it. Behind a TLS-terminating proxy the scheme comes from
+// X-Forwarded-Proto.
+func RequestURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host + r.URL.Path
+}
+
+// add records key until expiresAt, and reports false if it was already
+// recorded.
+func (v *Verifier) add(key string, expiresAt, now time.Time) bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if now.Sub(v.lastPrune) > pruneInterval {
+		for k, exp := range v.seen {
+			if now.After(exp) {
+				delete(v.seen, k)
+			}
+		}
+		v.lastPrune = now
+	}
+	if exp, ok := v.seen[key]; ok && !now.After(exp) {
+		return false
+	}
+	v.seen[key] = expiresAt
+	return true
+}
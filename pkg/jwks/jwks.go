@@ -0,0 +1,292 @@
+// Package jwks fetches and caches the signing keys of OpenID Connect issuers,
+// found through each issuer's discovery document.
+package jwks
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrKeyNotFound    = errors.New("signing key not found")
+	ErrInsecureIssuer = errors.New("issuer must use https")
+)
+
+const (
+	// DefaultTTL is how long a fetched key set is trusted before it is
+	// fetched again.
+	DefaultTTL = 10 * time.Minute
+	// minRefreshInterval bounds how often an unknown key ID can force a
+	// refetch, so tokens with made-up key IDs cannot hammer the issuer.
+	minRefreshInterval = time.Minute
+	maxResponseBytes   = 1 << 20
+)
+
+// Client fetches key sets over HTTP and caches them per issuer. It is safe
+// for concurrent use.
+type Client struct {
+	httpClient *http.Client
+	ttl        time.Duration
+
+	mu   sync.Mutex
+	sets map[string]*keySet
+}
+
+type keySet struct {
+	keys      map[string]interface{}
+	fetchedAt time.Time
+	// failedAt is when fetching the set last failed, and err why. Failures
+	// are remembered for minRefreshInterval so that an unreachable issuer
+	// is not retried on every token.
+	failedAt time.Time
+	err      error
+	// fetching is closed when the fetch in progress, if any, completes.
+	fetching chan struct{}
+}
+
+// New returns a Client. A nil httpClient uses one with a short timeout; a zero
+// ttl uses DefaultTTL.
+func New(httpClient *http.Client, ttl time.Duration) *Client {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	if ttl == 0 {
+		ttl = DefaultTTL
+	}
+	return &Client{
+		httpClient: httpClient,
+		ttl:        ttl,
+		sets:       map[string]*keySet{},
+	}
+}
+
+// Key returns the public key (*rsa.PublicKey or *ecdsa.PublicKey) with the
+// given key ID published by issuer. Only one fetch per issuer runs at a time;
+// callers needing the same issuer wait for it, and others are not held up.
+func (c *Client) Key(issuer, kid string) (interface{}, error) {
+	if !strings.HasPrefix(issuer, "https://") {
+		return nil, ErrInsecureIssuer
+	}
+
+	c.mu.Lock()
+	for {
+		set := c.sets[issuer]
+		if set == nil {
+			set = &keySet{}
+			c.sets[issuer] = set
+		}
+		if set.fetching != nil {
+			fetching := set.fetching
+			c.mu.Unlock()
+			<-fetching
+			c.mu.Lock()
+			continue
+		}
+
+		now := time.Now()
+		key, found := set.keys[kid]
+		if found && now.Sub(set.fetchedAt) <= c.ttl {
+			c.mu.Unlock()
+			return key, nil
+		}
+		if set.err != nil && now.Sub(set.failedAt) <= minRefreshInterval {
+			err := set.err
+			c.mu.Unlock()
+			return nil, err
+		}
+		if set.keys != nil && now.Sub(set.fetchedAt) <= minRefreshInterval {
+			c.mu.Unlock()
+			if !found {
+				return nil, ErrKeyNotFound
+			}
+			return key, nil
+		}
+
+		fetching := make(chan struct{})
+		set.fetching = fetching
+		c.mu.Unlock()
+		keys, err := c.fetch(issuer)
+		c.mu.Lock()
+		if err != nil {
+			set.err = err
+			set.failedAt = time.Now()
+		} else {
+			set.keys = keys
+			set.fetchedAt = time.Now()
+			set.err = nil
+		}
+		set.fetching = nil
+		close(fetching)
+	}
+}
+
+func (c *Client) fetch(issuer string) (map[string]interface{}, error) {
+	var discovery struct {
+		Issuer  string `json:"issuer"`
+		JWKSURI string `json:"jwks_uri"`
+	}
+	if err := c.getJSON(strings.TrimSuffix(issuer, "/")+"/.well-known/openid-configuration", &discovery); err != nil {
+		return nil, err
+	}
+	if discovery.Issuer != issuer {
+		return nil, fmt.Errorf("discovery document is for issuer %q", discovery.Issuer)
+	}
+	if !strings.HasPrefix(discovery.JWKSURI, "https://") {
+		return nil, ErrInsecureIssuer
+	}
+
+	var set Set
+	if err := c.getJSON(discovery.JWKSURI, &set); err != nil {
+		return nil, err
+	}
+	return set.SigningKeys(), nil
+}
+
+func (c *Client) getJSON(url string, v interface{}) error {
+	resp, err := c.httpClient.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("fetching %s: unexpected status %d", url, resp.StatusCode)
+	}
+	dec := json.NewDecoder(io.LimitReader(resp.Body, maxResponseBytes))
+	return dec.Decode(v)
+}
+
+// Set is a JSON Web Key Set (RFC 7517 section 5).
+type Set struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// SigningKeys returns the set's signature keys by key ID. Keys of types this
+// package does not understand are skipped rather than failing the set.
+func (s Set) SigningKeys() map[string]interface{} {
+	keys := make(map[string]interface{}, len(s.Keys))
+	for _, jwk := range s.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.PublicKey()
+		if err != nil {
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	return keys
+}
+
+// JSONWebKey is the subset of RFC 7517 needed for RSA and EC public keys.
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// RSAKey describes an RS256 signing key for publication in a Set.
+func RSAKey(kid string, key *rsa.PublicKey) JSONWebKey {
+	return JSONWebKey{
+		Kty: "RSA",
+		Kid: kid,
+		Use: "sig",
+		Alg: "RS256",
+		N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+// PublicKey returns the *rsa.PublicKey or *ecdsa.PublicKey k describes.
+func (k JSONWebKey) PublicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() < 3 {
+			return nil, errors.New("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("EC point is not on the curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+}
+
+// Thumbprint is the RFC 7638 SHA-256 thumbprint of the key, computed over
+// its required members only, so it does not depend on kid, use or alg.
+func (k JSONWebKey) Thumbprint() (string, error) {
+	var members map[string]string
+	switch k.Kty {
+	case "RSA":
+		members = map[string]string{"e": k.E, "kty": k.Kty, "n": k.N}
+	case "EC":
+		members = map[string]string{"crv": k.Crv, "kty": k.Kty, "x": k.X, "y": k.Y}
+	default:
+		return "", fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+	// The members are hashed in lexicographic order, which is the order
+	// encoding/json writes map keys in.
+	canonical, err := json.Marshal(members)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(canonical)
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, errors.New("empty key parameter")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
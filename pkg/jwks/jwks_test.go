@@ -0,0 +1,258 @@
+package jwks
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testIssuer is a local stand-in for an OpenID Connect issuer.
+type testIssuer struct {
+	server  *httptest.Server
+	keys    map[string]*rsa.PublicKey
+	fetches int
+	// fail makes the key set unavailable; block, if set, holds key set
+	// requests until it is closed.
+	fail  bool
+	block chan struct{}
+}
+
+func newTestIssuer(t *testing.T) *testIssuer {
+	t.Helper()
+	issuer := &testIssuer{keys: map[string]*rsa.PublicKey{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":   issuer.server.URL,
+			"jwks_uri": issuer.server.URL + "/keys",
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		issuer.fetches++
+		if issuer.block != nil {
+			<-issuer.block
+		}
+		if issuer.fail {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		var keys []map[string]string
+		for kid, key := range issuer.keys {
+			keys = append(keys, map[string]string{
+				"kty": "RSA",
+				"kid": kid,
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": keys})
+	})
+	issuer.server = httptest.NewTLSServer(mux)
+	t.Cleanup(issuer.server.Close)
+	return issuer
+}
+
+func (i *testIssuer) addKey(t *testing.T, kid string) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	i.keys[kid] = &key.PublicKey
+	return key
+}
+
+func TestKey(t *testing.T) {
+	issuer := newTestIssuer(t)
+	private := issuer.addKey(t, "key-1")
+	client := New(issuer.server.Client(), time.Hour)
+
+	key, err := client.Key(issuer.server.URL, "key-1")
+	if err != nil {
+		t.Fatalf("Failed to get key: %v", err)
+	}
+	public, ok := key.(*rsa.PublicKey)
+	if !ok || public.N.Cmp(private.N) != 0 {
+		t.Errorf("Returned key does not match the issuer's key")
+	}
+
+	// A second lookup is served from the cache.
+	if _, err := client.Key(issuer.server.URL, "key-1"); err != nil {
+		t.Fatalf("Failed to get cached key: %v", err)
+	}
+	if issuer.fetches != 1 {
+		t.Errorf("Expected 1 fetch, got %d", issuer.fetches)
+	}
+}
+
+func TestKey_UnknownKeyID(t *testing.T) {
+	issuer := newTestIssuer(t)
+	issuer.addKey(t, "key-1")
+	client := New(issuer.server.Client(), time.Hour)
+
+	if _, err := client.Key(issuer.server.URL, "key-1"); err != nil {
+		t.Fatalf("Failed to get key: %v", err)
+	}
+	if _, err := client.Key(issuer.server.URL, "made-up"); err != ErrKeyNotFound {
+		t.Errorf("Expected ErrKeyNotFound, got %v", err)
+	}
+	// Unknown key IDs do not force a refetch of a freshly fetched set.
+	if issuer.fetches != 1 {
+		t.Errorf("Expected 1 fetch, got %d", issuer.fetches)
+	}
+}
+
+func TestKey_RefetchesAfterRotation(t *testing.T) {
+	issuer := newTestIssuer(t)
+	issuer.addKey(t, "key-1")
+	client := New(issuer.server.Client(), time.Hour)
+
+	if _, err := client.Key(issuer.server.URL, "key-1"); err != nil {
+		t.Fatalf("Failed to get key: %v", err)
+	}
+	issuer.addKey(t, "key-2")
+	client.sets[issuer.server.URL].fetchedAt = time.Now().Add(-2 * minRefreshInterval)
+
+	if _, err := client.Key(issuer.server.URL, "key-2"); err != nil {
+		t.Fatalf("Failed to get rotated key: %v", err)
+	}
+	if issuer.fetches != 2 {
+		t.Errorf("Expected 2 fetches, got %d", issuer.fetches)
+	}
+}
+
+func TestKey_CachesFailures(t *testing.T) {
+	issuer := newTestIssuer(t)
+	issuer.addKey(t, "key-1")
+	issuer.fail = true
+	client := New(issuer.server.Client(), time.Hour)
+
+	for i := 0; i < 3; i++ {
+		if _, err := client.Key(issuer.server.URL, "key-1"); err == nil {
+			t.Fatalf("Expected an error while the issuer is failing")
+		}
+	}
+	if issuer.fetches != 1 {
+		t.Errorf("Expected 1 fetch while the failure is cached, got %d", issuer.fetches)
+	}
+
+	issuer.fail = false
+	client.sets[issuer.server.URL].failedAt = time.Now().Add(-2 * minRefreshInterval)
+	if _, err := client.Key(issuer.server.URL, "key-1"); err != nil {
+		t.Errorf("Failed to get key once the issuer recovered: %v", err)
+	}
+}
+
+func TestKey_FetchesOncePerIssuer(t *testing.T) {
+	slow := newTestIssuer(t)
+	slow.addKey(t, "key-1")
+	slow.block = make(chan struct{})
+	fast := newTestIssuer(t)
+	fast.addKey(t, "key-1")
+	// Test servers share a certificate, so either's client trusts both.
+	client := New(fast.server.Client(), time.Hour)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 5)
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := client.Key(slow.server.URL, "key-1")
+			errs <- err
+		}()
+	}
+
+	// A slow issuer does not hold up lookups for another.
+	done := make(chan error)
+	go func() {
+		_, err := client.Key(fast.server.URL, "key-1")
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Failed to get key of the fast issuer: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Lookup for one issuer waited for another")
+	}
+
+	close(slow.block)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("Failed to get key: %v", err)
+		}
+	}
+	if slow.fetches != 1 {
+		t.Errorf("Expected concurrent lookups to share 1 fetch, got %d", slow.fetches)
+	}
+}
+
+func TestKey_RejectsInsecureIssuer(t *testing.T) {
+	client := New(nil, 0)
+
+	if _, err := client.Key("http://issuer.example.com", "key-1"); err != ErrInsecureIssuer {
+		t.Errorf("Expected ErrInsecureIssuer, got %v", err)
+	}
+}
+
+func TestKey_RejectsMismatchedDiscoveryIssuer(t *testing.T) {
+	issuer := newTestIssuer(t)
+	issuer.addKey(t, "key-1")
+	client := New(issuer.server.Client(), time.Hour)
+
+	if _, err := client.Key(issuer.server.URL+"/other", "key-1"); err == nil {
+		t.Errorf("Expected an error for a discovery document of another issuer")
+	}
+}
+
+func TestRSAKey_RoundTrip(t *testing.T) {
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	set := Set{Keys: []JSONWebKey{RSAKey("key-1", &private.PublicKey), {Kty: "oct", Kid: "hmac"}}}
+	keys := set.SigningKeys()
+
+	public, ok := keys["key-1"].(*rsa.PublicKey)
+	if !ok || !public.Equal(&private.PublicKey) {
+		t.Errorf("Published key does not round-trip")
+	}
+	if _, ok := keys["hmac"]; ok {
+		t.Errorf("Expected unsupported key types to be skipped")
+	}
+}
+
+func TestThumbprint(t *testing.T) {
+	// The example of RFC 7638 section 3.1.
+	key := JSONWebKey{
+		Kty: "RSA",
+		N:   "0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw",
+		E:   "AQAB",
+		Alg: "RS256",
+		Kid: "2011-04-29",
+	}
+	thumbprint, err := key.Thumbprint()
+	if err != nil {
+		t.Fatalf("Failed to compute thumbprint: %v", err)
+	}
+	if thumbprint != "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs" {
+		t.Errorf("Unexpected thumbprint %s", thumbprint)
+	}
+
+	if _, err := (JSONWebKey{Kty: "oct"}).Thumbprint(); err == nil {
+		t.Errorf("Expected an error for a symmetric key")
+	}
+}